@@ -0,0 +1,68 @@
+package apikeys
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/apikey"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/auth"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+	"go.uber.org/zap"
+)
+
+// Authenticator turns API keys into caller claims. Every rejected key yields
+// auth.ErrInvalidKey; the reason is only wrapped for debug logging.
+type Authenticator struct {
+	repo             apikey.Repository
+	lastUsedInterval time.Duration
+}
+
+func NewAuthenticator(repo apikey.Repository, cfg config.APIKeysConfig) *Authenticator {
+	return &Authenticator{
+		repo:             repo,
+		lastUsedInterval: cfg.LastUsedInterval,
+	}
+}
+
+func (a *Authenticator) AuthenticateKey(ctx context.Context, plaintext string) (*auth.Claims, error) {
+	prefix, ok := apikey.ParsePrefix(plaintext)
+	if !ok {
+		return nil, fmt.Errorf("%w: malformed key", auth.ErrInvalidKey)
+	}
+
+	key, err := a.repo.FindByPrefix(ctx, prefix)
+	if errors.Is(err, apikey.ErrNotFound) {
+		return nil, fmt.Errorf("%w: unknown key", auth.ErrInvalidKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if !key.Matches(apikey.Hash(plaintext)) {
+		return nil, fmt.Errorf("%w: key %s does not match", auth.ErrInvalidKey, key.ID)
+	}
+	if !key.Usable(now) {
+		return nil, fmt.Errorf("%w: key %s is revoked or expired", auth.ErrInvalidKey, key.ID)
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= a.lastUsedInterval {
+		if err := a.repo.TouchLastUsed(ctx, key.ID, now, a.lastUsedInterval); err != nil {
+			zap.L().Warn("Failed to record API key use", zap.String("api_key_id", key.ID.String()), zap.Error(err))
+		}
+	}
+
+	claims := &auth.Claims{
+		Subject:       "apikey:" + key.ID.String(),
+		Raw:           map[string]interface{}{},
+		Permissions:   key.Scopes,
+		Tenant:        key.TenantID,
+		RateLimitTier: key.RateLimitTier,
+	}
+	if key.ExpiresAt != nil {
+		claims.ExpiresAt = *key.ExpiresAt
+	}
+	return claims, nil
+}
@@ -0,0 +1,69 @@
+package auditing
+
+import (
+	"context"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/commands"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/features"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/apikey"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/audit"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/featureflag"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/job"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/product"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/webhook"
+	"github.com/google/uuid"
+)
+
+// NewCommandLog tracks every command of the HTTP and gRPC APIs
+func NewCommandLog(repo audit.Repository, products product.ReadOnlyRepository, jobs job.Repository, webhooks webhook.Repository, keys apikey.Repository, flags *features.Service, reservations product.ReservationRepository) *Log {
+	l := NewLog(repo)
+
+	productSnapshot := func(ctx context.Context, id uuid.UUID) (any, error) { return products.FindByID(ctx, id) }
+	jobSnapshot := func(ctx context.Context, id uuid.UUID) (any, error) { return jobs.GetByID(ctx, id) }
+	subscriptionSnapshot := func(ctx context.Context, id uuid.UUID) (any, error) { return webhooks.GetSubscription(ctx, id) }
+	deliverySnapshot := func(ctx context.Context, id uuid.UUID) (any, error) { return webhooks.GetDelivery(ctx, id) }
+	keySnapshot := func(ctx context.Context, id uuid.UUID) (any, error) { return keys.GetByID(ctx, id) }
+	reservationSnapshot := func(ctx context.Context, id uuid.UUID) (any, error) { return reservations.GetReservation(ctx, id) }
+	flagSnapshot := func(ctx context.Context, id uuid.UUID) (any, error) { return flags.FlagByID(ctx, id) }
+
+	// Products
+	TrackCreated[commands.CreateProductCommand](l, audit.AggregateProduct, productSnapshot, func(res *product.Product) uuid.UUID { return res.ID() })
+	Track(l, audit.AggregateProduct, productSnapshot, func(cmd *commands.UpdateProductCommand) uuid.UUID { return cmd.ID })
+	Track(l, audit.AggregateProduct, productSnapshot, func(cmd *commands.ChangeProductStatusCommand) uuid.UUID { return cmd.ID })
+	Track(l, audit.AggregateProduct, productSnapshot, func(cmd *commands.DeleteProductCommand) uuid.UUID { return cmd.ID })
+	Track(l, audit.AggregateProduct, productSnapshot, func(cmd *commands.MoveStockCommand) uuid.UUID { return cmd.ProductID })
+
+	// Stock reservations
+	TrackCreated[commands.ReserveStockCommand](l, audit.AggregateStockReservation, reservationSnapshot,
+		func(res *commands.ReserveStockResponse) uuid.UUID { return res.ID })
+	Track(l, audit.AggregateStockReservation, reservationSnapshot, func(cmd *commands.ConfirmReservationCommand) uuid.UUID { return cmd.ID })
+	Track(l, audit.AggregateStockReservation, reservationSnapshot, func(cmd *commands.ReleaseReservationCommand) uuid.UUID { return cmd.ID })
+
+	// Bulk jobs
+	TrackCreated[commands.BulkChangePriceCommand](l, audit.AggregateJob, jobSnapshot, submittedJobID)
+	TrackCreated[commands.BulkChangeStatusCommand](l, audit.AggregateJob, jobSnapshot, submittedJobID)
+	TrackCreated[commands.BulkImportProductsCommand](l, audit.AggregateJob, jobSnapshot, submittedJobID)
+	Track(l, audit.AggregateJob, jobSnapshot, func(cmd *commands.CancelJobCommand) uuid.UUID { return cmd.ID })
+
+	// Webhooks
+	TrackCreated[commands.CreateWebhookSubscriptionCommand](l, audit.AggregateWebhookSubscription, subscriptionSnapshot,
+		func(res *commands.CreateWebhookSubscriptionResponse) uuid.UUID { return res.ID })
+	Track(l, audit.AggregateWebhookSubscription, subscriptionSnapshot, func(cmd *commands.UpdateWebhookSubscriptionCommand) uuid.UUID { return cmd.ID })
+	Track(l, audit.AggregateWebhookSubscription, subscriptionSnapshot, func(cmd *commands.DeleteWebhookSubscriptionCommand) uuid.UUID { return cmd.ID })
+	Track(l, audit.AggregateWebhookDelivery, deliverySnapshot, func(cmd *commands.RedeliverWebhookCommand) uuid.UUID { return cmd.ID })
+
+	// API keys; snapshots never include the key or its hash
+	TrackCreated[commands.CreateAPIKeyCommand](l, audit.AggregateAPIKey, keySnapshot, func(res *commands.CreateAPIKeyResponse) uuid.UUID { return res.ID })
+	Track(l, audit.AggregateAPIKey, keySnapshot, func(cmd *commands.RevokeAPIKeyCommand) uuid.UUID { return cmd.ID })
+
+	// Feature flags; deleting one records the config file definition it reverts to
+	Track(l, audit.AggregateFeatureFlag, flagSnapshot, func(cmd *commands.UpdateFeatureFlagCommand) uuid.UUID { return featureflag.IDOf(cmd.Key) })
+	Track(l, audit.AggregateFeatureFlag, flagSnapshot, func(cmd *commands.DeleteFeatureFlagCommand) uuid.UUID { return featureflag.IDOf(cmd.Key) })
+
+	return l
+}
+
+func submittedJobID(res *commands.SubmitJobResponse) uuid.UUID {
+	id, _ := uuid.Parse(res.JobID)
+	return id
+}
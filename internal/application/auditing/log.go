@@ -0,0 +1,200 @@
+package auditing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/audit"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/auth"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/handler"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/logger"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Anonymous is the actor recorded when authentication is disabled
+const Anonymous = "anonymous"
+
+// Snapshot loads the current state of an aggregate. A *fiber.Error with
+// status 404 means the aggregate does not exist (yet, or anymore).
+type Snapshot func(ctx context.Context, id uuid.UUID) (any, error)
+
+// omitted fields are not worth an audit record on their own
+var omitted = []string{"id", "tenant_id", "version", "updated_at", "last_used_at"}
+
+type rule struct {
+	aggregateType string
+	snapshot      Snapshot
+	requestID     func(req any) uuid.UUID
+	responseID    func(res any) uuid.UUID
+}
+
+// Log is a handler.Auditor that appends an audit entry for every successful
+// command it tracks. Requests it does not track, such as queries, are ignored.
+type Log struct {
+	repo  audit.Repository
+	rules map[reflect.Type]rule
+}
+
+func NewLog(repo audit.Repository) *Log {
+	return &Log{
+		repo:  repo,
+		rules: map[reflect.Type]rule{},
+	}
+}
+
+// Track audits commands of type R acting on the aggregate identified by the
+// request, e.g. updates and deletes
+func Track[R any](l *Log, aggregateType string, snapshot Snapshot, id func(req *R) uuid.UUID) {
+	l.rules[reflect.TypeFor[R]()] = rule{
+		aggregateType: aggregateType,
+		snapshot:      snapshot,
+		requestID:     func(req any) uuid.UUID { return id(req.(*R)) },
+	}
+}
+
+// TrackCreated audits commands of type R whose aggregate is only known from
+// the response Res, e.g. creates
+func TrackCreated[R, Res any](l *Log, aggregateType string, snapshot Snapshot, id func(res *Res) uuid.UUID) {
+	l.rules[reflect.TypeFor[R]()] = rule{
+		aggregateType: aggregateType,
+		snapshot:      snapshot,
+		responseID: func(res any) uuid.UUID {
+			r, _ := res.(*Res)
+			if r == nil {
+				return uuid.Nil
+			}
+			return id(r)
+		},
+	}
+}
+
+// Covers reports whether requests of type t are audited
+func (l *Log) Covers(t reflect.Type) bool {
+	_, ok := l.rules[t]
+	return ok
+}
+
+// Begin snapshots the aggregate before the command runs and returns the
+// function recording the entry once it succeeded. The snapshots are read
+// outside the command's transaction, so a concurrent write landing between
+// them is included in the recorded changes.
+func (l *Log) Begin(ctx context.Context, req any) func(ctx context.Context, res any) {
+	t := reflect.TypeOf(req).Elem()
+	rule, ok := l.rules[t]
+	if !ok {
+		return nil
+	}
+
+	var before map[string]interface{}
+	if rule.requestID != nil {
+		before = l.snapshot(ctx, rule, rule.requestID(req))
+	}
+
+	return func(ctx context.Context, res any) {
+		var id uuid.UUID
+		if rule.requestID != nil {
+			id = rule.requestID(req)
+		} else {
+			id = rule.responseID(res)
+		}
+
+		l.append(ctx, &audit.Entry{
+			ID:            uuid.New(),
+			Actor:         actor(ctx),
+			CommandType:   t.Name(),
+			AggregateType: rule.aggregateType,
+			AggregateID:   id,
+			Changes:       audit.Diff(before, l.snapshot(ctx, rule, id)),
+			ClientIP:      handler.ClientIP(ctx),
+			OccurredAt:    time.Now().UTC(),
+		})
+	}
+}
+
+// Record appends an entry for a change made outside the command pipeline,
+// such as a bulk job acting on behalf of its submitter. The caller passes
+// the aggregate states it read and wrote, so the entry matches the change
+// exactly. An empty actor is recorded as Anonymous.
+func (l *Log) Record(ctx context.Context, actor, commandType, aggregateType string, id uuid.UUID, before, after any) {
+	if actor == "" {
+		actor = Anonymous
+	}
+	l.append(ctx, &audit.Entry{
+		ID:            uuid.New(),
+		Actor:         actor,
+		CommandType:   commandType,
+		AggregateType: aggregateType,
+		AggregateID:   id,
+		Changes:       audit.Diff(fields(before), fields(after)),
+		OccurredAt:    time.Now().UTC(),
+	})
+}
+
+func (l *Log) append(ctx context.Context, entry *audit.Entry) {
+	if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.HasTraceID() {
+		entry.TraceID = spanCtx.TraceID().String()
+	}
+
+	// The change already succeeded, so a lost entry is reported but does
+	// not fail it
+	if err := l.repo.Append(ctx, entry); err != nil {
+		zap.L().Error("Failed to append audit entry", append(logger.GetTraceFieldsWithError(ctx, err),
+			zap.String("command_type", entry.CommandType),
+			zap.String("aggregate_id", entry.AggregateID.String()),
+		)...)
+	}
+}
+
+// snapshot returns the JSON fields of an aggregate, or nil when it does not exist
+func (l *Log) snapshot(ctx context.Context, rule rule, id uuid.UUID) map[string]interface{} {
+	if rule.snapshot == nil || id == uuid.Nil {
+		return nil
+	}
+
+	state, err := rule.snapshot(ctx, id)
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) && fiberErr.Code == fiber.StatusNotFound {
+		return nil
+	}
+	if err != nil {
+		zap.L().Warn("Failed to snapshot aggregate for audit", append(logger.GetTraceFieldsWithError(ctx, err),
+			zap.String("aggregate_type", rule.aggregateType),
+			zap.String("aggregate_id", id.String()),
+		)...)
+		return nil
+	}
+	return fields(state)
+}
+
+// fields returns the JSON fields of an aggregate state worth auditing
+func fields(state any) map[string]interface{} {
+	if state == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil
+	}
+	for _, field := range omitted {
+		delete(fields, field)
+	}
+	return fields
+}
+
+func actor(ctx context.Context) string {
+	if claims, ok := auth.ClaimsFromContext(ctx); ok && claims.Subject != "" {
+		return claims.Subject
+	}
+	return Anonymous
+}
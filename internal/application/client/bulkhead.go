@@ -0,0 +1,56 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+)
+
+// ErrBulkheadFull is returned when a downstream has too many calls in flight
+var ErrBulkheadFull = errors.New("too many concurrent calls")
+
+// bulkheadTransport caps the calls in flight, so a slow downstream cannot
+// tie up every goroutine of the service
+type bulkheadTransport struct {
+	name    string
+	next    http.RoundTripper
+	slots   chan struct{}
+	maxWait time.Duration
+}
+
+func newBulkheadTransport(name string, next http.RoundTripper, cfg config.BulkheadConfig) *bulkheadTransport {
+	return &bulkheadTransport{
+		name:    name,
+		next:    next,
+		slots:   make(chan struct{}, cfg.MaxConcurrent),
+		maxWait: cfg.MaxWait,
+	}
+}
+
+func (t *bulkheadTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	select {
+	case t.slots <- struct{}{}:
+	default:
+		timer := time.NewTimer(t.maxWait)
+		defer timer.Stop()
+		select {
+		case t.slots <- struct{}{}:
+		case <-timer.C:
+			events.WithLabelValues(t.name, "bulkhead_rejected").Inc()
+			return nil, ErrBulkheadFull
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+	release := func() { <-t.slots }
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
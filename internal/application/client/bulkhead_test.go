@@ -0,0 +1,33 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+)
+
+func TestBulkheadRejectsCallsOverCapacity(t *testing.T) {
+	next := roundTripFunc(func(req *http.Request) (*http.Response, error) { return respond(http.StatusOK), nil })
+	transport := newBulkheadTransport("test", next, config.BulkheadConfig{MaxConcurrent: 1, MaxWait: 10 * time.Millisecond})
+
+	req, _ := http.NewRequest(http.MethodGet, "http://downstream/", nil)
+	// The slot is held until the body is closed
+	first, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := transport.RoundTrip(req); !errors.Is(err, ErrBulkheadFull) {
+		t.Fatalf("got %v, want ErrBulkheadFull", err)
+	}
+
+	first.Body.Close()
+	second, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("got %v once the slot was released", err)
+	}
+	second.Body.Close()
+}
@@ -0,0 +1,197 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxResponseSize bounds the response bodies read into memory
+	maxResponseSize = 10 << 20
+	// maxErrorBody bounds the body kept in an UpstreamError
+	maxErrorBody = 512
+)
+
+// Empty is the request or response type of calls without a JSON body. Empty
+// responses are not decoded, so any body is accepted.
+type Empty struct{}
+
+// Client calls one downstream service; make calls with Do
+type Client struct {
+	name    string
+	baseURL *url.URL
+	timeout time.Duration
+	http    *http.Client
+}
+
+// New returns a client for the downstream called name, protected by policy
+func New(name string, cfg config.DownstreamConfig, policy config.ResiliencePolicyConfig) (*Client, error) {
+	baseURL, err := url.Parse(cfg.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("downstream %s: %w", name, err)
+	}
+	return &Client{
+		name:    name,
+		baseURL: baseURL,
+		timeout: policy.Timeout,
+		http:    &http.Client{Transport: NewPolicyTransport(name, policy)},
+	}, nil
+}
+
+type callOptions struct {
+	timeout time.Duration
+	header  http.Header
+	query   url.Values
+}
+
+type CallOption func(*callOptions)
+
+// WithTimeout bounds a call by d instead of the downstream's timeout, if shorter
+func WithTimeout(d time.Duration) CallOption {
+	return func(o *callOptions) {
+		if d < o.timeout {
+			o.timeout = d
+		}
+	}
+}
+
+// WithHeader sets a request header
+func WithHeader(key, value string) CallOption {
+	return func(o *callOptions) {
+		o.header.Set(key, value)
+	}
+}
+
+// WithIdempotencyKey lets the policy retry and hedge an unsafe request such
+// as a POST; the downstream must ignore repeats of the key
+func WithIdempotencyKey(key string) CallOption {
+	return WithHeader(IdempotencyKeyHeader, key)
+}
+
+// WithQuery sets the query string
+func WithQuery(query url.Values) CallOption {
+	return func(o *callOptions) {
+		o.query = query
+	}
+}
+
+// Do sends req as JSON to path, relative to the downstream's base URL, and
+// decodes the JSON response into a Res. A nil req sends no body. Failures are
+// returned as *UpstreamError.
+func Do[Req, Res any](ctx context.Context, c *Client, method, path string, req *Req, opts ...CallOption) (*Res, error) {
+	var payload []byte
+	if req != nil {
+		var err error
+		if payload, err = json.Marshal(req); err != nil {
+			return nil, fmt.Errorf("encode %s request: %w", c.name, err)
+		}
+	}
+
+	res := new(Res)
+	decode := func(data []byte) error {
+		if _, empty := any(res).(*Empty); empty {
+			return nil
+		}
+		if len(data) == 0 {
+			return errors.New("empty response body")
+		}
+		return json.Unmarshal(data, res)
+	}
+	if err := c.send(ctx, method, path, payload, decode, opts); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// send makes the call and decodes the body of a successful response
+func (c *Client) send(ctx context.Context, method, path string, payload []byte, decode func([]byte) error, opts []CallOption) error {
+	options := callOptions{timeout: c.timeout, header: http.Header{}}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, options.timeout)
+	defer cancel()
+
+	tracer := otel.GetTracerProvider().Tracer("")
+	ctx, span := tracer.Start(ctx, c.name+" "+method+" "+path)
+	span.SetAttributes(
+		attribute.String("downstream.name", c.name),
+		attribute.String("downstream.method", method),
+		attribute.String("downstream.path", path),
+	)
+	defer span.End()
+
+	u := c.baseURL.JoinPath(path)
+	u.RawQuery = options.query.Encode()
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return fmt.Errorf("create %s request: %w", c.name, err)
+	}
+	httpReq.Header = options.header
+	httpReq.Header.Set("Accept", "application/json")
+	if payload != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+
+	start := time.Now()
+	resp, err := c.http.Do(httpReq)
+	if err != nil {
+		return c.fail(ctx, &UpstreamError{Service: c.name, Method: method, Path: path, Kind: transportKind(err), Err: err})
+	}
+	defer resp.Body.Close()
+	span.SetAttributes(attribute.Int("downstream.status_code", resp.StatusCode))
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return c.fail(ctx, &UpstreamError{Service: c.name, Method: method, Path: path, Kind: transportKind(err), Err: err})
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		errorBody := data
+		if len(errorBody) > maxErrorBody {
+			errorBody = errorBody[:maxErrorBody]
+		}
+		return c.fail(ctx, &UpstreamError{Service: c.name, Method: method, Path: path, Kind: ErrStatus, StatusCode: resp.StatusCode, Body: string(errorBody)})
+	}
+
+	if err := decode(data); err != nil {
+		return c.fail(ctx, &UpstreamError{Service: c.name, Method: method, Path: path, Kind: ErrDecode, Err: err})
+	}
+
+	zap.L().Debug("Downstream call succeeded", append(logger.GetTraceFields(ctx),
+		zap.String("downstream", c.name), zap.String("method", method), zap.String("path", path),
+		zap.Int("status", resp.StatusCode), zap.Duration("duration", time.Since(start)))...)
+	return nil
+}
+
+// fail logs and traces a failed call
+func (c *Client) fail(ctx context.Context, err *UpstreamError) error {
+	zap.L().Error("Downstream call failed", append(logger.GetTraceFieldsWithError(ctx, err),
+		zap.String("downstream", c.name), zap.String("method", err.Method), zap.String("path", err.Path),
+		zap.Int("status", err.StatusCode))...)
+
+	if span := trace.SpanFromContext(ctx); span.IsRecording() {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Kind.Error())
+	}
+	return err
+}
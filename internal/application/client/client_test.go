@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+	"github.com/gofiber/fiber/v2"
+)
+
+type greeting struct {
+	Message string `json:"message"`
+}
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	c, err := New("test", config.DownstreamConfig{BaseURL: server.URL}, config.ResiliencePolicyConfig{Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestDoDecodesResponse(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"message":"hello"}`))
+	})
+
+	res, err := Do[Empty, greeting](context.Background(), c, http.MethodGet, "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Message != "hello" {
+		t.Fatalf("got %q, want hello", res.Message)
+	}
+}
+
+func TestDoRejectsEmptyBody(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {})
+
+	if _, err := Do[Empty, greeting](context.Background(), c, http.MethodGet, "/", nil); !errors.Is(err, ErrDecode) {
+		t.Fatalf("got %v, want ErrDecode", err)
+	}
+	if _, err := Do[Empty, Empty](context.Background(), c, http.MethodGet, "/", nil); err != nil {
+		t.Fatalf("got %v for an Empty response", err)
+	}
+}
+
+func TestHTTPError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"timeout", &UpstreamError{Service: "demo", Kind: ErrTimeout}, fiber.StatusGatewayTimeout},
+		{"unavailable", &UpstreamError{Service: "demo", Kind: ErrUnavailable}, fiber.StatusBadGateway},
+		{"error status", &UpstreamError{Service: "demo", Kind: ErrStatus, StatusCode: 500, Body: "secret"}, fiber.StatusBadGateway},
+		{"undecodable", &UpstreamError{Service: "demo", Kind: ErrDecode}, fiber.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fiberErr *fiber.Error
+			if !errors.As(HTTPError(tt.err), &fiberErr) || fiberErr.Code != tt.code {
+				t.Fatalf("got %v, want status %d", HTTPError(tt.err), tt.code)
+			}
+			if fiberErr.Message != "demo: "+tt.err.(*UpstreamError).Kind.Error() {
+				t.Errorf("got message %q", fiberErr.Message)
+			}
+		})
+	}
+
+	other := errors.New("other")
+	if HTTPError(other) != other {
+		t.Error("other errors should be returned unchanged")
+	}
+}
@@ -0,0 +1,30 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// Demo calls the demo downstream, whose endpoints succeed, fail or time out on purpose
+type Demo struct {
+	client *Client
+}
+
+func NewDemo(client *Client) *Demo {
+	return &Demo{client: client}
+}
+
+func (d *Demo) Test(ctx context.Context) error {
+	_, err := Do[Empty, Empty](ctx, d.client, http.MethodGet, "/test", nil)
+	return err
+}
+
+func (d *Demo) Error(ctx context.Context) error {
+	_, err := Do[Empty, Empty](ctx, d.client, http.MethodGet, "/error", nil)
+	return err
+}
+
+func (d *Demo) Timeout(ctx context.Context) error {
+	_, err := Do[Empty, Empty](ctx, d.client, http.MethodGet, "/timeout", nil)
+	return err
+}
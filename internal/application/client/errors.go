@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	circuitbreaker "github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/circuitbraker"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Kinds of UpstreamError, matched with errors.Is
+var (
+	ErrUnavailable = errors.New("downstream is unavailable")
+	ErrTimeout     = errors.New("downstream timed out")
+	ErrStatus      = errors.New("downstream responded with an error status")
+	ErrDecode      = errors.New("downstream response could not be decoded")
+)
+
+// UpstreamError describes a failed call to a downstream service
+type UpstreamError struct {
+	Service string
+	Method  string
+	Path    string
+	Kind    error
+	// StatusCode and Body are set when the downstream responded with an error status
+	StatusCode int
+	Body       string
+	// Err is the underlying error, if any
+	Err error
+}
+
+func (e *UpstreamError) Error() string {
+	msg := fmt.Sprintf("%s %s %s: %s", e.Service, e.Method, e.Path, e.Kind)
+	if e.StatusCode != 0 {
+		msg += fmt.Sprintf(" %d", e.StatusCode)
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *UpstreamError) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *UpstreamError) Unwrap() error {
+	return e.Err
+}
+
+// HTTPError maps an *UpstreamError to the *fiber.Error handlers return: 504
+// when the downstream timed out and 502 otherwise. The message names the
+// downstream and the kind of failure only, not its response. Other errors are
+// returned unchanged.
+func HTTPError(err error) error {
+	var upstream *UpstreamError
+	if !errors.As(err, &upstream) {
+		return err
+	}
+	code := fiber.StatusBadGateway
+	if errors.Is(upstream, ErrTimeout) {
+		code = fiber.StatusGatewayTimeout
+	}
+	return fiber.NewError(code, upstream.Service+": "+upstream.Kind.Error())
+}
+
+// transportKind classifies an error of the HTTP client
+func transportKind(err error) error {
+	var openErr *circuitbreaker.OpenCircuitError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &openErr):
+		return ErrUnavailable
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return ErrTimeout
+	default:
+		return ErrUnavailable
+	}
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+)
+
+// hedgingTransport sends extra copies of slow repeatable requests and returns
+// the first successful response, cancelling the other copies
+type hedgingTransport struct {
+	name string
+	next http.RoundTripper
+	cfg  config.HedgingConfig
+}
+
+type hedgeResult struct {
+	index int
+	resp  *http.Response
+	err   error
+}
+
+func (t *hedgingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !repeatable(req) {
+		return t.next.RoundTrip(req)
+	}
+
+	results := make(chan hedgeResult, t.cfg.MaxHedges+1)
+	var cancels []context.CancelFunc
+	send := func() {
+		ctx, cancel := context.WithCancel(req.Context())
+		index := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			hedge, err := withContext(ctx, req)
+			if err != nil {
+				results <- hedgeResult{index: index, err: err}
+				return
+			}
+			resp, err := t.next.RoundTrip(hedge)
+			results <- hedgeResult{index: index, resp: resp, err: err}
+		}()
+	}
+
+	send()
+	pending := 1
+	timer := time.NewTimer(t.cfg.Delay)
+	defer timer.Stop()
+
+	// Failures only end the call once no index is left in flight; the policy's
+	// retries then decide whether to try again
+	var last hedgeResult
+	for pending > 0 {
+		select {
+		case <-timer.C:
+			if len(cancels) <= t.cfg.MaxHedges {
+				events.WithLabelValues(t.name, "hedge").Inc()
+				send()
+				pending++
+				timer.Reset(t.cfg.Delay)
+			}
+		case res := <-results:
+			pending--
+			if res.err == nil && res.resp.StatusCode < http.StatusInternalServerError {
+				t.abandon(cancels, res.index, results, pending)
+				res.resp.Body = &releaseBody{ReadCloser: res.resp.Body, release: cancels[res.index]}
+				return res.resp, nil
+			}
+			if pending > 0 {
+				discard(res.resp)
+				cancels[res.index]()
+				continue
+			}
+			last = res
+		}
+	}
+
+	if last.err != nil {
+		cancels[last.index]()
+		return nil, last.err
+	}
+	last.resp.Body = &releaseBody{ReadCloser: last.resp.Body, release: cancels[last.index]}
+	return last.resp, nil
+}
+
+// abandon cancels every index but the winner and discards the responses still
+// to come
+func (t *hedgingTransport) abandon(cancels []context.CancelFunc, winner int, results <-chan hedgeResult, pending int) {
+	for i, cancel := range cancels {
+		if i != winner {
+			cancel()
+		}
+	}
+	if pending > 0 {
+		go func() {
+			for ; pending > 0; pending-- {
+				discard((<-results).resp)
+			}
+		}()
+	}
+}
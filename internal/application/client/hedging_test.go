@@ -0,0 +1,65 @@
+package client
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+)
+
+func TestHedgingCancelsSlowerCopies(t *testing.T) {
+	var calls atomic.Int32
+	cancelled := make(chan struct{})
+	next := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if calls.Add(1) == 1 {
+			// The original hangs until the hedge wins
+			<-req.Context().Done()
+			close(cancelled)
+			return nil, req.Context().Err()
+		}
+		return respond(http.StatusOK), nil
+	})
+	transport := &hedgingTransport{name: "test", next: next, cfg: config.HedgingConfig{MaxHedges: 1, Delay: 10 * time.Millisecond}}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://downstream/", nil)
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want 200", resp.StatusCode)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("the original request was not cancelled")
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("got %d calls, want 2", calls.Load())
+	}
+}
+
+func TestHedgingSkipsUnsafeRequests(t *testing.T) {
+	var calls atomic.Int32
+	next := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls.Add(1)
+		time.Sleep(30 * time.Millisecond)
+		return respond(http.StatusOK), nil
+	})
+	transport := &hedgingTransport{name: "test", next: next, cfg: config.HedgingConfig{MaxHedges: 2, Delay: time.Millisecond}}
+
+	req, _ := http.NewRequest(http.MethodPost, "http://downstream/", nil)
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if calls.Load() != 1 {
+		t.Fatalf("got %d calls, want 1", calls.Load())
+	}
+}
@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	circuitbreaker "github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/circuitbraker"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// IdempotencyKeyHeader marks a request as safe to send more than once
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+var events = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "http_client_resilience_events_total",
+	Help: "Retries, hedges and calls rejected by a bulkhead or retry budget, by downstream.",
+}, []string{"downstream", "event"})
+
+// NewPolicyTransport protects calls to the downstream called name with a
+// resilience policy. Each attempt is traced.
+func NewPolicyTransport(name string, policy config.ResiliencePolicyConfig) http.RoundTripper {
+	return protect(name, policy, NewTransport(policy))
+}
+
+// protect wraps base in the layers of policy
+func protect(name string, policy config.ResiliencePolicyConfig, base http.RoundTripper) http.RoundTripper {
+	transport := base
+	if breaker := policy.CircuitBreaker; breaker.Enabled {
+		transport = circuitbreaker.NewTransport(transport, circuitbreaker.CircuitBreakerConfig{
+			Name:                    "http:" + name,
+			MaxRequests:             breaker.MaxRequests,
+			Interval:                breaker.Interval,
+			Timeout:                 breaker.Timeout,
+			RequestsVolumeThreshold: breaker.RequestsVolumeThreshold,
+			FailureThreshold:        breaker.FailureThreshold,
+		})
+	}
+	if policy.Hedging.MaxHedges > 0 {
+		transport = &hedgingTransport{name: name, next: transport, cfg: policy.Hedging}
+	}
+	transport = otelhttp.NewTransport(transport)
+	if policy.Retry.MaxRetries > 0 || policy.AttemptTimeout > 0 {
+		transport = newRetryTransport(name, transport, policy.Retry, policy.AttemptTimeout)
+	}
+	if policy.Bulkhead.MaxConcurrent > 0 {
+		transport = newBulkheadTransport(name, transport, policy.Bulkhead)
+	}
+	return transport
+}
+
+// repeatable reports whether req may be sent more than once: its method is
+// safe or it carries an idempotency key, and its body can be replayed
+func repeatable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return req.Header.Get(IdempotencyKeyHeader) != ""
+}
+
+// withContext returns a copy of req for another attempt, with a fresh body
+func withContext(ctx context.Context, req *http.Request) (*http.Request, error) {
+	clone := req.Clone(ctx)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		clone.Body = body
+	}
+	return clone, nil
+}
+
+// releaseBody runs release once the response body is closed, as the call
+// lasts until then
+type releaseBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	if b.release != nil {
+		b.release()
+		b.release = nil
+	}
+	return err
+}
+
+// discard closes a response that will not be returned, so its connection can be reused
+func discard(resp *http.Response) {
+	if resp == nil {
+		return
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+}
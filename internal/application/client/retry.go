@@ -0,0 +1,185 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	circuitbreaker "github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/circuitbraker"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+)
+
+// retryTransport retries failed attempts of repeatable requests and bounds
+// each attempt by its own timeout
+type retryTransport struct {
+	name           string
+	next           http.RoundTripper
+	cfg            config.RetryPolicyConfig
+	attemptTimeout time.Duration
+	budget         *retryBudget
+}
+
+func newRetryTransport(name string, next http.RoundTripper, cfg config.RetryPolicyConfig, attemptTimeout time.Duration) *retryTransport {
+	return &retryTransport{
+		name:           name,
+		next:           next,
+		cfg:            cfg,
+		attemptTimeout: attemptTimeout,
+		budget:         newRetryBudget(cfg.Budget),
+	}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	maxRetries := t.cfg.MaxRetries
+	if !repeatable(req) {
+		maxRetries = 0
+	}
+	t.budget.deposit()
+
+	for attempt := 0; ; attempt++ {
+		resp, err := t.attempt(req, attempt)
+		if attempt >= maxRetries || !t.retryable(ctx, resp, err) {
+			return resp, err
+		}
+		if !t.budget.withdraw() {
+			events.WithLabelValues(t.name, "retry_budget_exhausted").Inc()
+			return resp, err
+		}
+
+		wait := t.backoff(attempt, resp)
+		discard(resp)
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+		events.WithLabelValues(t.name, "retry").Inc()
+	}
+}
+
+func (t *retryTransport) attempt(req *http.Request, attempt int) (*http.Response, error) {
+	ctx, cancel := req.Context(), context.CancelFunc(func() {})
+	if t.attemptTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, t.attemptTimeout)
+	}
+
+	if attempt > 0 || t.attemptTimeout > 0 {
+		var err error
+		if req, err = withContext(ctx, req); err != nil {
+			cancel()
+			return nil, err
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// The attempt lasts until its body is read
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: cancel}
+	return resp, nil
+}
+
+// retryable reports whether a failed attempt is worth repeating. Open
+// circuits, forbidden addresses and calls the caller gave up on are not.
+func (t *retryTransport) retryable(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		var openErr *circuitbreaker.OpenCircuitError
+		return !errors.As(err, &openErr) && !errors.Is(err, ErrForbiddenAddress)
+	}
+	return slices.Contains(t.cfg.RetryableStatuses, resp.StatusCode)
+}
+
+// backoff returns the jittered wait before the retry following attempt. A
+// Retry-After header is honored up to WaitMax.
+func (t *retryTransport) backoff(attempt int, resp *http.Response) time.Duration {
+	wait := t.cfg.WaitMin
+	switch t.cfg.Backoff {
+	case "linear":
+		wait *= time.Duration(attempt + 1)
+	case "exponential":
+		wait = time.Duration(float64(wait) * math.Pow(2, float64(attempt)))
+	}
+	if wait <= 0 || wait > t.cfg.WaitMax {
+		wait = t.cfg.WaitMax
+	}
+	// Jitter within the upper half spreads out the retries of many callers
+	if half := wait / 2; half > 0 {
+		wait = half + rand.N(half)
+	}
+
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			if after := time.Duration(seconds) * time.Second; after > wait {
+				wait = min(after, t.cfg.WaitMax)
+			}
+		}
+	}
+	return wait
+}
+
+// retryBudget is a token bucket: each call deposits Ratio tokens, MinPerSecond
+// tokens are added every second, and each retry takes one
+type retryBudget struct {
+	ratio        float64
+	minPerSecond float64
+	capacity     float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newRetryBudget(cfg config.RetryBudgetConfig) *retryBudget {
+	if cfg.Ratio == 0 {
+		return nil
+	}
+	// A burst of up to ten seconds of retries can be saved up
+	capacity := max(10*cfg.MinPerSecond, 10)
+	return &retryBudget{
+		ratio:        cfg.Ratio,
+		minPerSecond: cfg.MinPerSecond,
+		capacity:     capacity,
+		tokens:       capacity,
+		last:         time.Now(),
+	}
+}
+
+func (b *retryBudget) deposit() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.tokens+b.ratio, b.capacity)
+}
+
+func (b *retryBudget) withdraw() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens = min(b.tokens+now.Sub(b.last).Seconds()*b.minPerSecond, b.capacity)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
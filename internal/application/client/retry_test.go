@@ -0,0 +1,89 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func respond(status int) *http.Response {
+	return &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(""))}
+}
+
+func retryPolicy(budget config.RetryBudgetConfig) config.RetryPolicyConfig {
+	return config.RetryPolicyConfig{
+		MaxRetries:        3,
+		Backoff:           "constant",
+		WaitMin:           time.Millisecond,
+		WaitMax:           time.Millisecond,
+		RetryableStatuses: []int{http.StatusServiceUnavailable},
+		Budget:            budget,
+	}
+}
+
+func unavailable(attempts *atomic.Int32) roundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		attempts.Add(1)
+		return respond(http.StatusServiceUnavailable), nil
+	}
+}
+
+func TestRetryRetriesUpToMaxRetries(t *testing.T) {
+	var attempts atomic.Int32
+	transport := newRetryTransport("test", unavailable(&attempts), retryPolicy(config.RetryBudgetConfig{}), 0)
+
+	req, _ := http.NewRequest(http.MethodGet, "http://downstream/", nil)
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable || attempts.Load() != 4 {
+		t.Fatalf("got status %d after %d attempts, want 503 after 4", resp.StatusCode, attempts.Load())
+	}
+}
+
+func TestRetryStopsWhenBudgetIsExhausted(t *testing.T) {
+	var attempts atomic.Int32
+	transport := newRetryTransport("test", unavailable(&attempts), retryPolicy(config.RetryBudgetConfig{Ratio: 0.1}), 0)
+	for transport.budget.withdraw() {
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://downstream/", nil)
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	// The call earns 0.1 retries, less than the one a retry takes
+	if resp.StatusCode != http.StatusServiceUnavailable || attempts.Load() != 1 {
+		t.Fatalf("got status %d after %d attempts, want 503 after 1", resp.StatusCode, attempts.Load())
+	}
+}
+
+func TestRetrySkipsUnsafeRequestsWithoutIdempotencyKey(t *testing.T) {
+	var attempts atomic.Int32
+	transport := newRetryTransport("test", unavailable(&attempts), retryPolicy(config.RetryBudgetConfig{}), 0)
+
+	req, _ := http.NewRequest(http.MethodPost, "http://downstream/", strings.NewReader("{}"))
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if attempts.Load() != 1 {
+		t.Fatalf("got %d attempts, want 1", attempts.Load())
+	}
+}
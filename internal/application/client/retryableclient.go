@@ -1,129 +1,61 @@
 package client
 
 import (
+	"bytes"
 	"context"
 	"io"
 	"net/http"
-	"time"
 
-	"github.com/hashicorp/go-retryablehttp"
-	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
 	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/logger"
 	"go.uber.org/zap"
 )
 
 type CustomRetryableClient struct {
-	*retryablehttp.Client
+	*http.Client
 }
 
-// NewRetryableClient creates a new retryable client
-func NewRetryableClient(transport *http.Transport) CustomRetryableClient {
-	retryClient := retryablehttp.NewClient()
-	retryClient.Logger = zap.NewStdLog(zap.L())
-	retryClient.HTTPClient.Transport = otelhttp.NewTransport(transport)
-	retryClient.RetryMax = 3
-	retryClient.RetryWaitMin = 100 * time.Millisecond
-	retryClient.RetryWaitMax = 10 * time.Second
-	retryClient.Backoff = retryablehttp.LinearJitterBackoff
-
-	retryClient.CheckRetry = func(ctx context.Context, resp *http.Response, err error) (bool, error) {
-		if ctx.Err() != nil {
-			return false, ctx.Err()
-		}
-		return retryablehttp.DefaultRetryPolicy(ctx, resp, err)
-	}
-	return CustomRetryableClient{retryClient}
-}
-
-func (c *CustomRetryableClient) GetTimeout(ctx context.Context) error {
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost:8081/timeout", nil)
-	if err != nil {
-		zap.L().Error("Failed to create request to timeout", logger.GetTraceFieldsWithError(ctx, err)...)
-		return err
-	}
-
-	retryableRequest, err := retryablehttp.FromRequest(req)
-	if err != nil {
-		zap.L().Error("Failed to create retryable request", logger.GetTraceFieldsWithError(ctx, err)...)
-		return err
-	}
-
-	resp, err := c.Client.Do(retryableRequest)
-	if err != nil {
-		zap.L().Error("Failed to make request to timeout", logger.GetTraceFieldsWithError(ctx, err)...)
-		return err
-	}
-
-	defer resp.Body.Close()
-
-	_, err = io.ReadAll(resp.Body)
-	if err != nil {
-		zap.L().Error("Failed to read response from timeout", logger.GetTraceFieldsWithError(ctx, err)...)
-		return err
-	}
-
-	return nil
+// NewRetryableClient creates a client for arbitrary URLs, protected by policy
+// and bounded by its timeout. It has no circuit breakers, as the URLs, such as
+// those of webhook subscribers, may be tenant supplied and would each get one.
+// For the same reason it only connects to public addresses, unless
+// allowPrivate is set.
+func NewRetryableClient(name string, policy config.ResiliencePolicyConfig, allowPrivate bool) CustomRetryableClient {
+	policy.CircuitBreaker.Enabled = false
+	base := NewPublicTransport(policy)
+	if allowPrivate {
+		base = NewTransport(policy)
+	}
+	return CustomRetryableClient{&http.Client{
+		Transport: protect(name, policy, base),
+		Timeout:   policy.Timeout,
+	}}
 }
 
-func (c *CustomRetryableClient) GetError(ctx context.Context) error {
+// Post sends a JSON body with the given headers and returns the status code of the final response
+func (c *CustomRetryableClient) Post(ctx context.Context, url string, body []byte, headers map[string]string) (int, error) {
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost:8081/error", nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
 	if err != nil {
-		zap.L().Error("Failed to create request to google", logger.GetTraceFieldsWithError(ctx, err)...)
-		return err
+		zap.L().Error("Failed to create request", logger.GetTraceFieldsWithError(ctx, err)...)
+		return 0, err
 	}
 
-	retryableRequest, err := retryablehttp.FromRequest(req)
-	if err != nil {
-		zap.L().Error("Failed to create retryable request", logger.GetTraceFieldsWithError(ctx, err)...)
-		return err
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
 	}
 
-	resp, err := c.Client.Do(retryableRequest)
+	resp, err := c.Client.Do(req)
 	if err != nil {
-		zap.L().Error("Failed to make request to error", logger.GetTraceFieldsWithError(ctx, err)...)
-		return err
+		zap.L().Error("Failed to make request", append(logger.GetTraceFieldsWithError(ctx, err), zap.String("url", url))...)
+		return 0, err
 	}
 
 	defer resp.Body.Close()
 
-	_, err = io.ReadAll(resp.Body)
-	if err != nil {
-		zap.L().Error("Failed to read response from error", logger.GetTraceFieldsWithError(ctx, err)...)
-		return err
-	}
-
-	return nil
-}
-
-func (c *CustomRetryableClient) GetTest(ctx context.Context) error {
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost:8081/test", nil)
-	if err != nil {
-		zap.L().Error("Failed to create request to google", logger.GetTraceFieldsWithError(ctx, err)...)
-		return err
-	}
-
-	retryableRequest, err := retryablehttp.FromRequest(req)
-	if err != nil {
-		zap.L().Error("Failed to create retryable request", logger.GetTraceFieldsWithError(ctx, err)...)
-		return err
-	}
-
-	resp, err := c.Client.Do(retryableRequest)
-	if err != nil {
-		zap.L().Error("Failed to make request to test", logger.GetTraceFieldsWithError(ctx, err)...)
-		return err
-	}
-
-	defer resp.Body.Close()
-
-	_, err = io.ReadAll(resp.Body)
-	if err != nil {
-		zap.L().Error("Failed to read response from test", logger.GetTraceFieldsWithError(ctx, err)...)
-		return err
-	}
+	// Drain a bounded amount so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
 
-	return nil
+	return resp.StatusCode, nil
 }
@@ -1,19 +1,79 @@
 package client
 
 import (
+	"errors"
 	"net"
 	"net/http"
+	"net/netip"
+	"syscall"
 	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
 )
 
-func NewTransport() *http.Transport {
+// ErrForbiddenAddress is returned when a call would connect to an address
+// that is not publicly routable
+var ErrForbiddenAddress = errors.New("address is not publicly routable")
+
+// nonPublic are the ranges IsGlobalUnicast and IsPrivate leave out, such as
+// carrier-grade NAT and NAT64, which can reach internal IPv4 hosts
+var nonPublic = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+}
+
+func NewTransport(policy config.ResiliencePolicyConfig) *http.Transport {
 	return &http.Transport{
-		Dial: (&net.Dialer{
-			Timeout:   30 * time.Second,
+		DialContext: (&net.Dialer{
+			Timeout:   policy.ConnectTimeout,
 			KeepAlive: 30 * time.Second,
-		}).Dial,
-		TLSHandshakeTimeout:   10 * time.Second,
-		ResponseHeaderTimeout: 10 * time.Second,
+		}).DialContext,
+		TLSHandshakeTimeout:   policy.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: policy.ResponseHeaderTimeout,
 		ExpectContinueTimeout: 1 * time.Second,
 	}
 }
+
+// NewPublicTransport is NewTransport refusing to connect to loopback, private,
+// link local and other addresses that are not publicly routable. Addresses
+// are checked when dialing, after DNS resolution and on redirects, so a
+// public name cannot point the call into the internal network.
+func NewPublicTransport(policy config.ResiliencePolicyConfig) *http.Transport {
+	transport := NewTransport(policy)
+	transport.DialContext = (&net.Dialer{
+		Timeout:   policy.ConnectTimeout,
+		KeepAlive: 30 * time.Second,
+		Control:   publicOnly,
+	}).DialContext
+	return transport
+}
+
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !publicAddr(addrPort.Addr()) {
+		return ErrForbiddenAddress
+	}
+	return nil
+}
+
+// publicAddr reports whether addr is publicly routable
+func publicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublic {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
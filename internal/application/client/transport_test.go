@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+)
+
+func TestPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"fd00::1", false},
+		{"fe80::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"64:ff9b::a00:1", false},
+	}
+	for _, tt := range tests {
+		if got := publicAddr(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("publicAddr(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestRetryableClientRefusesPrivateTargets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	c := NewRetryableClient("test", config.ResiliencePolicyConfig{}, false)
+	if _, err := c.Post(context.Background(), server.URL, []byte("{}"), nil); !errors.Is(err, ErrForbiddenAddress) {
+		t.Fatalf("got %v, want ErrForbiddenAddress", err)
+	}
+
+	c = NewRetryableClient("test", config.ResiliencePolicyConfig{}, true)
+	if status, err := c.Post(context.Background(), server.URL, []byte("{}"), nil); err != nil || status != http.StatusOK {
+		t.Fatalf("got %d, %v with private targets allowed", status, err)
+	}
+}
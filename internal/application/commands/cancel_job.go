@@ -0,0 +1,24 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/job"
+	"github.com/google/uuid"
+)
+
+type CancelJobCommand struct {
+	ID uuid.UUID `params:"id"`
+}
+
+type CancelJobHandler struct {
+	repo job.Repository
+}
+
+func NewCancelJobHandler(repo job.Repository) *CancelJobHandler {
+	return &CancelJobHandler{repo: repo}
+}
+
+func (h *CancelJobHandler) Handle(ctx context.Context, cmd *CancelJobCommand) (*job.Job, error) {
+	return h.repo.RequestCancel(ctx, cmd.ID)
+}
@@ -0,0 +1,25 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/product"
+	"github.com/google/uuid"
+)
+
+// ConfirmReservationCommand sells the reserved units, taking them off the stock
+type ConfirmReservationCommand struct {
+	ID uuid.UUID `params:"id"`
+}
+
+type ConfirmReservationHandler struct {
+	repo product.ReservationRepository
+}
+
+func NewConfirmReservationHandler(repo product.ReservationRepository) *ConfirmReservationHandler {
+	return &ConfirmReservationHandler{repo: repo}
+}
+
+func (h *ConfirmReservationHandler) Handle(ctx context.Context, cmd *ConfirmReservationCommand) (*product.Reservation, error) {
+	return h.repo.ConfirmReservation(ctx, cmd.ID)
+}
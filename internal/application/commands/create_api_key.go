@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/apikey"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/auth"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+	"github.com/gofiber/fiber/v2"
+)
+
+type CreateAPIKeyCommand struct {
+	Name          string     `json:"name"`
+	Scopes        []string   `json:"scopes"`
+	RateLimitTier string     `json:"rate_limit_tier"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+}
+
+// CreateAPIKeyResponse is the only response that reveals the key
+type CreateAPIKeyResponse struct {
+	*apikey.APIKey
+	Key string `json:"key"`
+}
+
+func (CreateAPIKeyResponse) StatusCode() int { return fiber.StatusCreated }
+
+type CreateAPIKeyHandler struct {
+	repo   apikey.Repository
+	cfg    config.APIKeysConfig
+	scopes []string
+}
+
+// NewCreateAPIKeyHandler creates the handler; scopes lists the permissions keys may be granted
+func NewCreateAPIKeyHandler(repo apikey.Repository, cfg config.APIKeysConfig, scopes []string) *CreateAPIKeyHandler {
+	return &CreateAPIKeyHandler{repo: repo, cfg: cfg, scopes: scopes}
+}
+
+func (h *CreateAPIKeyHandler) Handle(ctx context.Context, cmd *CreateAPIKeyCommand) (*CreateAPIKeyResponse, error) {
+	for _, scope := range cmd.Scopes {
+		if !slices.Contains(h.scopes, scope) {
+			return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("unknown scope %q", scope))
+		}
+	}
+
+	tier := cmd.RateLimitTier
+	if tier == "" {
+		tier = h.cfg.DefaultTier
+	}
+	if !slices.Contains(h.cfg.Tiers, tier) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("unknown rate limit tier %q", tier))
+	}
+
+	var createdBy string
+	if claims, ok := auth.ClaimsFromContext(ctx); ok {
+		createdBy = claims.Subject
+	}
+
+	key, plaintext, err := apikey.NewAPIKey(cmd.Name, cmd.Scopes, tier, createdBy, cmd.ExpiresAt)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	if err := h.repo.Save(ctx, key); err != nil {
+		return nil, err
+	}
+
+	return &CreateAPIKeyResponse{APIKey: key, Key: plaintext}, nil
+}
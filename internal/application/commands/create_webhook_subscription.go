@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/webhook"
+	"github.com/gofiber/fiber/v2"
+)
+
+type CreateWebhookSubscriptionCommand struct {
+	URL         string   `json:"url"`
+	Description string   `json:"description"`
+	EventTypes  []string `json:"event_types"`
+	Secret      string   `json:"secret"`
+}
+
+// CreateWebhookSubscriptionResponse is the only response that reveals the shared secret
+type CreateWebhookSubscriptionResponse struct {
+	*webhook.Subscription
+	Secret string `json:"secret"`
+}
+
+type CreateWebhookSubscriptionHandler struct {
+	repo webhook.Repository
+}
+
+func NewCreateWebhookSubscriptionHandler(repo webhook.Repository) *CreateWebhookSubscriptionHandler {
+	return &CreateWebhookSubscriptionHandler{repo: repo}
+}
+
+func (h *CreateWebhookSubscriptionHandler) Handle(ctx context.Context, cmd *CreateWebhookSubscriptionCommand) (*CreateWebhookSubscriptionResponse, error) {
+	subscription, err := webhook.NewSubscription(cmd.URL, cmd.Description, cmd.EventTypes, cmd.Secret)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	if err := h.repo.SaveSubscription(ctx, subscription); err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return &CreateWebhookSubscriptionResponse{
+		Subscription: subscription,
+		Secret:       subscription.Secret,
+	}, nil
+}
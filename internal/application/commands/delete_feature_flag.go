@@ -0,0 +1,35 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/features"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/featureflag"
+)
+
+// DeleteFeatureFlagCommand removes a flag saved through the API. A flag
+// defined in the config file reverts to that definition.
+type DeleteFeatureFlagCommand struct {
+	Key string `params:"key"`
+}
+
+type DeleteFeatureFlagHandler struct {
+	flags *features.Service
+}
+
+func NewDeleteFeatureFlagHandler(flags *features.Service) *DeleteFeatureFlagHandler {
+	return &DeleteFeatureFlagHandler{flags: flags}
+}
+
+func (h *DeleteFeatureFlagHandler) Handle(ctx context.Context, cmd *DeleteFeatureFlagCommand) (*featureflag.Flag, error) {
+	flag, err := h.flags.Flag(ctx, cmd.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.flags.Delete(ctx, cmd.Key); err != nil {
+		return nil, err
+	}
+
+	return flag, nil
+}
@@ -0,0 +1,33 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/webhook"
+	"github.com/google/uuid"
+)
+
+type DeleteWebhookSubscriptionCommand struct {
+	ID uuid.UUID `params:"id"`
+}
+
+type DeleteWebhookSubscriptionHandler struct {
+	repo webhook.Repository
+}
+
+func NewDeleteWebhookSubscriptionHandler(repo webhook.Repository) *DeleteWebhookSubscriptionHandler {
+	return &DeleteWebhookSubscriptionHandler{repo: repo}
+}
+
+func (h *DeleteWebhookSubscriptionHandler) Handle(ctx context.Context, cmd *DeleteWebhookSubscriptionCommand) (*webhook.Subscription, error) {
+	subscription, err := h.repo.GetSubscription(ctx, cmd.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.repo.DeleteSubscription(ctx, cmd.ID); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
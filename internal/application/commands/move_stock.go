@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/product"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/feature"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// RequireStockIdempotencyKey is the feature flag making MoveStockCommand
+// reject requests without an Idempotency-Key, while clients start sending one
+const RequireStockIdempotencyKey = "require-stock-idempotency-key"
+
+// MoveStockCommand changes the stock of a product relative to its current
+// level. Quantity is the number of units received, sold, returned or written
+// off; for adjustments it is the signed change.
+type MoveStockCommand struct {
+	ProductID uuid.UUID            `json:"product_id" params:"id"`
+	Type      product.MovementType `json:"type"`
+	Quantity  int                  `json:"quantity"`
+	// Reference identifies the cause, e.g. a delivery note or an order
+	Reference string `json:"reference"`
+	// IdempotencyKey makes retries of the request return the movement it
+	// made instead of moving the stock again
+	IdempotencyKey string `json:"-" reqHeader:"Idempotency-Key"`
+}
+
+type MoveStockResponse struct {
+	*product.Movement
+}
+
+func (MoveStockResponse) StatusCode() int { return fiber.StatusCreated }
+
+type MoveStockHandler struct {
+	repo product.MovementRepository
+}
+
+func NewMoveStockHandler(repo product.MovementRepository) *MoveStockHandler {
+	return &MoveStockHandler{repo: repo}
+}
+
+func (h *MoveStockHandler) Handle(ctx context.Context, cmd *MoveStockCommand) (*MoveStockResponse, error) {
+	if cmd.IdempotencyKey == "" && feature.Enabled(ctx, RequireStockIdempotencyKey) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Idempotency-Key header is required")
+	}
+
+	movement, err := h.repo.MoveStock(ctx, cmd.ProductID, cmd.Type, cmd.Quantity, cmd.Reference, cmd.IdempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+	return &MoveStockResponse{Movement: movement}, nil
+}
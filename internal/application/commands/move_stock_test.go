@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/product"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/feature"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// flags turns on the feature flags set to true
+type flags map[string]bool
+
+func (f flags) Enabled(_ context.Context, key string) bool { return f[key] }
+
+func withFlags(t *testing.T, f flags) {
+	t.Helper()
+	feature.SetEvaluator(f)
+	t.Cleanup(func() { feature.SetEvaluator(nil) })
+}
+
+// movementRepository records the stock movements it was asked to make
+type movementRepository struct {
+	product.MovementRepository
+	moves int
+}
+
+func (r *movementRepository) MoveStock(_ context.Context, productID uuid.UUID, t product.MovementType, quantity int, reference, idempotencyKey string) (*product.Movement, error) {
+	r.moves++
+	return &product.Movement{ProductID: productID, Type: t, Delta: quantity, Reference: reference, IdempotencyKey: idempotencyKey}, nil
+}
+
+func TestMoveStockRequiresIdempotencyKeyWhenFlagged(t *testing.T) {
+	withFlags(t, flags{RequireStockIdempotencyKey: true})
+	repo := &movementRepository{}
+	handler := NewMoveStockHandler(repo)
+
+	_, err := handler.Handle(context.Background(), &MoveStockCommand{ProductID: uuid.New(), Type: product.MovementReceipt, Quantity: 1})
+	var fiberErr *fiber.Error
+	if !errors.As(err, &fiberErr) || fiberErr.Code != fiber.StatusBadRequest {
+		t.Fatalf("got %v, want 400", err)
+	}
+	if repo.moves != 0 {
+		t.Fatalf("stock was moved %d times", repo.moves)
+	}
+
+	if _, err := handler.Handle(context.Background(), &MoveStockCommand{ProductID: uuid.New(), Type: product.MovementReceipt, Quantity: 1, IdempotencyKey: "key"}); err != nil {
+		t.Fatalf("got %v with a key", err)
+	}
+}
+
+func TestMoveStockAcceptsMissingKeyWhenNotFlagged(t *testing.T) {
+	withFlags(t, flags{})
+	repo := &movementRepository{}
+
+	if _, err := NewMoveStockHandler(repo).Handle(context.Background(), &MoveStockCommand{ProductID: uuid.New(), Type: product.MovementReceipt, Quantity: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if repo.moves != 1 {
+		t.Fatalf("stock was moved %d times, want 1", repo.moves)
+	}
+}
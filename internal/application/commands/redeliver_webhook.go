@@ -0,0 +1,38 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/webhook"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type RedeliverWebhookCommand struct {
+	ID uuid.UUID `params:"id"`
+}
+
+type RedeliverWebhookHandler struct {
+	repo webhook.Repository
+}
+
+func NewRedeliverWebhookHandler(repo webhook.Repository) *RedeliverWebhookHandler {
+	return &RedeliverWebhookHandler{repo: repo}
+}
+
+func (h *RedeliverWebhookHandler) Handle(ctx context.Context, cmd *RedeliverWebhookCommand) (*webhook.Delivery, error) {
+	delivery, err := h.repo.GetDelivery(ctx, cmd.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := delivery.Redeliver(); err != nil {
+		return nil, fiber.NewError(fiber.StatusConflict, err.Error())
+	}
+
+	if err := h.repo.UpdateDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+
+	return delivery, nil
+}
@@ -0,0 +1,25 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/product"
+	"github.com/google/uuid"
+)
+
+// ReleaseReservationCommand returns the reserved units to the available stock
+type ReleaseReservationCommand struct {
+	ID uuid.UUID `params:"id"`
+}
+
+type ReleaseReservationHandler struct {
+	repo product.ReservationRepository
+}
+
+func NewReleaseReservationHandler(repo product.ReservationRepository) *ReleaseReservationHandler {
+	return &ReleaseReservationHandler{repo: repo}
+}
+
+func (h *ReleaseReservationHandler) Handle(ctx context.Context, cmd *ReleaseReservationCommand) (*product.Reservation, error) {
+	return h.repo.ReleaseReservation(ctx, cmd.ID)
+}
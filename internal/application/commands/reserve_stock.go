@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/product"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// ReserveStockCommand holds units of a product for an order until they are
+// confirmed or released, or the reservation expires
+type ReserveStockCommand struct {
+	ProductID uuid.UUID `json:"product_id" params:"id"`
+	OrderID   string    `json:"order_id"`
+	Quantity  int       `json:"quantity"`
+	// TTLSeconds overrides the configured reservation TTL
+	TTLSeconds int `json:"ttl_seconds"`
+}
+
+type ReserveStockResponse struct {
+	*product.Reservation
+}
+
+func (ReserveStockResponse) StatusCode() int { return fiber.StatusCreated }
+
+type ReserveStockHandler struct {
+	repo product.ReservationRepository
+	cfg  config.InventoryConfig
+}
+
+func NewReserveStockHandler(repo product.ReservationRepository, cfg config.InventoryConfig) *ReserveStockHandler {
+	return &ReserveStockHandler{repo: repo, cfg: cfg}
+}
+
+func (h *ReserveStockHandler) Handle(ctx context.Context, cmd *ReserveStockCommand) (*ReserveStockResponse, error) {
+	ttl := h.cfg.ReservationTTL
+	if cmd.TTLSeconds != 0 {
+		ttl = time.Duration(cmd.TTLSeconds) * time.Second
+	}
+	if ttl <= 0 || ttl > h.cfg.MaxReservationTTL {
+		return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("ttl_seconds must be between 1 and %d", int(h.cfg.MaxReservationTTL.Seconds())))
+	}
+
+	reservation, err := h.repo.Reserve(ctx, cmd.ProductID, cmd.OrderID, cmd.Quantity, ttl)
+	if err != nil {
+		return nil, err
+	}
+	return &ReserveStockResponse{Reservation: reservation}, nil
+}
@@ -0,0 +1,38 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/apikey"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type RevokeAPIKeyCommand struct {
+	ID uuid.UUID `params:"id"`
+}
+
+type RevokeAPIKeyHandler struct {
+	repo apikey.Repository
+}
+
+func NewRevokeAPIKeyHandler(repo apikey.Repository) *RevokeAPIKeyHandler {
+	return &RevokeAPIKeyHandler{repo: repo}
+}
+
+func (h *RevokeAPIKeyHandler) Handle(ctx context.Context, cmd *RevokeAPIKeyCommand) (*apikey.APIKey, error) {
+	key, err := h.repo.GetByID(ctx, cmd.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := key.Revoke(); err != nil {
+		return nil, fiber.NewError(fiber.StatusConflict, err.Error())
+	}
+
+	if err := h.repo.Revoke(ctx, key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
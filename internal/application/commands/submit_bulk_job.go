@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/job"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/auth"
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxImportItems bounds the size of a single bulk import request
+const maxImportItems = 10000
+
+type BulkChangePriceCommand struct {
+	Selector job.ProductSelector `json:"selector"`
+	Mode     string              `json:"mode"` // "set", "percent", "delta"
+	Value    float64             `json:"value"`
+	Currency string              `json:"currency"`
+}
+
+type BulkChangeStatusCommand struct {
+	Selector job.ProductSelector `json:"selector"`
+	Action   string              `json:"action"` // "activate", "deactivate", "discontinue"
+}
+
+type BulkImportProductsCommand struct {
+	Items []job.ImportItem `json:"items"`
+}
+
+// SubmitJobResponse is returned when a bulk job has been queued
+type SubmitJobResponse struct {
+	JobID  string     `json:"job_id"`
+	Status job.Status `json:"status"`
+}
+
+func (SubmitJobResponse) StatusCode() int { return fiber.StatusAccepted }
+
+type BulkChangePriceHandler struct {
+	repo job.Repository
+}
+
+func NewBulkChangePriceHandler(repo job.Repository) *BulkChangePriceHandler {
+	return &BulkChangePriceHandler{repo: repo}
+}
+
+func (h *BulkChangePriceHandler) Handle(ctx context.Context, cmd *BulkChangePriceCommand) (*SubmitJobResponse, error) {
+	switch cmd.Mode {
+	case job.PriceModeSet:
+		if cmd.Value < 0 {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "price cannot be negative")
+		}
+	case job.PriceModePercent:
+		if cmd.Value <= -100 {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "percent change must be greater than -100")
+		}
+	case job.PriceModeDelta:
+	default:
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid price change mode")
+	}
+
+	return submitJob(ctx, h.repo, job.TypeBulkPriceChange, job.BulkPriceChangePayload{
+		Selector: cmd.Selector,
+		Mode:     cmd.Mode,
+		Value:    cmd.Value,
+		Currency: cmd.Currency,
+	})
+}
+
+type BulkChangeStatusHandler struct {
+	repo job.Repository
+}
+
+func NewBulkChangeStatusHandler(repo job.Repository) *BulkChangeStatusHandler {
+	return &BulkChangeStatusHandler{repo: repo}
+}
+
+func (h *BulkChangeStatusHandler) Handle(ctx context.Context, cmd *BulkChangeStatusCommand) (*SubmitJobResponse, error) {
+	switch cmd.Action {
+	case "activate", "deactivate", "discontinue":
+	default:
+		return nil, fiber.NewError(fiber.StatusBadRequest, "invalid status change action")
+	}
+
+	return submitJob(ctx, h.repo, job.TypeBulkStatusChange, job.BulkStatusChangePayload{
+		Selector: cmd.Selector,
+		Action:   cmd.Action,
+	})
+}
+
+type BulkImportProductsHandler struct {
+	repo job.Repository
+}
+
+func NewBulkImportProductsHandler(repo job.Repository) *BulkImportProductsHandler {
+	return &BulkImportProductsHandler{repo: repo}
+}
+
+func (h *BulkImportProductsHandler) Handle(ctx context.Context, cmd *BulkImportProductsCommand) (*SubmitJobResponse, error) {
+	if len(cmd.Items) == 0 {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "at least one item is required")
+	}
+	if len(cmd.Items) > maxImportItems {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "too many items in a single import")
+	}
+
+	return submitJob(ctx, h.repo, job.TypeBulkImport, job.BulkImportPayload{Items: cmd.Items})
+}
+
+func submitJob(ctx context.Context, repo job.Repository, jobType job.Type, payload any) (*SubmitJobResponse, error) {
+	newJob, err := job.NewJob(jobType, payload)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	// Executors attribute the per-product audit entries to the submitter
+	if claims, ok := auth.ClaimsFromContext(ctx); ok {
+		newJob.SubmittedBy = claims.Subject
+	}
+
+	if err := repo.Save(ctx, newJob); err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return &SubmitJobResponse{
+		JobID:  newJob.ID.String(),
+		Status: newJob.Status,
+	}, nil
+}
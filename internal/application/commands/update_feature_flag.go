@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/features"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/featureflag"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/auth"
+	"github.com/gofiber/fiber/v2"
+)
+
+// UpdateFeatureFlagCommand saves a flag, creating it if needed. Omitted
+// fields keep their current value, including one from the config file.
+type UpdateFeatureFlagCommand struct {
+	Key         string   `json:"key" params:"key"`
+	Description *string  `json:"description"`
+	Enabled     *bool    `json:"enabled"`
+	Rollout     *int     `json:"rollout"`
+	Tenants     []string `json:"tenants"`
+	Subjects    []string `json:"subjects"`
+}
+
+type UpdateFeatureFlagHandler struct {
+	flags *features.Service
+}
+
+func NewUpdateFeatureFlagHandler(flags *features.Service) *UpdateFeatureFlagHandler {
+	return &UpdateFeatureFlagHandler{flags: flags}
+}
+
+func (h *UpdateFeatureFlagHandler) Handle(ctx context.Context, cmd *UpdateFeatureFlagCommand) (*featureflag.Flag, error) {
+	flag, err := h.flags.Flag(ctx, cmd.Key)
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) && fiberErr.Code == fiber.StatusNotFound {
+		flag, err = featureflag.NewFlag(cmd.Key, featureflag.SourceDatabase)
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	description, enabled, rollout, tenants, subjects := flag.Description, flag.Enabled, flag.Rollout, flag.Tenants, flag.Subjects
+	if cmd.Description != nil {
+		description = *cmd.Description
+	}
+	if cmd.Enabled != nil {
+		enabled = *cmd.Enabled
+	}
+	if cmd.Rollout != nil {
+		rollout = *cmd.Rollout
+	}
+	if cmd.Tenants != nil {
+		tenants = cmd.Tenants
+	}
+	if cmd.Subjects != nil {
+		subjects = cmd.Subjects
+	}
+	if err := flag.Change(description, enabled, rollout, tenants, subjects); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	flag.Source = featureflag.SourceDatabase
+	flag.UpdatedBy = ""
+	if claims, ok := auth.ClaimsFromContext(ctx); ok {
+		flag.UpdatedBy = claims.Subject
+	}
+
+	if err := h.flags.Save(ctx, flag); err != nil {
+		return nil, err
+	}
+
+	return flag, nil
+}
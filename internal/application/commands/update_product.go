@@ -2,6 +2,7 @@ package commands
 
 import (
 	"context"
+	"errors"
 
 	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/product"
 	"github.com/google/uuid"
@@ -14,7 +15,7 @@ type UpdateProductCommand struct {
 	Description string    `json:"description"`
 	Price       float64   `json:"price"`
 	Currency    string    `json:"currency"`
-	StockLevel  int       `json:"stock_level"`
+	StockLevel  *int      `json:"stock_level"`
 	StockUnit   string    `json:"stock_unit"`
 	Version     int       `json:"version"`
 }
@@ -52,13 +53,22 @@ func (h *UpdateProductHandler) Handle(ctx context.Context, cmd *UpdateProductCom
 	}
 
 	// Update stock if provided
-	if cmd.StockLevel >= 0 {
+	if cmd.StockLevel != nil {
 		// Use domain logic to update stock
-		if err := existingProduct.UpdateStock(cmd.StockLevel); err != nil {
-			return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		if err := existingProduct.UpdateStock(*cmd.StockLevel); err != nil {
+			// Reserved units cannot be set away
+			if errors.Is(err, product.ErrInsufficientStock) {
+				return nil, fiber.NewError(fiber.StatusConflict, err.Error())
+			}
+			return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
 		}
 	}
 
+	// Nothing changed, e.g. the stock was set to its current level
+	if existingProduct.Version() == cmd.Version {
+		return existingProduct, nil
+	}
+
 	// Persist updated product
 	if err := h.repo.Update(ctx, existingProduct); err != nil {
 		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/product"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// memoryRepository keeps one product as the repository would store it
+type memoryRepository struct {
+	product.Repository
+	stored *product.Product
+}
+
+func (r *memoryRepository) GetByID(context.Context, uuid.UUID) (*product.Product, error) {
+	return r.stored, nil
+}
+
+func (r *memoryRepository) Update(_ context.Context, p *product.Product) error {
+	r.stored = p
+	return nil
+}
+
+func TestUpdateProductKeepsOmittedStock(t *testing.T) {
+	price, _ := product.NewPrice(10, "USD")
+	stock, _ := product.NewStock(7, "pcs")
+	p, err := product.NewProduct("Widget", "", price, stock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.ClearEvents()
+	p.ClearMovements()
+	repo := &memoryRepository{stored: p}
+
+	var cmd UpdateProductCommand
+	if err := json.Unmarshal([]byte(`{"price": 12.5, "currency": "USD"}`), &cmd); err != nil {
+		t.Fatal(err)
+	}
+	cmd.ID = p.ID()
+	cmd.Version = p.Version()
+
+	updated, err := NewUpdateProductHandler(repo).Handle(context.Background(), &cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Stock() != 7 {
+		t.Errorf("stock = %d, want 7", updated.Stock())
+	}
+	if m := updated.Movements(); len(m) != 0 {
+		t.Errorf("recorded movements %+v, want none", m)
+	}
+}
+
+func TestUpdateProductSetsGivenStock(t *testing.T) {
+	price, _ := product.NewPrice(10, "USD")
+	stock, _ := product.NewStock(7, "pcs")
+	p, err := product.NewProduct("Widget", "", price, stock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.ClearMovements()
+	repo := &memoryRepository{stored: p}
+
+	level := 0
+	updated, err := NewUpdateProductHandler(repo).Handle(context.Background(), &UpdateProductCommand{
+		ID: p.ID(), StockLevel: &level, Version: p.Version(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Stock() != 0 {
+		t.Errorf("stock = %d, want 0", updated.Stock())
+	}
+	m := updated.Movements()
+	if len(m) != 1 || m[0].Type != product.MovementAdjustment || m[0].Delta != -7 {
+		t.Errorf("movements = %+v, want one adjustment of -7", m)
+	}
+}
+
+func TestUpdateProductCannotSetStockBelowReserved(t *testing.T) {
+	price, _ := product.NewPrice(10, "USD")
+	stock, _ := product.NewStock(7, "pcs")
+	p, err := product.NewProduct("Widget", "", price, stock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.SetReservedStock(5)
+	repo := &memoryRepository{stored: p}
+
+	level := 2
+	_, err = NewUpdateProductHandler(repo).Handle(context.Background(), &UpdateProductCommand{
+		ID: p.ID(), StockLevel: &level, Version: p.Version(),
+	})
+	var fiberErr *fiber.Error
+	if !errors.As(err, &fiberErr) || fiberErr.Code != fiber.StatusConflict {
+		t.Fatalf("got %v, want 409", err)
+	}
+}
+
+func TestUpdateProductToCurrentStockChangesNothing(t *testing.T) {
+	price, _ := product.NewPrice(10, "USD")
+	stock, _ := product.NewStock(7, "pcs")
+	p, err := product.NewProduct("Widget", "", price, stock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.ClearEvents()
+	p.ClearMovements()
+	repo := &memoryRepository{stored: p}
+	version := p.Version()
+
+	level := 7
+	updated, err := NewUpdateProductHandler(repo).Handle(context.Background(), &UpdateProductCommand{
+		ID: p.ID(), StockLevel: &level, Version: version,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Version() != version || len(updated.Events()) != 0 {
+		t.Errorf("version %d with events %v, want %d with none", updated.Version(), updated.Events(), version)
+	}
+}
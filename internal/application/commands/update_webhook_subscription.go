@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/webhook"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type UpdateWebhookSubscriptionCommand struct {
+	ID          uuid.UUID `json:"id" params:"id"`
+	URL         string    `json:"url"`
+	Description string    `json:"description"`
+	EventTypes  []string  `json:"event_types"`
+	Active      *bool     `json:"active"`
+}
+
+type UpdateWebhookSubscriptionHandler struct {
+	repo webhook.Repository
+}
+
+func NewUpdateWebhookSubscriptionHandler(repo webhook.Repository) *UpdateWebhookSubscriptionHandler {
+	return &UpdateWebhookSubscriptionHandler{repo: repo}
+}
+
+func (h *UpdateWebhookSubscriptionHandler) Handle(ctx context.Context, cmd *UpdateWebhookSubscriptionCommand) (*webhook.Subscription, error) {
+	subscription, err := h.repo.GetSubscription(ctx, cmd.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	active := subscription.Active
+	if cmd.Active != nil {
+		active = *cmd.Active
+	}
+
+	if err := subscription.Change(cmd.URL, cmd.Description, cmd.EventTypes, active); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	if err := h.repo.UpdateSubscription(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
@@ -0,0 +1,233 @@
+package features
+
+import (
+	"context"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/featureflag"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/auth"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/tenant"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+var evaluations = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "feature_flag_evaluations_total",
+	Help: "Feature flag evaluations, by flag and result (on, off or unknown).",
+}, []string{"flag", "result"})
+
+// Service combines the flags of the config file with those saved through the
+// admin API, which take precedence. Saved flags are cached for the configured
+// refresh interval when evaluated; the admin methods always read them anew.
+type Service struct {
+	repo    featureflag.Repository
+	runtime *config.Runtime
+
+	// Evaluations read the cache without locking; one of them refreshes it
+	cache   atomic.Pointer[savedFlags]
+	refresh singleflight.Group
+	// generation is bumped by every change so a refresh that read the flags
+	// before it does not cache them as fresh
+	generation atomic.Uint64
+}
+
+type savedFlags struct {
+	flags    map[string]featureflag.Flag
+	loadedAt time.Time
+}
+
+func NewService(repo featureflag.Repository, runtime *config.Runtime) *Service {
+	return &Service{
+		repo:    repo,
+		runtime: runtime,
+	}
+}
+
+// FromConfig returns the flags defined in a config file
+func FromConfig(defs map[string]config.FeatureFlagConfig) map[string]featureflag.Flag {
+	flags := make(map[string]featureflag.Flag, len(defs))
+	for key, def := range defs {
+		rollout := 100
+		if def.Rollout != nil {
+			rollout = *def.Rollout
+		}
+		flag := featureflag.Flag{ID: featureflag.IDOf(key), Key: key, Source: featureflag.SourceConfig}
+		// The config is validated on load, so the rollout is in range
+		_ = flag.Change(def.Description, def.Enabled, rollout, def.Tenants, def.Subjects)
+		flags[key] = flag
+	}
+	return flags
+}
+
+// Enabled reports whether a flag is on for the tenant and subject of ctx. The
+// config file flags are those of the tenant, including its overrides. When
+// the saved flags cannot be loaded, the last loaded ones are used.
+func (s *Service) Enabled(ctx context.Context, key string) bool {
+	flag, ok := s.cached(ctx)[key]
+	if !ok {
+		flag, ok = FromConfig(tenant.Config(ctx, s.runtime.Config()).Features)[key]
+	}
+	if !ok {
+		evaluations.WithLabelValues(key, "unknown").Inc()
+		return false
+	}
+
+	var tenantID, subject string
+	if t, ok := tenant.FromContext(ctx); ok {
+		tenantID = t.ID
+	}
+	if claims, ok := auth.ClaimsFromContext(ctx); ok {
+		subject = claims.Subject
+	}
+
+	on := flag.Evaluate(tenantID, subject)
+	result := "off"
+	if on {
+		result = "on"
+	}
+	evaluations.WithLabelValues(key, result).Inc()
+	return on
+}
+
+// Flags returns every flag, sorted by key. Like Enabled, the config file
+// flags are those of the caller's tenant, including its overrides.
+func (s *Service) Flags(ctx context.Context) ([]featureflag.Flag, error) {
+	saved, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := FromConfig(tenant.Config(ctx, s.runtime.Config()).Features)
+	for key, flag := range saved {
+		merged[key] = flag
+	}
+	flags := make([]featureflag.Flag, 0, len(merged))
+	for _, flag := range merged {
+		flags = append(flags, flag)
+	}
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Key < flags[j].Key })
+	return flags, nil
+}
+
+// Flag returns the effective definition of a flag
+func (s *Service) Flag(ctx context.Context, key string) (*featureflag.Flag, error) {
+	return s.find(ctx, func(f *featureflag.Flag) bool { return f.Key == key })
+}
+
+// FlagByID returns the effective definition of a flag, for audit snapshots
+func (s *Service) FlagByID(ctx context.Context, id uuid.UUID) (*featureflag.Flag, error) {
+	return s.find(ctx, func(f *featureflag.Flag) bool { return f.ID == id })
+}
+
+// Save stores a flag, replacing its config file definition
+func (s *Service) Save(ctx context.Context, flag *featureflag.Flag) error {
+	if err := s.repo.Save(ctx, flag); err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+// Delete removes a saved flag, restoring its config file definition if any
+func (s *Service) Delete(ctx context.Context, key string) error {
+	if err := s.repo.Delete(ctx, key); err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+func (s *Service) find(ctx context.Context, match func(*featureflag.Flag) bool) (*featureflag.Flag, error) {
+	flags, err := s.Flags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range flags {
+		if match(&flags[i]) {
+			return &flags[i], nil
+		}
+	}
+	return nil, fiber.NewError(fiber.StatusNotFound, featureflag.ErrNotFound.Error())
+}
+
+// load reads the saved flags and refreshes the cache with them
+func (s *Service) load(ctx context.Context) (map[string]featureflag.Flag, error) {
+	generation := s.generation.Load()
+	list, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	saved := byKey(list)
+	s.store(saved, generation)
+	return saved, nil
+}
+
+// cached returns the saved flags, refreshing them once the interval passed.
+// Only the first evaluation waits for them to load; later ones get the
+// previous flags while a single refresh runs.
+func (s *Service) cached(ctx context.Context) map[string]featureflag.Flag {
+	current := s.cache.Load()
+	if current != nil && time.Since(current.loadedAt) < s.runtime.Config().FeatureFlags.RefreshInterval {
+		return current.flags
+	}
+
+	done := s.refresh.DoChan("flags", func() (interface{}, error) {
+		generation := s.generation.Load()
+		list, err := s.repo.List(context.WithoutCancel(ctx))
+		if err != nil {
+			zap.L().Warn("Failed to load feature flags, using the last loaded ones", zap.Error(err))
+			// Retried after the interval rather than on every evaluation
+			var last map[string]featureflag.Flag
+			if current := s.cache.Load(); current != nil {
+				last = current.flags
+			}
+			s.cache.Store(&savedFlags{flags: last, loadedAt: time.Now()})
+			return last, nil
+		}
+		saved := byKey(list)
+		s.store(saved, generation)
+		return saved, nil
+	})
+	if current != nil {
+		return current.flags
+	}
+	select {
+	case res := <-done:
+		flags, _ := res.Val.(map[string]featureflag.Flag)
+		return flags
+	case <-ctx.Done():
+		return nil
+	}
+}
+
+// store caches flags read at the given generation, as stale if a change
+// happened since
+func (s *Service) store(flags map[string]featureflag.Flag, generation uint64) {
+	loadedAt := time.Now()
+	if s.generation.Load() != generation {
+		loadedAt = time.Time{}
+	}
+	s.cache.Store(&savedFlags{flags: flags, loadedAt: loadedAt})
+}
+
+func byKey(list []featureflag.Flag) map[string]featureflag.Flag {
+	flags := make(map[string]featureflag.Flag, len(list))
+	for _, flag := range list {
+		flags[flag.Key] = flag
+	}
+	return flags
+}
+
+func (s *Service) invalidate() {
+	s.generation.Add(1)
+	if current := s.cache.Load(); current != nil {
+		s.cache.Store(&savedFlags{flags: current.flags})
+	}
+}
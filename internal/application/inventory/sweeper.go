@@ -0,0 +1,121 @@
+package inventory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/product"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+var expired = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "stock_reservations_expired_total",
+	Help: "Stock reservations expired by the sweeper.",
+})
+
+// Sweeper expires stock reservations whose TTL has passed, returning their
+// units to the available stock. Every replica may run one; a reservation is
+// only ever expired once.
+type Sweeper struct {
+	repo product.ReservationRepository
+	cfg  config.InventoryConfig
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewSweeper(repo product.ReservationRepository, cfg config.InventoryConfig) *Sweeper {
+	return &Sweeper{
+		repo: repo,
+		cfg:  cfg,
+	}
+}
+
+// Start launches the sweep loop. It runs until Stop is called or ctx is done.
+func (s *Sweeper) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.wg.Add(1)
+	go s.run(ctx)
+	zap.L().Info("Reservation sweeper started", zap.Duration("interval", s.cfg.SweepInterval))
+}
+
+// Stop waits for the current sweep to finish
+func (s *Sweeper) Stop(ctx context.Context) error {
+	if s.cancel == nil {
+		return nil
+	}
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		zap.L().Info("Reservation sweeper stopped")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Sweeper) run(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.cfg.SweepInterval)
+	defer ticker.Stop()
+
+	for {
+		s.sweep(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sweep expires due reservations batch by batch until none are left
+func (s *Sweeper) sweep(ctx context.Context) {
+	for ctx.Err() == nil {
+		// Reservations failing to expire are logged and retried next sweep
+		n, err := s.sweepBatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			zap.L().Error("Failed to expire stock reservations", zap.Error(err))
+		}
+		if n < s.cfg.SweepBatchSize {
+			return
+		}
+	}
+}
+
+func (s *Sweeper) sweepBatch(ctx context.Context) (int, error) {
+	tracer := otel.GetTracerProvider().Tracer("")
+	ctx, span := tracer.Start(ctx, "expire stock reservations")
+	defer span.End()
+
+	reservations, err := s.repo.ExpireReservations(ctx, s.cfg.SweepBatchSize)
+	for _, r := range reservations {
+		expired.Inc()
+		zap.L().Info("Stock reservation expired",
+			zap.String("reservation_id", r.ID.String()),
+			zap.String("product_id", r.ProductID.String()),
+			zap.String("order_id", r.OrderID),
+			zap.Int("quantity", r.Quantity),
+		)
+	}
+	span.SetAttributes(attribute.Int("reservations.expired", len(reservations)))
+	if err != nil {
+		span.RecordError(err)
+	}
+	return len(reservations), err
+}
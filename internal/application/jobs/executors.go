@@ -0,0 +1,226 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/auditing"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/commands"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/audit"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/job"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/product"
+	"github.com/google/uuid"
+)
+
+// Executor processes one batch of a job, starting at the job's cursor.
+// It advances the cursor and progress counters and reports whether the job
+// has no more work. Per-item failures are recorded on the job; a returned
+// error means the batch itself could not run.
+type Executor interface {
+	RunBatch(ctx context.Context, j *job.Job, batchSize int) (done bool, err error)
+}
+
+// NewExecutors returns the executors for all bulk job types. Price and
+// status changes record an audit entry per product, attributed to the job's
+// submitter; imports are audited as the job itself.
+func NewExecutors(writeRepo product.Repository, readRepo product.ReadOnlyRepository, auditLog *auditing.Log) map[job.Type]Executor {
+	return map[job.Type]Executor{
+		job.TypeBulkPriceChange:  &priceChangeExecutor{writeRepo: writeRepo, readRepo: readRepo, audit: auditLog},
+		job.TypeBulkStatusChange: &statusChangeExecutor{writeRepo: writeRepo, readRepo: readRepo, audit: auditLog},
+		job.TypeBulkImport:       &importExecutor{create: commands.NewCreateProductHandler(writeRepo)},
+	}
+}
+
+type priceChangeExecutor struct {
+	writeRepo product.Repository
+	readRepo  product.ReadOnlyRepository
+	audit     *auditing.Log
+}
+
+func (e *priceChangeExecutor) RunBatch(ctx context.Context, j *job.Job, batchSize int) (bool, error) {
+	var payload job.BulkPriceChangePayload
+	if err := j.DecodePayload(&payload); err != nil {
+		return false, err
+	}
+
+	return forEachSelected(ctx, e.readRepo, j, payload.Selector, batchSize, func(ctx context.Context, id uuid.UUID) error {
+		p, err := e.writeRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		before := product.NewProductReadModel(p)
+
+		amount, err := newPriceAmount(p.Price(), payload.Mode, payload.Value)
+		if err != nil {
+			return err
+		}
+
+		currency := payload.Currency
+		if currency == "" {
+			currency = p.Currency()
+		}
+
+		price, err := product.NewPrice(amount, currency)
+		if err != nil {
+			return err
+		}
+		if err := p.UpdatePrice(price); err != nil {
+			return err
+		}
+		return updateAudited(ctx, e.writeRepo, e.audit, j, reflect.TypeFor[commands.BulkChangePriceCommand](), before, p)
+	})
+}
+
+type statusChangeExecutor struct {
+	writeRepo product.Repository
+	readRepo  product.ReadOnlyRepository
+	audit     *auditing.Log
+}
+
+func (e *statusChangeExecutor) RunBatch(ctx context.Context, j *job.Job, batchSize int) (bool, error) {
+	var payload job.BulkStatusChangePayload
+	if err := j.DecodePayload(&payload); err != nil {
+		return false, err
+	}
+
+	return forEachSelected(ctx, e.readRepo, j, payload.Selector, batchSize, func(ctx context.Context, id uuid.UUID) error {
+		p, err := e.writeRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		before := product.NewProductReadModel(p)
+
+		var actionErr error
+		switch payload.Action {
+		case "activate":
+			actionErr = p.Activate()
+		case "deactivate":
+			actionErr = p.Deactivate()
+		case "discontinue":
+			actionErr = p.Discontinue()
+		default:
+			actionErr = errors.New("invalid status change action")
+		}
+		if actionErr != nil {
+			return actionErr
+		}
+		return updateAudited(ctx, e.writeRepo, e.audit, j, reflect.TypeFor[commands.BulkChangeStatusCommand](), before, p)
+	})
+}
+
+// updateAudited saves a product changed by a job and records the change on
+// behalf of the job's submitter. The update only succeeds when the product
+// is still at the version it was loaded at, so before and after are exactly
+// the states it replaced and wrote.
+func updateAudited(ctx context.Context, repo product.Repository, auditLog *auditing.Log, j *job.Job, command reflect.Type, before product.ProductReadModel, p *product.Product) error {
+	if err := repo.Update(ctx, p); err != nil {
+		return err
+	}
+	auditLog.Record(ctx, j.SubmittedBy, command.Name(), audit.AggregateProduct, p.ID(), before, product.NewProductReadModel(p))
+	return nil
+}
+
+type importExecutor struct {
+	create *commands.CreateProductHandler
+}
+
+func (e *importExecutor) RunBatch(ctx context.Context, j *job.Job, batchSize int) (bool, error) {
+	var payload job.BulkImportPayload
+	if err := j.DecodePayload(&payload); err != nil {
+		return false, err
+	}
+
+	j.Total = len(payload.Items)
+
+	// For imports the cursor is the index of the next item to process
+	next := 0
+	if j.Cursor != "" {
+		var err error
+		if next, err = strconv.Atoi(j.Cursor); err != nil {
+			return false, fmt.Errorf("invalid import cursor %q: %w", j.Cursor, err)
+		}
+	}
+
+	end := min(next+batchSize, len(payload.Items))
+	for i := next; i < end; i++ {
+		item := payload.Items[i]
+		_, err := e.create.Handle(ctx, &commands.CreateProductCommand{
+			Name:        item.Name,
+			Description: item.Description,
+			Price:       item.Price,
+			Currency:    item.Currency,
+			StockLevel:  item.StockLevel,
+			StockUnit:   item.StockUnit,
+		})
+		if err != nil {
+			j.RecordFailure(strconv.Itoa(i), err)
+		} else {
+			j.RecordSuccess()
+		}
+		j.Cursor = strconv.Itoa(i + 1)
+	}
+
+	return end >= len(payload.Items), nil
+}
+
+// forEachSelected applies fn to the next batch of products matching the
+// selector, in ID order, using the last processed ID as the cursor.
+func forEachSelected(
+	ctx context.Context,
+	readRepo product.ReadOnlyRepository,
+	j *job.Job,
+	selector job.ProductSelector,
+	batchSize int,
+	fn func(ctx context.Context, id uuid.UUID) error,
+) (bool, error) {
+	filter := selector.Filter()
+
+	after := uuid.Nil
+	if j.Cursor != "" {
+		var err error
+		if after, err = uuid.Parse(j.Cursor); err != nil {
+			return false, fmt.Errorf("invalid job cursor %q: %w", j.Cursor, err)
+		}
+	} else if j.Processed == 0 {
+		total, err := readRepo.Count(ctx, filter)
+		if err != nil {
+			return false, err
+		}
+		j.Total = int(total)
+	}
+
+	ids, err := readRepo.FindIDs(ctx, filter, after, batchSize)
+	if err != nil {
+		return false, err
+	}
+
+	for _, id := range ids {
+		if err := fn(ctx, id); err != nil {
+			j.RecordFailure(id.String(), err)
+		} else {
+			j.RecordSuccess()
+		}
+		j.Cursor = id.String()
+	}
+
+	return len(ids) < batchSize, nil
+}
+
+func newPriceAmount(current float64, mode string, value float64) (float64, error) {
+	var amount float64
+	switch mode {
+	case job.PriceModeSet:
+		amount = value
+	case job.PriceModePercent:
+		amount = current * (1 + value/100)
+	case job.PriceModeDelta:
+		amount = current + value
+	default:
+		return 0, fmt.Errorf("invalid price mode %q", mode)
+	}
+	return math.Round(amount*100) / 100, nil
+}
@@ -0,0 +1,181 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/job"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/logger"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/tenant"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// Runner executes queued jobs on a pool of background workers.
+//
+// Workers claim jobs through a lease that is renewed after every batch. A job
+// whose worker disappears (crash, restart) becomes claimable again once its
+// lease expires and continues from its last saved cursor. A batch that was in
+// flight when the process died may be applied a second time.
+type Runner struct {
+	repo      job.Repository
+	executors map[job.Type]Executor
+	cfg       config.JobsConfig
+	workerID  string
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewRunner(repo job.Repository, executors map[job.Type]Executor, cfg config.JobsConfig) *Runner {
+	host, _ := os.Hostname()
+	return &Runner{
+		repo:      repo,
+		executors: executors,
+		cfg:       cfg,
+		workerID:  fmt.Sprintf("%s-%s", host, uuid.NewString()[:8]),
+	}
+}
+
+// Start launches the workers. They run until Stop is called or ctx is done.
+func (r *Runner) Start(ctx context.Context) {
+	ctx, r.cancel = context.WithCancel(ctx)
+	for i := 0; i < r.cfg.Workers; i++ {
+		r.wg.Add(1)
+		go r.work(ctx, fmt.Sprintf("%s/%d", r.workerID, i))
+	}
+	zap.L().Info("Job runner started", zap.String("worker_id", r.workerID), zap.Int("workers", r.cfg.Workers))
+}
+
+// Stop asks the workers to stop after their current batch and waits for them.
+// Jobs that were interrupted are released back to the queue.
+func (r *Runner) Stop(ctx context.Context) error {
+	if r.cancel == nil {
+		return nil
+	}
+	r.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		zap.L().Info("Job runner stopped", zap.String("worker_id", r.workerID))
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *Runner) work(ctx context.Context, workerID string) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Drain the queue before waiting for the next tick
+		for ctx.Err() == nil {
+			j, err := r.repo.ClaimNext(ctx, workerID, r.lease())
+			if err != nil {
+				if ctx.Err() == nil {
+					zap.L().Error("Failed to claim job", zap.String("worker_id", workerID), zap.Error(err))
+				}
+				break
+			}
+			if j == nil {
+				break
+			}
+			r.process(ctx, j)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Runner) process(ctx context.Context, j *job.Job) {
+	// Batches run on a context that survives shutdown so that a batch is
+	// never cut in half; shutdown is only observed between batches.
+	batchCtx := context.WithoutCancel(ctx)
+	// Executors act for the tenant that queued the job
+	batchCtx = tenant.WithTenant(batchCtx, &tenant.Tenant{ID: j.TenantID})
+
+	tracer := otel.GetTracerProvider().Tracer("")
+	batchCtx, span := tracer.Start(batchCtx, "job "+string(j.Type))
+	span.SetAttributes(attribute.String("job.id", j.ID.String()), attribute.String("tenant.id", j.TenantID), attribute.Int("job.attempt", j.Attempts))
+	defer span.End()
+
+	fields := append(logger.GetTraceFields(batchCtx), zap.String("job_id", j.ID.String()), zap.String("job_type", string(j.Type)))
+	zap.L().Info("Job claimed", append(fields, zap.Int("attempt", j.Attempts), zap.Int("processed", j.Processed))...)
+
+	executor, ok := r.executors[j.Type]
+	switch {
+	case !ok:
+		j.Fail(fmt.Sprintf("unsupported job type %q", j.Type))
+	case j.Attempts > r.cfg.MaxAttempts:
+		j.Fail(fmt.Sprintf("giving up after %d attempts", r.cfg.MaxAttempts))
+	}
+
+	for !j.IsFinished() {
+		if j.CancelRequested {
+			j.Cancel()
+			break
+		}
+
+		if ctx.Err() != nil {
+			// Shutting down: hand the job back so it resumes on the next start
+			if err := r.repo.Release(batchCtx, j); err != nil {
+				zap.L().Error("Failed to release job", append(fields, zap.Error(err))...)
+			}
+			zap.L().Info("Job released", append(fields, zap.Int("processed", j.Processed))...)
+			return
+		}
+
+		done, err := executor.RunBatch(batchCtx, j, r.cfg.BatchSize)
+		if err != nil {
+			// Keep the lease; the job is retried once it expires
+			span.RecordError(err)
+			zap.L().Error("Job batch failed", append(fields, zap.Error(err))...)
+			return
+		}
+		if done {
+			j.Complete()
+			break
+		}
+
+		if err := r.repo.SaveProgress(batchCtx, j, r.lease()); err != nil {
+			span.RecordError(err)
+			zap.L().Error("Failed to save job progress", append(fields, zap.Error(err))...)
+			return
+		}
+	}
+
+	if err := r.repo.SaveProgress(batchCtx, j, r.lease()); err != nil {
+		span.RecordError(err)
+		zap.L().Error("Failed to save job result", append(fields, zap.Error(err))...)
+		return
+	}
+
+	zap.L().Info("Job finished", append(fields,
+		zap.String("status", string(j.Status)),
+		zap.Int("succeeded", j.Succeeded),
+		zap.Int("failed", j.Failed),
+	)...)
+}
+
+func (r *Runner) lease() time.Duration {
+	return r.cfg.LeaseTimeout
+}
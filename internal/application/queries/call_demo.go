@@ -0,0 +1,47 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/client"
+	"github.com/gofiber/fiber/v2"
+)
+
+type CallDemoQuery struct {
+	// Scenario is the demo endpoint to call: test, error or timeout
+	Scenario string `params:"scenario"`
+}
+
+type CallDemoResponse struct {
+	Scenario string `json:"scenario"`
+}
+
+// CallDemoHandler calls the demo downstream, whose endpoints succeed, fail
+// or time out on purpose, to exercise its resilience policy. Failed calls
+// respond with 502, or 504 when the downstream timed out.
+type CallDemoHandler struct {
+	demo *client.Demo
+}
+
+func NewCallDemoHandler(demo *client.Demo) *CallDemoHandler {
+	return &CallDemoHandler{demo: demo}
+}
+
+func (h *CallDemoHandler) Handle(ctx context.Context, query *CallDemoQuery) (*CallDemoResponse, error) {
+	var call func(context.Context) error
+	switch query.Scenario {
+	case "test":
+		call = h.demo.Test
+	case "error":
+		call = h.demo.Error
+	case "timeout":
+		call = h.demo.Timeout
+	default:
+		return nil, fiber.NewError(fiber.StatusBadRequest, "scenario must be test, error or timeout")
+	}
+
+	if err := call(ctx); err != nil {
+		return nil, client.HTTPError(err)
+	}
+	return &CallDemoResponse{Scenario: query.Scenario}, nil
+}
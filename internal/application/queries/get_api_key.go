@@ -0,0 +1,24 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/apikey"
+	"github.com/google/uuid"
+)
+
+type GetAPIKeyQuery struct {
+	ID uuid.UUID `params:"id"`
+}
+
+type GetAPIKeyHandler struct {
+	repo apikey.Repository
+}
+
+func NewGetAPIKeyHandler(repo apikey.Repository) *GetAPIKeyHandler {
+	return &GetAPIKeyHandler{repo: repo}
+}
+
+func (h *GetAPIKeyHandler) Handle(ctx context.Context, query *GetAPIKeyQuery) (*apikey.APIKey, error) {
+	return h.repo.GetByID(ctx, query.ID)
+}
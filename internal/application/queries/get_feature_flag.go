@@ -0,0 +1,38 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/features"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/featureflag"
+)
+
+type GetFeatureFlagQuery struct {
+	Key string `params:"key"`
+}
+
+type GetFeatureFlagResponse struct {
+	*featureflag.Flag
+	// On tells whether the flag is on for the caller, to check targeting
+	On bool `json:"on"`
+}
+
+type GetFeatureFlagHandler struct {
+	flags *features.Service
+}
+
+func NewGetFeatureFlagHandler(flags *features.Service) *GetFeatureFlagHandler {
+	return &GetFeatureFlagHandler{flags: flags}
+}
+
+func (h *GetFeatureFlagHandler) Handle(ctx context.Context, query *GetFeatureFlagQuery) (*GetFeatureFlagResponse, error) {
+	flag, err := h.flags.Flag(ctx, query.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GetFeatureFlagResponse{
+		Flag: flag,
+		On:   h.flags.Enabled(ctx, query.Key),
+	}, nil
+}
@@ -0,0 +1,24 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/health"
+)
+
+type GetHealthQuery struct{}
+
+// GetHealthHandler breaks readiness down per check. /readyz only reports the
+// overall status, as the checks name downstream hosts and database errors.
+type GetHealthHandler struct {
+	checks *health.Registry
+}
+
+func NewGetHealthHandler(checks *health.Registry) *GetHealthHandler {
+	return &GetHealthHandler{checks: checks}
+}
+
+func (h *GetHealthHandler) Handle(ctx context.Context, _ *GetHealthQuery) (*health.Report, error) {
+	report := h.checks.Ready(ctx)
+	return &report, nil
+}
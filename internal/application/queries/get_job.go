@@ -0,0 +1,24 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/job"
+	"github.com/google/uuid"
+)
+
+type GetJobQuery struct {
+	ID uuid.UUID `params:"id"`
+}
+
+type GetJobHandler struct {
+	repo job.Repository
+}
+
+func NewGetJobHandler(repo job.Repository) *GetJobHandler {
+	return &GetJobHandler{repo: repo}
+}
+
+func (h *GetJobHandler) Handle(ctx context.Context, query *GetJobQuery) (*job.Job, error) {
+	return h.repo.GetByID(ctx, query.ID)
+}
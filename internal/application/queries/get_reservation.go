@@ -0,0 +1,24 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/product"
+	"github.com/google/uuid"
+)
+
+type GetReservationQuery struct {
+	ID uuid.UUID `params:"id"`
+}
+
+type GetReservationHandler struct {
+	repo product.ReservationRepository
+}
+
+func NewGetReservationHandler(repo product.ReservationRepository) *GetReservationHandler {
+	return &GetReservationHandler{repo: repo}
+}
+
+func (h *GetReservationHandler) Handle(ctx context.Context, query *GetReservationQuery) (*product.Reservation, error) {
+	return h.repo.GetReservation(ctx, query.ID)
+}
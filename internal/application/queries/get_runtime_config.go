@@ -0,0 +1,151 @@
+package queries
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/features"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/tenant"
+)
+
+type GetRuntimeConfigQuery struct{}
+
+type SamplingRuleSettings struct {
+	Route string  `json:"route"`
+	Ratio float64 `json:"ratio"`
+}
+
+type SamplerSettings struct {
+	Ratio       float64                `json:"ratio"`
+	ParentBased bool                   `json:"parent_based"`
+	Rules       []SamplingRuleSettings `json:"rules"`
+}
+
+type RateLimitRuleSettings struct {
+	Name    string   `json:"name"`
+	Methods []string `json:"methods,omitempty"`
+	Path    string   `json:"path,omitempty"`
+	Rate    float64  `json:"rate"`
+	Burst   int      `json:"burst"`
+}
+
+type RateLimitSettings struct {
+	Enabled bool                    `json:"enabled"`
+	PerIP   RateLimitRuleSettings   `json:"per_ip"`
+	Default RateLimitRuleSettings   `json:"default"`
+	Rules   []RateLimitRuleSettings `json:"rules"`
+	Tiers   map[string]float64      `json:"tiers"`
+}
+
+// FeatureSettings is a config file flag without its targeting, as the
+// targeted tenants and subjects are not the caller's business
+type FeatureSettings struct {
+	Key         string `json:"key"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+	Rollout     int    `json:"rollout"`
+}
+
+type CircuitBreakerSettings struct {
+	RequestsVolumeThreshold uint32  `json:"requests_volume_threshold"`
+	FailureThreshold        float64 `json:"failure_threshold"`
+}
+
+// RuntimeSettings are the settings reloaded without a restart
+type RuntimeSettings struct {
+	LogLevel       string                 `json:"log_level"`
+	Sampler        SamplerSettings        `json:"sampler"`
+	RateLimit      RateLimitSettings      `json:"rate_limit"`
+	Features       []FeatureSettings      `json:"features"`
+	CircuitBreaker CircuitBreakerSettings `json:"circuit_breaker"`
+}
+
+type GetRuntimeConfigResponse struct {
+	Settings RuntimeSettings `json:"settings"`
+	// TenantRateLimits are the rate limits of the caller's tenant, if it has overrides
+	TenantRateLimits map[string]RateLimitSettings `json:"tenant_rate_limits,omitempty"`
+	Reloadable       []string                     `json:"reloadable"`
+	LoadedAt         time.Time                    `json:"loaded_at"`
+	ReloadedAt       *time.Time                   `json:"reloaded_at,omitempty"`
+	Reloads          int                          `json:"reloads"`
+	// LastError explains why the latest change to the config file was rejected
+	LastError string `json:"last_error,omitempty"`
+}
+
+type GetRuntimeConfigHandler struct {
+	runtime *config.Runtime
+}
+
+func NewGetRuntimeConfigHandler(runtime *config.Runtime) *GetRuntimeConfigHandler {
+	return &GetRuntimeConfigHandler{runtime: runtime}
+}
+
+// Handle returns the active reloadable settings and when they were last reloaded
+func (h *GetRuntimeConfigHandler) Handle(ctx context.Context, query *GetRuntimeConfigQuery) (*GetRuntimeConfigResponse, error) {
+	status := h.runtime.Status()
+	cfg := status.Config
+
+	res := &GetRuntimeConfigResponse{
+		Settings: RuntimeSettings{
+			LogLevel:  cfg.Log.Level,
+			Sampler:   samplerSettings(cfg.Tracing.Sampler),
+			RateLimit: rateLimitSettings(cfg.RateLimit),
+			Features:  configFlags(cfg.Features),
+			CircuitBreaker: CircuitBreakerSettings{
+				RequestsVolumeThreshold: cfg.CircuitBreaker.RequestsVolumeThreshold,
+				FailureThreshold:        cfg.CircuitBreaker.FailureThreshold,
+			},
+		},
+		Reloadable: config.Reloadable,
+		LoadedAt:   status.LoadedAt,
+		Reloads:    status.Reloads,
+		LastError:  status.LastError,
+	}
+	if !status.ReloadedAt.IsZero() {
+		res.ReloadedAt = &status.ReloadedAt
+	}
+	// Other tenants' overrides are not the caller's business
+	if t, ok := tenant.FromContext(ctx); ok {
+		if _, overridden := cfg.Tenancy.Tenants[t.ID]; overridden {
+			res.TenantRateLimits = map[string]RateLimitSettings{t.ID: rateLimitSettings(cfg.ForTenant(t.ID).RateLimit)}
+		}
+	}
+	return res, nil
+}
+
+func configFlags(defs map[string]config.FeatureFlagConfig) []FeatureSettings {
+	flags := []FeatureSettings{}
+	for _, flag := range features.FromConfig(defs) {
+		flags = append(flags, FeatureSettings{Key: flag.Key, Description: flag.Description, Enabled: flag.Enabled, Rollout: flag.Rollout})
+	}
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Key < flags[j].Key })
+	return flags
+}
+
+func samplerSettings(cfg config.SamplerConfig) SamplerSettings {
+	settings := SamplerSettings{Ratio: cfg.Ratio, ParentBased: cfg.ParentBased, Rules: []SamplingRuleSettings{}}
+	for _, rule := range cfg.Rules {
+		settings.Rules = append(settings.Rules, SamplingRuleSettings{Route: rule.Route, Ratio: rule.Ratio})
+	}
+	return settings
+}
+
+func rateLimitSettings(cfg config.RateLimitConfig) RateLimitSettings {
+	settings := RateLimitSettings{
+		Enabled: cfg.Enabled,
+		PerIP:   rateLimitRuleSettings(cfg.PerIP),
+		Default: rateLimitRuleSettings(cfg.Default),
+		Rules:   []RateLimitRuleSettings{},
+		Tiers:   cfg.Tiers,
+	}
+	for _, rule := range cfg.Rules {
+		settings.Rules = append(settings.Rules, rateLimitRuleSettings(rule))
+	}
+	return settings
+}
+
+func rateLimitRuleSettings(rule config.RateLimitRule) RateLimitRuleSettings {
+	return RateLimitRuleSettings{Name: rule.Name, Methods: rule.Methods, Path: rule.Path, Rate: rule.Rate, Burst: rule.Burst}
+}
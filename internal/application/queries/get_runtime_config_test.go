@@ -0,0 +1,43 @@
+package queries
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/tenant"
+)
+
+func TestRuntimeConfigHidesOtherTenants(t *testing.T) {
+	cfg := &config.Config{
+		Features: map[string]config.FeatureFlagConfig{
+			"beta": {Enabled: true, Tenants: []string{"globex"}, Subjects: []string{"globex-user"}},
+		},
+		Tenancy: config.TenancyConfig{
+			Tenants: map[string]map[string]interface{}{"acme": {}, "globex": {}},
+		},
+	}
+	handler := NewGetRuntimeConfigHandler(config.NewRuntime(cfg))
+
+	ctx := tenant.WithTenant(context.Background(), &tenant.Tenant{ID: "acme", Config: cfg})
+	res, err := handler.Handle(ctx, &GetRuntimeConfigQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "globex") {
+		t.Errorf("response names another tenant: %s", data)
+	}
+	if _, ok := res.TenantRateLimits["acme"]; !ok {
+		t.Errorf("response lacks the caller's rate limits: %s", data)
+	}
+	if len(res.Settings.Features) != 1 || !res.Settings.Features[0].Enabled {
+		t.Errorf("got features %+v, want beta enabled", res.Settings.Features)
+	}
+}
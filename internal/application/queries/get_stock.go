@@ -0,0 +1,57 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/product"
+	"github.com/google/uuid"
+)
+
+type GetStockQuery struct {
+	ID uuid.UUID `params:"id"`
+}
+
+// GetStockResponse reconciles the stock level of a product with its ledger
+type GetStockResponse struct {
+	ProductID      uuid.UUID `json:"product_id"`
+	StockLevel     int       `json:"stock_level"`
+	ReservedStock  int       `json:"reserved_stock"`
+	AvailableStock int       `json:"available_stock"`
+	// LedgerBalance is the sum of the product's movements. It is null until
+	// the first stock change of a product stocked before the ledger existed.
+	LedgerBalance *int `json:"ledger_balance"`
+	// Reconciled is false when the stock level differs from the ledger balance
+	Reconciled bool `json:"reconciled"`
+}
+
+type GetStockHandler struct {
+	products product.ReadOnlyRepository
+	ledger   product.MovementRepository
+}
+
+func NewGetStockHandler(products product.ReadOnlyRepository, ledger product.MovementRepository) *GetStockHandler {
+	return &GetStockHandler{products: products, ledger: ledger}
+}
+
+func (h *GetStockHandler) Handle(ctx context.Context, query *GetStockQuery) (*GetStockResponse, error) {
+	p, err := h.products.FindByID(ctx, query.ID)
+	if err != nil {
+		return nil, err
+	}
+	balance, tracked, err := h.ledger.LedgerBalance(ctx, query.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &GetStockResponse{
+		ProductID:      p.ID,
+		StockLevel:     p.StockLevel,
+		ReservedStock:  p.ReservedStock,
+		AvailableStock: p.AvailableStock,
+		Reconciled:     !tracked || balance == p.StockLevel,
+	}
+	if tracked {
+		res.LedgerBalance = &balance
+	}
+	return res, nil
+}
@@ -0,0 +1,24 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/webhook"
+	"github.com/google/uuid"
+)
+
+type GetWebhookSubscriptionQuery struct {
+	ID uuid.UUID `params:"id"`
+}
+
+type GetWebhookSubscriptionHandler struct {
+	repo webhook.Repository
+}
+
+func NewGetWebhookSubscriptionHandler(repo webhook.Repository) *GetWebhookSubscriptionHandler {
+	return &GetWebhookSubscriptionHandler{repo: repo}
+}
+
+func (h *GetWebhookSubscriptionHandler) Handle(ctx context.Context, query *GetWebhookSubscriptionQuery) (*webhook.Subscription, error) {
+	return h.repo.GetSubscription(ctx, query.ID)
+}
@@ -0,0 +1,34 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/apikey"
+)
+
+type ListAPIKeysQuery struct{}
+
+type ListAPIKeysResponse struct {
+	APIKeys []apikey.APIKey `json:"api_keys"`
+	Total   int             `json:"total"`
+}
+
+type ListAPIKeysHandler struct {
+	repo apikey.Repository
+}
+
+func NewListAPIKeysHandler(repo apikey.Repository) *ListAPIKeysHandler {
+	return &ListAPIKeysHandler{repo: repo}
+}
+
+func (h *ListAPIKeysHandler) Handle(ctx context.Context, query *ListAPIKeysQuery) (*ListAPIKeysResponse, error) {
+	keys, err := h.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListAPIKeysResponse{
+		APIKeys: keys,
+		Total:   len(keys),
+	}, nil
+}
@@ -0,0 +1,35 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/features"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/featureflag"
+)
+
+type ListFeatureFlagsQuery struct{}
+
+type ListFeatureFlagsResponse struct {
+	Flags []featureflag.Flag `json:"flags"`
+	Total int                `json:"total"`
+}
+
+type ListFeatureFlagsHandler struct {
+	flags *features.Service
+}
+
+func NewListFeatureFlagsHandler(flags *features.Service) *ListFeatureFlagsHandler {
+	return &ListFeatureFlagsHandler{flags: flags}
+}
+
+func (h *ListFeatureFlagsHandler) Handle(ctx context.Context, query *ListFeatureFlagsQuery) (*ListFeatureFlagsResponse, error) {
+	flags, err := h.flags.Flags(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListFeatureFlagsResponse{
+		Flags: flags,
+		Total: len(flags),
+	}, nil
+}
@@ -0,0 +1,62 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/audit"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultAuditPageSize = 50
+	maxAuditPageSize     = 200
+)
+
+type ListProductAuditQuery struct {
+	ID         uuid.UUID `params:"id"`
+	PageSize   int       `query:"page_size"`
+	PageNumber int       `query:"page"`
+}
+
+type ListProductAuditResponse struct {
+	Entries  []audit.Entry `json:"entries"`
+	Total    int64         `json:"total"`
+	Page     int           `json:"page"`
+	PageSize int           `json:"page_size"`
+}
+
+type ListProductAuditHandler struct {
+	repo audit.Repository
+}
+
+func NewListProductAuditHandler(repo audit.Repository) *ListProductAuditHandler {
+	return &ListProductAuditHandler{repo: repo}
+}
+
+// Handle returns the audit trail of a product, newest first. The trail of a
+// deleted product remains available.
+func (h *ListProductAuditHandler) Handle(ctx context.Context, query *ListProductAuditQuery) (*ListProductAuditResponse, error) {
+	pageSize := query.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultAuditPageSize
+	}
+	pageSize = min(pageSize, maxAuditPageSize)
+	page := max(query.PageNumber, 0)
+
+	entries, total, err := h.repo.List(ctx, audit.Filter{
+		AggregateType: audit.AggregateProduct,
+		AggregateID:   query.ID,
+		PageSize:      pageSize,
+		PageNumber:    page,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListProductAuditResponse{
+		Entries:  entries,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}, nil
+}
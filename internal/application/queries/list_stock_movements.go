@@ -0,0 +1,90 @@
+package queries
+
+import (
+	"context"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/product"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultMovementPageSize = 50
+	maxMovementPageSize     = 200
+)
+
+// ListStockMovementsQuery selects movements that occurred from From up to,
+// but excluding, To. Both are RFC 3339 timestamps.
+type ListStockMovementsQuery struct {
+	ID         uuid.UUID `params:"id"`
+	From       string    `query:"from"`
+	To         string    `query:"to"`
+	PageSize   int       `query:"page_size"`
+	PageNumber int       `query:"page"`
+}
+
+type ListStockMovementsResponse struct {
+	Movements []product.Movement `json:"movements"`
+	Total     int64              `json:"total"`
+	Page      int                `json:"page"`
+	PageSize  int                `json:"page_size"`
+}
+
+type ListStockMovementsHandler struct {
+	repo product.MovementRepository
+}
+
+func NewListStockMovementsHandler(repo product.MovementRepository) *ListStockMovementsHandler {
+	return &ListStockMovementsHandler{repo: repo}
+}
+
+// Handle returns the stock ledger of a product, newest first. The ledger of a
+// deleted product remains available.
+func (h *ListStockMovementsHandler) Handle(ctx context.Context, query *ListStockMovementsQuery) (*ListStockMovementsResponse, error) {
+	from, err := parseTime("from", query.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseTime("to", query.To)
+	if err != nil {
+		return nil, err
+	}
+
+	pageSize := query.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultMovementPageSize
+	}
+	pageSize = min(pageSize, maxMovementPageSize)
+	page := max(query.PageNumber, 0)
+
+	movements, total, err := h.repo.ListMovements(ctx, product.MovementFilter{
+		ProductID:  query.ID,
+		From:       from,
+		To:         to,
+		PageSize:   pageSize,
+		PageNumber: page,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListStockMovementsResponse{
+		Movements: movements,
+		Total:     total,
+		Page:      page,
+		PageSize:  pageSize,
+	}, nil
+}
+
+// parseTime parses an optional RFC 3339 query parameter
+func parseTime(name, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, name+" must be an RFC 3339 timestamp")
+	}
+	return &t, nil
+}
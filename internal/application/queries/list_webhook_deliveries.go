@@ -0,0 +1,45 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/webhook"
+	"github.com/google/uuid"
+)
+
+type ListWebhookDeliveriesQuery struct {
+	SubscriptionID uuid.UUID               `params:"id"`
+	Status         *webhook.DeliveryStatus `query:"status"`
+	PageSize       int                     `query:"page_size"`
+	PageNumber     int                     `query:"page"`
+}
+
+type ListWebhookDeliveriesResponse struct {
+	Deliveries []webhook.Delivery `json:"deliveries"`
+	Total      int                `json:"total"`
+}
+
+type ListWebhookDeliveriesHandler struct {
+	repo webhook.Repository
+}
+
+func NewListWebhookDeliveriesHandler(repo webhook.Repository) *ListWebhookDeliveriesHandler {
+	return &ListWebhookDeliveriesHandler{repo: repo}
+}
+
+func (h *ListWebhookDeliveriesHandler) Handle(ctx context.Context, query *ListWebhookDeliveriesQuery) (*ListWebhookDeliveriesResponse, error) {
+	deliveries, err := h.repo.ListDeliveries(ctx, webhook.DeliveryFilter{
+		SubscriptionID: query.SubscriptionID,
+		Status:         query.Status,
+		PageSize:       query.PageSize,
+		PageNumber:     query.PageNumber,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListWebhookDeliveriesResponse{
+		Deliveries: deliveries,
+		Total:      len(deliveries),
+	}, nil
+}
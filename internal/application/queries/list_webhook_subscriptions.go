@@ -0,0 +1,34 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/webhook"
+)
+
+type ListWebhookSubscriptionsQuery struct{}
+
+type ListWebhookSubscriptionsResponse struct {
+	Subscriptions []webhook.Subscription `json:"subscriptions"`
+	Total         int                    `json:"total"`
+}
+
+type ListWebhookSubscriptionsHandler struct {
+	repo webhook.Repository
+}
+
+func NewListWebhookSubscriptionsHandler(repo webhook.Repository) *ListWebhookSubscriptionsHandler {
+	return &ListWebhookSubscriptionsHandler{repo: repo}
+}
+
+func (h *ListWebhookSubscriptionsHandler) Handle(ctx context.Context, query *ListWebhookSubscriptionsQuery) (*ListWebhookSubscriptionsResponse, error) {
+	subscriptions, err := h.repo.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListWebhookSubscriptionsResponse{
+		Subscriptions: subscriptions,
+		Total:         len(subscriptions),
+	}, nil
+}
@@ -0,0 +1,59 @@
+package stream
+
+import (
+	"encoding/json"
+	"slices"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/product"
+	"github.com/google/uuid"
+)
+
+// Filter selects the events a stream client receives. Empty fields match
+// everything, except TenantID: clients only receive their own tenant's events.
+//
+// Status matches events that leave a product in that status: creation,
+// activation, deactivation and discontinuation.
+type Filter struct {
+	TenantID   string
+	ProductIDs []uuid.UUID
+	Status     *product.ProductStatus
+}
+
+func (f Filter) Matches(event product.StoredEvent) bool {
+	if event.TenantID != f.TenantID {
+		return false
+	}
+
+	if len(f.ProductIDs) > 0 && !slices.Contains(f.ProductIDs, event.ProductID) {
+		return false
+	}
+
+	if f.Status != nil {
+		status, ok := resultingStatus(event)
+		if !ok || status != *f.Status {
+			return false
+		}
+	}
+
+	return true
+}
+
+func resultingStatus(event product.StoredEvent) (product.ProductStatus, bool) {
+	switch event.Type {
+	case product.EventProductActivated:
+		return product.StatusActive, true
+	case product.EventProductDeactivated:
+		return product.StatusInactive, true
+	case product.EventProductDiscontinued:
+		return product.StatusDiscontinued, true
+	case product.EventProductCreated:
+		var created struct {
+			Status product.ProductStatus `json:"status"`
+		}
+		if err := json.Unmarshal(event.Payload, &created); err != nil {
+			return "", false
+		}
+		return created.Status, true
+	}
+	return "", false
+}
@@ -0,0 +1,174 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/product"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+	"go.uber.org/zap"
+)
+
+var ErrClosed = errors.New("product event stream is shutting down")
+
+// Hub tails the product event log and broadcasts new events to subscribers.
+// Every replica runs its own hub, so clients see changes made on any replica.
+type Hub struct {
+	log product.EventLog
+	cfg config.StreamConfig
+
+	mu          sync.Mutex
+	subscribers map[*Subscription]struct{}
+	position    int64
+	closed      bool
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Subscription receives live events. Events is closed when the hub shuts
+// down or when the subscriber falls too far behind.
+type Subscription struct {
+	Events <-chan product.StoredEvent
+
+	events chan product.StoredEvent
+	hub    *Hub
+}
+
+func NewHub(log product.EventLog, cfg config.StreamConfig) *Hub {
+	return &Hub{
+		log:         log,
+		cfg:         cfg,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Start positions the hub at the head of the log and starts tailing it
+func (h *Hub) Start(ctx context.Context) error {
+	position, err := h.log.LatestPosition(ctx)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	h.position = position
+	h.mu.Unlock()
+
+	ctx, h.cancel = context.WithCancel(ctx)
+	h.wg.Add(1)
+	go h.run(ctx)
+	return nil
+}
+
+// Subscribe registers a subscriber. It returns the log position the live
+// events start after, so callers can replay everything up to it first.
+func (h *Hub) Subscribe() (*Subscription, int64, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil, 0, ErrClosed
+	}
+
+	events := make(chan product.StoredEvent, h.cfg.BufferSize)
+	s := &Subscription{Events: events, events: events, hub: h}
+	h.subscribers[s] = struct{}{}
+	return s, h.position, nil
+}
+
+// Close unregisters the subscription
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
+
+// Close stops tailing the log and ends every subscription. Subscribe fails afterwards.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	h.closed = true
+	for s := range h.subscribers {
+		h.remove(s)
+	}
+	h.mu.Unlock()
+
+	if h.cancel != nil {
+		h.cancel()
+	}
+	h.wg.Wait()
+}
+
+// Subscribers returns the number of connected subscribers
+func (h *Hub) Subscribers() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subscribers)
+}
+
+func (h *Hub) run(ctx context.Context) {
+	defer h.wg.Done()
+
+	ticker := time.NewTicker(h.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.poll(ctx)
+		}
+	}
+}
+
+func (h *Hub) poll(ctx context.Context) {
+	for ctx.Err() == nil {
+		h.mu.Lock()
+		after := h.position
+		h.mu.Unlock()
+
+		events, err := h.log.ReadAfter(ctx, after, h.cfg.BatchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				zap.L().Error("Failed to read product event log", zap.Error(err))
+			}
+			return
+		}
+		if len(events) == 0 {
+			return
+		}
+
+		h.broadcast(events)
+		if len(events) < h.cfg.BatchSize {
+			return
+		}
+	}
+}
+
+func (h *Hub) broadcast(events []product.StoredEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, event := range events {
+		for s := range h.subscribers {
+			select {
+			case s.events <- event:
+			default:
+				// Too slow to keep up; the client reconnects with Last-Event-ID
+				zap.L().Warn("Disconnecting slow product stream subscriber", zap.Int64("position", event.Position))
+				h.remove(s)
+			}
+		}
+	}
+	h.position = events[len(events)-1].Position
+}
+
+// remove must be called with h.mu held
+func (h *Hub) remove(s *Subscription) {
+	if _, ok := h.subscribers[s]; ok {
+		delete(h.subscribers, s)
+		close(s.events)
+	}
+}
@@ -0,0 +1,244 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/client"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/webhook"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/logger"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/tenant"
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// Sender posts a signed payload to a subscriber endpoint
+type Sender interface {
+	Post(ctx context.Context, url string, body []byte, headers map[string]string) (int, error)
+}
+
+// Dispatcher turns product events into webhook deliveries and sends them.
+// Failed deliveries are retried with exponential backoff and dead-lettered
+// after the configured number of attempts.
+type Dispatcher struct {
+	repo   webhook.Repository
+	sender Sender
+	cfg    config.WebhooksConfig
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewDispatcher(repo webhook.Repository, sender Sender, cfg config.WebhooksConfig) *Dispatcher {
+	return &Dispatcher{
+		repo:   repo,
+		sender: sender,
+		cfg:    cfg,
+	}
+}
+
+// Start launches the dispatch loop. It runs until Stop is called or ctx is done.
+func (d *Dispatcher) Start(ctx context.Context) {
+	ctx, d.cancel = context.WithCancel(ctx)
+	d.wg.Add(1)
+	go d.run(ctx)
+	zap.L().Info("Webhook dispatcher started")
+}
+
+// Stop waits for in-flight deliveries to finish
+func (d *Dispatcher) Stop(ctx context.Context) error {
+	if d.cancel == nil {
+		return nil
+	}
+	d.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		zap.L().Info("Webhook dispatcher stopped")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (d *Dispatcher) run(ctx context.Context) {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		d.fanOut(ctx)
+		d.deliverDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *Dispatcher) fanOut(ctx context.Context) {
+	for ctx.Err() == nil {
+		consumed, err := d.repo.FanOut(ctx, d.cfg.BatchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				zap.L().Error("Failed to fan out product events to webhooks", zap.Error(err))
+			}
+			return
+		}
+		if consumed < d.cfg.BatchSize {
+			return
+		}
+	}
+}
+
+func (d *Dispatcher) deliverDue(ctx context.Context) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	lease := d.cfg.LeaseTimeout
+	deliveries, err := d.repo.ClaimDue(ctx, d.cfg.BatchSize, lease)
+	if err != nil {
+		if ctx.Err() == nil {
+			zap.L().Error("Failed to claim webhook deliveries", zap.Error(err))
+		}
+		return
+	}
+
+	// Deliveries already claimed are finished even when shutting down
+	deliverCtx := context.WithoutCancel(ctx)
+
+	sem := make(chan struct{}, max(d.cfg.Concurrency, 1))
+	var wg sync.WaitGroup
+	for i := range deliveries {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(delivery *webhook.Delivery) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			d.deliver(deliverCtx, delivery)
+		}(&deliveries[i])
+	}
+	wg.Wait()
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery *webhook.Delivery) {
+	ctx = tenant.WithTenant(ctx, &tenant.Tenant{ID: delivery.TenantID})
+	tracer := otel.GetTracerProvider().Tracer("")
+	ctx, span := tracer.Start(ctx, "webhook delivery")
+	span.SetAttributes(
+		attribute.String("webhook.delivery_id", delivery.ID.String()),
+		attribute.String("webhook.event_type", delivery.EventType),
+		attribute.Int("webhook.attempt", delivery.Attempts+1),
+	)
+	defer span.End()
+
+	fields := append(logger.GetTraceFields(ctx),
+		zap.String("delivery_id", delivery.ID.String()),
+		zap.String("subscription_id", delivery.SubscriptionID.String()),
+		zap.String("event_type", delivery.EventType),
+	)
+
+	subscription, err := d.repo.GetSubscription(ctx, delivery.SubscriptionID)
+	var fiberErr *fiber.Error
+	switch {
+	case errors.As(err, &fiberErr) && fiberErr.Code == fiber.StatusNotFound:
+		delivery.Kill("subscription no longer exists")
+	case err != nil:
+		// Leave the delivery leased; it is picked up again when the lease expires
+		span.RecordError(err)
+		zap.L().Error("Failed to load webhook subscription", append(fields, zap.Error(err))...)
+		return
+	case !subscription.Active:
+		delivery.Kill("subscription is inactive")
+	default:
+		d.send(ctx, subscription, delivery)
+	}
+
+	if err := d.repo.UpdateDelivery(ctx, delivery); err != nil {
+		span.RecordError(err)
+		zap.L().Error("Failed to save webhook delivery", append(fields, zap.Error(err))...)
+		return
+	}
+
+	switch delivery.Status {
+	case webhook.DeliverySucceeded:
+		zap.L().Info("Webhook delivered", append(fields, zap.Int("status_code", delivery.LastStatusCode))...)
+	case webhook.DeliveryDead:
+		zap.L().Warn("Webhook delivery dead-lettered", append(fields, zap.String("reason", delivery.LastError))...)
+	default:
+		zap.L().Info("Webhook delivery failed, will retry", append(fields,
+			zap.Int("attempts", delivery.Attempts),
+			zap.Time("next_attempt_at", delivery.NextAttemptAt),
+			zap.String("reason", delivery.LastError),
+		)...)
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, subscription *webhook.Subscription, delivery *webhook.Delivery) {
+	timestamp := time.Now().Unix()
+	headers := map[string]string{
+		webhook.HeaderEventID:   delivery.EventID.String(),
+		webhook.HeaderEventType: delivery.EventType,
+		webhook.HeaderTimestamp: strconv.FormatInt(timestamp, 10),
+		webhook.HeaderSignature: webhook.Sign(subscription.Secret, timestamp, delivery.Payload),
+		// Stable across attempts, so subscribers and a retrying policy can
+		// recognize repeats of this delivery
+		client.IdempotencyKeyHeader: delivery.ID.String(),
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d.cfg.Timeout)
+	defer cancel()
+
+	statusCode, err := d.sender.Post(ctx, subscription.URL, delivery.Payload, headers)
+	switch {
+	case errors.Is(err, client.ErrForbiddenAddress):
+		delivery.Kill(err.Error())
+	case err != nil:
+		delivery.Fail(statusCode, err.Error(), d.cfg.MaxAttempts, d.backoff(delivery.Attempts+1))
+	case statusCode >= 200 && statusCode < 300:
+		delivery.Succeed(statusCode)
+	default:
+		reason := fmt.Sprintf("subscriber responded with status %d", statusCode)
+		delivery.Fail(statusCode, reason, d.cfg.MaxAttempts, d.backoff(delivery.Attempts+1))
+	}
+}
+
+// backoff returns the exponential, jittered wait before the given attempt
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	base := d.cfg.BackoffBase
+	maxWait := d.cfg.BackoffMax
+
+	wait := maxWait
+	if attempt < 32 {
+		if exp := base << (attempt - 1); exp > 0 && exp < maxWait {
+			wait = exp
+		}
+	}
+
+	// Jitter within the upper half of the window spreads out retries of a failed burst
+	half := wait / 2
+	if half <= 0 {
+		return wait
+	}
+	return half + rand.N(half)
+}
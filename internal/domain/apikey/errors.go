@@ -0,0 +1,8 @@
+package apikey
+
+import "errors"
+
+var (
+	ErrNotFound       = errors.New("api key not found")
+	ErrAlreadyRevoked = errors.New("api key is already revoked")
+)
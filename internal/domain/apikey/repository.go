@@ -0,0 +1,22 @@
+package apikey
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Repository persists API keys. Management methods are scoped to the tenant of
+// ctx; FindByPrefix is not, because the key itself identifies its tenant.
+type Repository interface {
+	Save(ctx context.Context, key *APIKey) error
+	GetByID(ctx context.Context, id uuid.UUID) (*APIKey, error)
+	List(ctx context.Context) ([]APIKey, error)
+	Revoke(ctx context.Context, key *APIKey) error
+
+	FindByPrefix(ctx context.Context, prefix string) (*APIKey, error)
+	// TouchLastUsed records a use, skipping the write when the last recorded
+	// use is more recent than interval
+	TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time, interval time.Duration) error
+}
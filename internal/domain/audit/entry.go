@@ -0,0 +1,56 @@
+package audit
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Aggregate types recorded in audit entries
+const (
+	AggregateProduct             = "product"
+	AggregateJob                 = "job"
+	AggregateWebhookSubscription = "webhook_subscription"
+	AggregateWebhookDelivery     = "webhook_delivery"
+	AggregateAPIKey              = "api_key"
+	AggregateFeatureFlag         = "feature_flag"
+	AggregateStockReservation    = "stock_reservation"
+)
+
+// Entry records who executed a command against an aggregate and what it changed
+type Entry struct {
+	ID            uuid.UUID         `json:"id"`
+	TenantID      string            `json:"-"`
+	Actor         string            `json:"actor"`
+	CommandType   string            `json:"command_type"`
+	AggregateType string            `json:"aggregate_type"`
+	AggregateID   uuid.UUID         `json:"aggregate_id"`
+	Changes       map[string]Change `json:"changes"`
+	TraceID       string            `json:"trace_id,omitempty"`
+	ClientIP      string            `json:"client_ip,omitempty"`
+	OccurredAt    time.Time         `json:"occurred_at"`
+}
+
+// Change is the value of a field before and after a command. Before is nil
+// for created aggregates and After is nil for deleted ones.
+type Change struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// Diff returns the fields whose value differs between two snapshots
+func Diff(before, after map[string]interface{}) map[string]Change {
+	changes := make(map[string]Change)
+	for field, value := range before {
+		if next, ok := after[field]; !ok || !reflect.DeepEqual(value, next) {
+			changes[field] = Change{Before: value, After: after[field]}
+		}
+	}
+	for field, value := range after {
+		if _, ok := before[field]; !ok {
+			changes[field] = Change{After: value}
+		}
+	}
+	return changes
+}
@@ -0,0 +1,23 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type Filter struct {
+	AggregateType string
+	AggregateID   uuid.UUID
+	PageSize      int
+	PageNumber    int
+}
+
+// Repository stores audit entries. Entries are append only; both methods are
+// scoped to the tenant of ctx.
+type Repository interface {
+	Append(ctx context.Context, entry *Entry) error
+	// List returns a page of matching entries, newest first, and the total
+	// number of matching entries
+	List(ctx context.Context, filter Filter) ([]Entry, int64, error)
+}
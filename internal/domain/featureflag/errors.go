@@ -0,0 +1,9 @@
+package featureflag
+
+import "errors"
+
+var (
+	ErrNotFound   = errors.New("feature flag not found")
+	ErrNotChanged = errors.New("feature flag has not been changed through the API")
+	ErrInvalidKey = errors.New("key must be 1 to 64 lowercase letters, digits, '.', '_' or '-'")
+)
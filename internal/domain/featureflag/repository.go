@@ -0,0 +1,12 @@
+package featureflag
+
+import "context"
+
+// Repository persists flags changed through the admin API. Flags are global,
+// not scoped to a tenant; targeting decides which tenants get them.
+type Repository interface {
+	List(ctx context.Context) ([]Flag, error)
+	// Save inserts the flag or replaces the one with the same key
+	Save(ctx context.Context, flag *Flag) error
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,9 @@
+package job
+
+import "errors"
+
+var (
+	ErrNotFound        = errors.New("job not found")
+	ErrAlreadyFinished = errors.New("job has already finished")
+	ErrLeaseLost       = errors.New("job lease lost to another worker")
+)
@@ -0,0 +1,127 @@
+package job
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Status string
+
+const (
+	StatusPending   Status = "PENDING"
+	StatusRunning   Status = "RUNNING"
+	StatusSucceeded Status = "SUCCEEDED"
+	StatusFailed    Status = "FAILED"
+	StatusCancelled Status = "CANCELLED"
+)
+
+type Type string
+
+const (
+	TypeBulkPriceChange  Type = "BULK_PRICE_CHANGE"
+	TypeBulkStatusChange Type = "BULK_STATUS_CHANGE"
+	TypeBulkImport       Type = "BULK_IMPORT"
+)
+
+// maxItemErrors bounds how many per-item failures are kept on a job
+const maxItemErrors = 100
+
+// ItemError describes a single item of a bulk job that could not be processed
+type ItemError struct {
+	Ref     string `json:"ref"`
+	Message string `json:"message"`
+}
+
+// Job is a long running bulk operation executed by background workers.
+// Progress is checkpointed through Cursor so a job can resume where it left off.
+type Job struct {
+	ID              uuid.UUID       `json:"id"`
+	TenantID        string          `json:"-"`
+	Type            Type            `json:"type"`
+	Status          Status          `json:"status"`
+	Payload         json.RawMessage `json:"payload"`
+	Cursor          string          `json:"-"`
+	Total           int             `json:"total"`
+	Processed       int             `json:"processed"`
+	Succeeded       int             `json:"succeeded"`
+	Failed          int             `json:"failed"`
+	Errors          []ItemError     `json:"errors"`
+	Error           string          `json:"error,omitempty"`
+	CancelRequested bool            `json:"cancel_requested"`
+	Attempts        int             `json:"attempts"`
+	LockedBy        string          `json:"-"`
+	SubmittedBy     string          `json:"submitted_by,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+	StartedAt       *time.Time      `json:"started_at,omitempty"`
+	FinishedAt      *time.Time      `json:"finished_at,omitempty"`
+}
+
+// Factory method
+func NewJob(jobType Type, payload any) (*Job, error) {
+	if jobType == "" {
+		return nil, errors.New("job type is required")
+	}
+
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Job{
+		ID:        uuid.New(),
+		Type:      jobType,
+		Status:    StatusPending,
+		Payload:   raw,
+		Errors:    []ItemError{},
+		CreatedAt: time.Now().UTC(),
+	}, nil
+}
+
+// Business methods
+func (j *Job) RecordSuccess() {
+	j.Processed++
+	j.Succeeded++
+}
+
+func (j *Job) RecordFailure(ref string, err error) {
+	j.Processed++
+	j.Failed++
+	if len(j.Errors) < maxItemErrors {
+		j.Errors = append(j.Errors, ItemError{Ref: ref, Message: err.Error()})
+	}
+}
+
+func (j *Job) Complete() {
+	j.finish(StatusSucceeded)
+}
+
+func (j *Job) Fail(reason string) {
+	j.Error = reason
+	j.finish(StatusFailed)
+}
+
+func (j *Job) Cancel() {
+	j.finish(StatusCancelled)
+}
+
+func (j *Job) IsFinished() bool {
+	switch j.Status {
+	case StatusSucceeded, StatusFailed, StatusCancelled:
+		return true
+	}
+	return false
+}
+
+// DecodePayload unmarshals the job payload into v
+func (j *Job) DecodePayload(v any) error {
+	return json.Unmarshal(j.Payload, v)
+}
+
+func (j *Job) finish(status Status) {
+	now := time.Now().UTC()
+	j.Status = status
+	j.FinishedAt = &now
+}
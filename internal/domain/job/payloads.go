@@ -0,0 +1,58 @@
+package job
+
+import "github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/product"
+
+const (
+	PriceModeSet     = "set"
+	PriceModePercent = "percent"
+	PriceModeDelta   = "delta"
+)
+
+// ProductSelector selects the products a bulk job applies to
+type ProductSelector struct {
+	MinPrice   *float64               `json:"min_price,omitempty"`
+	MaxPrice   *float64               `json:"max_price,omitempty"`
+	Status     *product.ProductStatus `json:"status,omitempty"`
+	MinStock   *int                   `json:"min_stock,omitempty"`
+	SearchTerm string                 `json:"search,omitempty"`
+}
+
+// Filter converts the selector into a product filter
+func (s ProductSelector) Filter() product.ProductFilter {
+	return product.ProductFilter{
+		MinPrice:   s.MinPrice,
+		MaxPrice:   s.MaxPrice,
+		Status:     s.Status,
+		StockLevel: s.MinStock,
+		SearchTerm: s.SearchTerm,
+	}
+}
+
+// BulkPriceChangePayload changes the price of every selected product.
+// Mode is one of "set", "percent" or "delta".
+type BulkPriceChangePayload struct {
+	Selector ProductSelector `json:"selector"`
+	Mode     string          `json:"mode"`
+	Value    float64         `json:"value"`
+	Currency string          `json:"currency,omitempty"`
+}
+
+// BulkStatusChangePayload applies a status action to every selected product
+type BulkStatusChangePayload struct {
+	Selector ProductSelector `json:"selector"`
+	Action   string          `json:"action"`
+}
+
+type ImportItem struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Price       float64 `json:"price"`
+	Currency    string  `json:"currency"`
+	StockLevel  int     `json:"stock_level"`
+	StockUnit   string  `json:"stock_unit"`
+}
+
+// BulkImportPayload creates one product per item
+type BulkImportPayload struct {
+	Items []ImportItem `json:"items"`
+}
@@ -0,0 +1,24 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Repository persists jobs and coordinates workers claiming them
+type Repository interface {
+	Save(ctx context.Context, job *Job) error
+	GetByID(ctx context.Context, id uuid.UUID) (*Job, error)
+	// ClaimNext leases the oldest runnable job to the given worker. Jobs whose
+	// lease has expired (e.g. the worker died) are runnable again, which is
+	// how jobs resume after a restart. It returns nil when there is no work.
+	ClaimNext(ctx context.Context, workerID string, lease time.Duration) (*Job, error)
+	// SaveProgress persists progress, extends the lease and refreshes
+	// CancelRequested from the store.
+	SaveProgress(ctx context.Context, job *Job, lease time.Duration) error
+	// Release hands a running job back to the queue without finishing it.
+	Release(ctx context.Context, job *Job) error
+	RequestCancel(ctx context.Context, id uuid.UUID) (*Job, error)
+}
@@ -5,4 +5,12 @@ import "errors"
 var (
 	ErrNotFound        = errors.New("product not found")
 	ErrVersionMismatch = errors.New("product version mismatch")
+
+	ErrInsufficientStock    = errors.New("not enough stock available")
+	ErrNotReservable        = errors.New("only active products can be reserved")
+	ErrReservationNotFound  = errors.New("reservation not found")
+	ErrReservationClosed    = errors.New("reservation is no longer active")
+	ErrReservationExpired   = errors.New("reservation has expired")
+	ErrReservationDuplicate = errors.New("order already holds a reservation of this product")
+	ErrIdempotencyKeyReused = errors.New("idempotency key was already used for a different stock movement")
 )
@@ -0,0 +1,179 @@
+package product
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event names as published to the event log and to subscribers
+const (
+	EventProductCreated      = "product.created"
+	EventProductPriceChanged = "product.price_changed"
+	EventProductStockChanged = "product.stock_changed"
+	EventProductActivated    = "product.activated"
+	EventProductDeactivated  = "product.deactivated"
+	EventProductDiscontinued = "product.discontinued"
+	EventProductDeleted      = "product.deleted"
+
+	EventStockReserved        = "product.stock_reserved"
+	EventReservationConfirmed = "product.reservation_confirmed"
+	EventReservationReleased  = "product.reservation_released"
+	EventReservationExpired   = "product.reservation_expired"
+)
+
+// EventNames lists every product event name
+var EventNames = []string{
+	EventProductCreated,
+	EventProductPriceChanged,
+	EventProductStockChanged,
+	EventProductActivated,
+	EventProductDeactivated,
+	EventProductDiscontinued,
+	EventProductDeleted,
+	EventStockReserved,
+	EventReservationConfirmed,
+	EventReservationReleased,
+	EventReservationExpired,
+}
+
+type ProductEvent interface {
+	EventName() string
+	AggregateID() uuid.UUID
+}
+
+type ProductCreated struct {
+	ProductID   uuid.UUID     `json:"product_id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Price       Price         `json:"price"`
+	StockLevel  int           `json:"stock_level"`
+	StockUnit   string        `json:"stock_unit"`
+	Status      ProductStatus `json:"status"`
+	OccurredAt  time.Time     `json:"occurred_at"`
+}
+
+type ProductPriceChanged struct {
+	ProductID uuid.UUID `json:"product_id"`
+	OldPrice  Price     `json:"old_price"`
+	NewPrice  Price     `json:"new_price"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// ProductStockChanged names the kind of movement that changed the stock and
+// its reference, e.g. the order of a sale
+type ProductStockChanged struct {
+	ProductID   uuid.UUID    `json:"product_id"`
+	OldQuantity int          `json:"old_quantity"`
+	NewQuantity int          `json:"new_quantity"`
+	Movement    MovementType `json:"movement"`
+	Reference   string       `json:"reference,omitempty"`
+	OccurredAt  time.Time    `json:"occurred_at"`
+}
+
+type ProductActivated struct {
+	ProductID      uuid.UUID     `json:"product_id"`
+	PreviousStatus ProductStatus `json:"previous_status"`
+	OccurredAt     time.Time     `json:"occurred_at"`
+}
+
+type ProductDeactivated struct {
+	ProductID      uuid.UUID     `json:"product_id"`
+	PreviousStatus ProductStatus `json:"previous_status"`
+	OccurredAt     time.Time     `json:"occurred_at"`
+}
+
+type ProductDiscontinued struct {
+	ProductID      uuid.UUID     `json:"product_id"`
+	PreviousStatus ProductStatus `json:"previous_status"`
+	OccurredAt     time.Time     `json:"occurred_at"`
+}
+
+type ProductDeleted struct {
+	ProductID  uuid.UUID `json:"product_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// StockReserved and the reservation events that follow it carry the
+// product's available stock after the change
+type StockReserved struct {
+	ProductID     uuid.UUID `json:"product_id"`
+	ReservationID uuid.UUID `json:"reservation_id"`
+	OrderID       string    `json:"order_id"`
+	Quantity      int       `json:"quantity"`
+	Available     int       `json:"available"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	OccurredAt    time.Time `json:"occurred_at"`
+}
+
+type StockReservationConfirmed struct {
+	ProductID     uuid.UUID `json:"product_id"`
+	ReservationID uuid.UUID `json:"reservation_id"`
+	OrderID       string    `json:"order_id"`
+	Quantity      int       `json:"quantity"`
+	Available     int       `json:"available"`
+	OccurredAt    time.Time `json:"occurred_at"`
+}
+
+type StockReservationReleased struct {
+	ProductID     uuid.UUID `json:"product_id"`
+	ReservationID uuid.UUID `json:"reservation_id"`
+	OrderID       string    `json:"order_id"`
+	Quantity      int       `json:"quantity"`
+	Available     int       `json:"available"`
+	OccurredAt    time.Time `json:"occurred_at"`
+}
+
+type StockReservationExpired struct {
+	ProductID     uuid.UUID `json:"product_id"`
+	ReservationID uuid.UUID `json:"reservation_id"`
+	OrderID       string    `json:"order_id"`
+	Quantity      int       `json:"quantity"`
+	Available     int       `json:"available"`
+	OccurredAt    time.Time `json:"occurred_at"`
+}
+
+func (e ProductCreated) EventName() string            { return EventProductCreated }
+func (e ProductPriceChanged) EventName() string       { return EventProductPriceChanged }
+func (e ProductStockChanged) EventName() string       { return EventProductStockChanged }
+func (e ProductActivated) EventName() string          { return EventProductActivated }
+func (e ProductDeactivated) EventName() string        { return EventProductDeactivated }
+func (e ProductDiscontinued) EventName() string       { return EventProductDiscontinued }
+func (e ProductDeleted) EventName() string            { return EventProductDeleted }
+func (e StockReserved) EventName() string             { return EventStockReserved }
+func (e StockReservationConfirmed) EventName() string { return EventReservationConfirmed }
+func (e StockReservationReleased) EventName() string  { return EventReservationReleased }
+func (e StockReservationExpired) EventName() string   { return EventReservationExpired }
+
+func (e ProductCreated) AggregateID() uuid.UUID            { return e.ProductID }
+func (e ProductPriceChanged) AggregateID() uuid.UUID       { return e.ProductID }
+func (e ProductStockChanged) AggregateID() uuid.UUID       { return e.ProductID }
+func (e ProductActivated) AggregateID() uuid.UUID          { return e.ProductID }
+func (e ProductDeactivated) AggregateID() uuid.UUID        { return e.ProductID }
+func (e ProductDiscontinued) AggregateID() uuid.UUID       { return e.ProductID }
+func (e ProductDeleted) AggregateID() uuid.UUID            { return e.ProductID }
+func (e StockReserved) AggregateID() uuid.UUID             { return e.ProductID }
+func (e StockReservationConfirmed) AggregateID() uuid.UUID { return e.ProductID }
+func (e StockReservationReleased) AggregateID() uuid.UUID  { return e.ProductID }
+func (e StockReservationExpired) AggregateID() uuid.UUID   { return e.ProductID }
+
+// StoredEvent is a product event as recorded in the event log.
+// Position increases monotonically and can be used as a resume cursor.
+type StoredEvent struct {
+	Position   int64           `json:"position"`
+	ID         uuid.UUID       `json:"id"`
+	TenantID   string          `json:"tenant_id"`
+	ProductID  uuid.UUID       `json:"product_id"`
+	Type       string          `json:"type"`
+	Payload    json.RawMessage `json:"payload"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+// EventLog gives read access to the append-only log of product events
+type EventLog interface {
+	ReadAfter(ctx context.Context, position int64, limit int) ([]StoredEvent, error)
+	// LatestPosition returns the position of the newest event, or 0 when the log is empty
+	LatestPosition(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,145 @@
+package product
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MovementType string
+
+const (
+	MovementReceipt    MovementType = "RECEIPT"
+	MovementSale       MovementType = "SALE"
+	MovementAdjustment MovementType = "ADJUSTMENT"
+	MovementReturn     MovementType = "RETURN"
+	MovementWriteOff   MovementType = "WRITE_OFF"
+)
+
+// SystemActor is recorded for movements nobody in particular made, such as
+// opening balances and changes by background processes
+const SystemActor = "system"
+
+// OpeningBalance is the reference of the movement recording the stock a
+// product had before the ledger existed
+const OpeningBalance = "opening balance"
+
+// Movement is an entry of the append-only stock ledger. A product's stock
+// level is the sum of the deltas of its movements.
+type Movement struct {
+	ID        uuid.UUID    `json:"id"`
+	TenantID  string       `json:"-"`
+	ProductID uuid.UUID    `json:"product_id"`
+	Type      MovementType `json:"type"`
+	Delta     int          `json:"delta"`
+	// Balance is the stock level after the movement
+	Balance   int    `json:"balance"`
+	Reference string `json:"reference,omitempty"`
+	// IdempotencyKey identifies the request that made the movement, so a
+	// retried request does not move the stock again
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+	Actor          string `json:"actor"`
+	// Position orders the movements of a product
+	Position   int64     `json:"position"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// MovementFilter selects the movements of a product, optionally within
+// [From, To)
+type MovementFilter struct {
+	ProductID  uuid.UUID
+	From       *time.Time
+	To         *time.Time
+	PageSize   int
+	PageNumber int
+}
+
+// delta returns the signed stock change of moving quantity units. Only
+// adjustments take a sign; the other types say which way units move.
+func (t MovementType) delta(quantity int) (int, error) {
+	switch t {
+	case MovementReceipt, MovementReturn, MovementSale, MovementWriteOff:
+		if quantity <= 0 {
+			return 0, fmt.Errorf("quantity of a %s must be positive", t)
+		}
+		if t == MovementSale || t == MovementWriteOff {
+			return -quantity, nil
+		}
+		return quantity, nil
+	case MovementAdjustment:
+		if quantity == 0 {
+			return 0, errors.New("adjustment must change the stock")
+		}
+		return quantity, nil
+	}
+	return 0, fmt.Errorf("unknown movement type %q", t)
+}
+
+// MoveStock changes the stock relative to its current level and records the
+// movement. Discontinued products only accept write-offs.
+func (p *Product) MoveStock(t MovementType, quantity int, reference, idempotencyKey string) (*Movement, error) {
+	delta, err := t.delta(quantity)
+	if err != nil {
+		return nil, err
+	}
+	if p.status == StatusDiscontinued && t != MovementWriteOff {
+		return nil, errors.New("cannot update stock of discontinued product")
+	}
+	if p.stock.quantity+delta < p.stock.reserved {
+		return nil, ErrInsufficientStock
+	}
+
+	p.version++
+	return p.changeStock(p.stock.quantity+delta, t, reference, idempotencyKey), nil
+}
+
+// changeStock sets the stock level, recording the change as an event and a
+// movement. Keeping the level records nothing.
+func (p *Product) changeStock(quantity int, t MovementType, reference, idempotencyKey string) *Movement {
+	oldQuantity := p.stock.quantity
+	if quantity == oldQuantity {
+		return nil
+	}
+	now := time.Now().UTC()
+	p.stock.quantity = quantity
+	p.record(ProductStockChanged{
+		ProductID:   p.id,
+		OldQuantity: oldQuantity,
+		NewQuantity: quantity,
+		Movement:    t,
+		Reference:   reference,
+		OccurredAt:  now,
+	})
+	return p.move(t, quantity-oldQuantity, reference, idempotencyKey, now)
+}
+
+func (p *Product) move(t MovementType, delta int, reference, idempotencyKey string, now time.Time) *Movement {
+	m := Movement{
+		ID:             uuid.New(),
+		TenantID:       p.tenantID,
+		ProductID:      p.id,
+		Type:           t,
+		Delta:          delta,
+		Balance:        p.stock.quantity,
+		Reference:      reference,
+		IdempotencyKey: idempotencyKey,
+		OccurredAt:     now,
+	}
+	p.movements = append(p.movements, m)
+	return &m
+}
+
+// Repeats reports whether m was made by the same request as moving quantity
+// units of type t with the given reference
+func (m *Movement) Repeats(t MovementType, quantity int, reference string) bool {
+	delta, err := t.delta(quantity)
+	return err == nil && m.Type == t && m.Delta == delta && m.Reference == reference
+}
+
+// Movements returns the stock movements made since the product was loaded
+func (p *Product) Movements() []Movement { return p.movements }
+
+// ClearMovements drops recorded movements once they have been persisted
+func (p *Product) ClearMovements() { p.movements = nil }
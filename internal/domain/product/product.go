@@ -1,6 +1,7 @@
 package product
 
 import (
+	"encoding/json"
 	"errors"
 	"time"
 
@@ -26,6 +27,8 @@ func NewPrice(amount float64, currency string) (Price, error) {
 type Stock struct {
 	quantity int
 	unit     string
+	// reserved is the part of quantity held by active reservations
+	reserved int
 }
 
 func NewStock(quantity int, unit string) (Stock, error) {
@@ -41,6 +44,7 @@ func NewStock(quantity int, unit string) (Stock, error) {
 // Product Entity (Aggregate Root)
 type Product struct {
 	id          uuid.UUID
+	tenantID    string
 	name        string
 	description string
 	price       Price
@@ -48,6 +52,7 @@ type Product struct {
 	status      ProductStatus
 	version     int
 	events      []ProductEvent
+	movements   []Movement
 }
 
 type ProductStatus string
@@ -65,7 +70,7 @@ func NewProduct(name, description string, price Price, stock Stock) (*Product, e
 		return nil, errors.New("product name is required")
 	}
 
-	return &Product{
+	p := &Product{
 		id:          uuid.New(),
 		name:        name,
 		description: description,
@@ -73,7 +78,21 @@ func NewProduct(name, description string, price Price, stock Stock) (*Product, e
 		stock:       stock,
 		status:      StatusDraft,
 		version:     1,
-	}, nil
+	}
+	p.record(ProductCreated{
+		ProductID:   p.id,
+		Name:        name,
+		Description: description,
+		Price:       price,
+		StockLevel:  stock.quantity,
+		StockUnit:   stock.unit,
+		Status:      p.status,
+		OccurredAt:  time.Now().UTC(),
+	})
+	if stock.quantity > 0 {
+		p.move(MovementReceipt, stock.quantity, "", "", time.Now().UTC())
+	}
+	return p, nil
 }
 
 // Business methods
@@ -84,8 +103,10 @@ func (p *Product) Activate() error {
 	if p.stock.quantity == 0 {
 		return errors.New("cannot activate product with zero stock")
 	}
+	previous := p.status
 	p.status = StatusActive
 	p.version++
+	p.record(ProductActivated{ProductID: p.id, PreviousStatus: previous, OccurredAt: time.Now().UTC()})
 	return nil
 }
 
@@ -93,8 +114,10 @@ func (p *Product) Deactivate() error {
 	if p.status == StatusDiscontinued {
 		return errors.New("cannot deactivate discontinued product")
 	}
+	previous := p.status
 	p.status = StatusInactive
 	p.version++
+	p.record(ProductDeactivated{ProductID: p.id, PreviousStatus: previous, OccurredAt: time.Now().UTC()})
 	return nil
 }
 
@@ -102,8 +125,10 @@ func (p *Product) UpdatePrice(newPrice Price) error {
 	if p.status == StatusDiscontinued {
 		return errors.New("cannot update price of discontinued product")
 	}
+	oldPrice := p.price
 	p.price = newPrice
 	p.version++
+	p.record(ProductPriceChanged{ProductID: p.id, OldPrice: oldPrice, NewPrice: newPrice, ChangedAt: time.Now().UTC()})
 	return nil
 }
 
@@ -114,14 +139,17 @@ func (p *Product) UpdateStock(quantity int) error {
 	if p.status == StatusDiscontinued {
 		return errors.New("cannot update stock of discontinued product")
 	}
-
-	newStock, err := NewStock(quantity, p.stock.unit)
-	if err != nil {
-		return err
+	if quantity < p.stock.reserved {
+		return ErrInsufficientStock
+	}
+	// Subscribers are not told about a level that did not change
+	if quantity == p.stock.quantity {
+		return nil
 	}
 
-	p.stock = newStock
+	// Setting an absolute level is recorded as an adjustment
 	p.version++
+	p.changeStock(quantity, MovementAdjustment, "", "")
 	return nil
 }
 
@@ -129,13 +157,16 @@ func (p *Product) Discontinue() error {
 	if p.status == StatusDiscontinued {
 		return errors.New("product is already discontinued")
 	}
+	previous := p.status
 	p.status = StatusDiscontinued
 	p.version++
+	p.record(ProductDiscontinued{ProductID: p.id, PreviousStatus: previous, OccurredAt: time.Now().UTC()})
 	return nil
 }
 
 // Getters (since fields are private)
 func (p *Product) ID() uuid.UUID         { return p.id }
+func (p *Product) TenantID() string      { return p.tenantID }
 func (p *Product) Name() string          { return p.name }
 func (p *Product) Description() string   { return p.description }
 func (p *Product) Price() float64        { return p.price.amount }
@@ -145,11 +176,33 @@ func (p *Product) StockUnit() string     { return p.stock.unit }
 func (p *Product) Status() ProductStatus { return p.status }
 func (p *Product) Version() int          { return p.version }
 
+// MarshalJSON renders the product as its read model, as responses of the
+// commands returning it are documented
+func (p *Product) MarshalJSON() ([]byte, error) {
+	return json.Marshal(NewProductReadModel(p))
+}
+
+// Events returns the domain events recorded since the product was loaded
+func (p *Product) Events() []ProductEvent { return p.events }
+
+// ClearEvents drops recorded events once they have been persisted
+func (p *Product) ClearEvents() { p.events = nil }
+
+func (p *Product) record(event ProductEvent) {
+	p.events = append(p.events, event)
+}
+
 // Setters for persistence layer
 func (p *Product) SetID(id uuid.UUID) {
 	p.id = id
 }
 
+// SetTenantID assigns the owning tenant. Repositories set it from the request
+// when a new product is saved.
+func (p *Product) SetTenantID(tenantID string) {
+	p.tenantID = tenantID
+}
+
 func (p *Product) SetStatus(status ProductStatus) {
 	p.status = status
 }
@@ -158,11 +211,22 @@ func (p *Product) SetVersion(version int) {
 	p.version = version
 }
 
+func (p *Product) SetReservedStock(reserved int) {
+	p.stock.reserved = reserved
+}
+
 // Price methods
 func (p Price) Amount() float64 {
 	return p.amount
 }
 
+func (p Price) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   float64 `json:"amount"`
+		Currency string  `json:"currency"`
+	}{p.amount, p.currency})
+}
+
 func (p Price) Currency() string {
 	return p.currency
 }
@@ -175,14 +239,3 @@ func (s Stock) Quantity() int {
 func (s Stock) Unit() string {
 	return s.unit
 }
-
-type ProductEvent interface {
-	EventName() string
-}
-
-type ProductPriceChanged struct {
-	ProductID uuid.UUID
-	OldPrice  Price
-	NewPrice  Price
-	ChangedAt time.Time
-}
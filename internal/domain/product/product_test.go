@@ -0,0 +1,39 @@
+package product
+
+import "testing"
+
+func TestUpdateStockToCurrentLevel(t *testing.T) {
+	p := newActiveProduct(t, 10)
+	version := p.Version()
+
+	if err := p.UpdateStock(10); err != nil {
+		t.Fatal(err)
+	}
+	if p.Version() != version {
+		t.Errorf("version = %d, want %d", p.Version(), version)
+	}
+	if names := eventNames(p); len(names) != 0 {
+		t.Errorf("recorded events %v, want none", names)
+	}
+	if m := p.Movements(); len(m) != 0 {
+		t.Errorf("recorded movements %+v, want none", m)
+	}
+}
+
+func TestUpdateStock(t *testing.T) {
+	p := newActiveProduct(t, 10)
+	version := p.Version()
+
+	if err := p.UpdateStock(4); err != nil {
+		t.Fatal(err)
+	}
+	if p.Stock() != 4 || p.Version() != version+1 {
+		t.Errorf("stock %d at version %d, want 4 at %d", p.Stock(), p.Version(), version+1)
+	}
+	if names := eventNames(p); len(names) != 1 || names[0] != EventProductStockChanged {
+		t.Errorf("recorded events %v, want %s", names, EventProductStockChanged)
+	}
+	if m := p.Movements(); len(m) != 1 || m[0].Delta != -6 {
+		t.Errorf("movements = %+v, want one of -6", m)
+	}
+}
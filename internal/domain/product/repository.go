@@ -2,6 +2,7 @@ package product
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -14,24 +15,80 @@ type Repository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*Product, error)
 }
 
+// ReservationRepository stores stock reservations. Each change locks the
+// product, so concurrent reservations can never hold more than its stock.
+type ReservationRepository interface {
+	Reserve(ctx context.Context, productID uuid.UUID, orderID string, quantity int, ttl time.Duration) (*Reservation, error)
+	ConfirmReservation(ctx context.Context, id uuid.UUID) (*Reservation, error)
+	ReleaseReservation(ctx context.Context, id uuid.UUID) (*Reservation, error)
+	GetReservation(ctx context.Context, id uuid.UUID) (*Reservation, error)
+	// ExpireReservations expires up to limit reservations of any tenant whose
+	// TTL has passed and returns them
+	ExpireReservations(ctx context.Context, limit int) ([]Reservation, error)
+}
+
+// MovementRepository gives access to the stock ledger. Movements are also
+// recorded by every other change of a product's stock.
+type MovementRepository interface {
+	// MoveStock changes the stock relative to its level, so concurrent moves
+	// need no version and never overwrite each other. A movement with the
+	// same idempotency key is returned instead of moving the stock again.
+	MoveStock(ctx context.Context, productID uuid.UUID, movementType MovementType, quantity int, reference, idempotencyKey string) (*Movement, error)
+	// ListMovements returns a page of a product's movements, newest first, and
+	// the total number of matching movements
+	ListMovements(ctx context.Context, filter MovementFilter) ([]Movement, int64, error)
+	// LedgerBalance returns the sum of the deltas of a product's movements and
+	// false when it has none yet
+	LedgerBalance(ctx context.Context, productID uuid.UUID) (int, bool, error)
+}
+
 // ReadOnlyRepository represents a read-only repository for product queries
 type ReadOnlyRepository interface {
 	FindByID(ctx context.Context, id uuid.UUID) (*ProductReadModel, error)
 	FindAll(ctx context.Context, filter ProductFilter) ([]ProductReadModel, error)
 	FindByStatus(ctx context.Context, status ProductStatus) ([]ProductReadModel, error)
+	// FindIDs returns up to limit IDs matching the filter that sort after the given ID.
+	// Pagination fields of the filter are ignored.
+	FindIDs(ctx context.Context, filter ProductFilter, after uuid.UUID, limit int) ([]uuid.UUID, error)
+	// FindAfter is the keyset paginated variant of FindAll, ordered by ID
+	FindAfter(ctx context.Context, filter ProductFilter, after uuid.UUID, limit int) ([]ProductReadModel, error)
+	FindByIDs(ctx context.Context, ids []uuid.UUID) ([]ProductReadModel, error)
+	Count(ctx context.Context, filter ProductFilter) (int64, error)
 }
 
 // ProductReadModel represents a denormalized view of the Product aggregate
 type ProductReadModel struct {
-	ID          uuid.UUID     `json:"id"`
-	Name        string        `json:"name"`
-	Description string        `json:"description"`
-	PriceAmount float64       `json:"price_amount"`
-	Currency    string        `json:"currency"`
-	StockLevel  int           `json:"stock_level"`
-	StockUnit   string        `json:"stock_unit"`
-	Status      ProductStatus `json:"status"`
-	Version     int           `json:"version"`
+	ID          uuid.UUID `json:"id"`
+	TenantID    string    `json:"tenant_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	PriceAmount float64   `json:"price_amount"`
+	Currency    string    `json:"currency"`
+	StockLevel  int       `json:"stock_level"`
+	StockUnit   string    `json:"stock_unit"`
+	// ReservedStock is held by active reservations and not available
+	ReservedStock  int           `json:"reserved_stock"`
+	AvailableStock int           `json:"available_stock"`
+	Status         ProductStatus `json:"status"`
+	Version        int           `json:"version"`
+}
+
+// NewProductReadModel returns the read model view of a loaded product
+func NewProductReadModel(p *Product) ProductReadModel {
+	return ProductReadModel{
+		ID:             p.ID(),
+		TenantID:       p.TenantID(),
+		Name:           p.Name(),
+		Description:    p.Description(),
+		PriceAmount:    p.Price(),
+		Currency:       p.Currency(),
+		StockLevel:     p.Stock(),
+		StockUnit:      p.StockUnit(),
+		ReservedStock:  p.Reserved(),
+		AvailableStock: p.Available(),
+		Status:         p.Status(),
+		Version:        p.Version(),
+	}
 }
 
 // ProductFilter represents query filters for products
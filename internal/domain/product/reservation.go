@@ -0,0 +1,153 @@
+package product
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReservationStatus string
+
+const (
+	ReservationActive    ReservationStatus = "ACTIVE"
+	ReservationConfirmed ReservationStatus = "CONFIRMED"
+	ReservationReleased  ReservationStatus = "RELEASED"
+	ReservationExpired   ReservationStatus = "EXPIRED"
+)
+
+// Reservation holds units of a product's stock for an order until it is
+// confirmed, released or expires. Active reservations count against the
+// available stock; confirming one takes its units off the stock.
+type Reservation struct {
+	ID        uuid.UUID         `json:"id"`
+	TenantID  string            `json:"-"`
+	ProductID uuid.UUID         `json:"product_id"`
+	OrderID   string            `json:"order_id"`
+	Quantity  int               `json:"quantity"`
+	Status    ReservationStatus `json:"status"`
+	ExpiresAt time.Time         `json:"expires_at"`
+	CreatedAt time.Time         `json:"created_at"`
+	// ClosedAt is set once the reservation is no longer active
+	ClosedAt *time.Time `json:"closed_at,omitempty"`
+}
+
+// IsActive reports whether the reservation still holds stock at now
+func (r *Reservation) IsActive(now time.Time) bool {
+	return r.Status == ReservationActive && now.Before(r.ExpiresAt)
+}
+
+func (r *Reservation) close(status ReservationStatus, now time.Time) error {
+	if r.Status != ReservationActive {
+		return ErrReservationClosed
+	}
+	r.Status = status
+	r.ClosedAt = &now
+	return nil
+}
+
+// Available is the stock not held by active reservations
+func (p *Product) Available() int { return p.stock.quantity - p.stock.reserved }
+
+// Reserved is the stock held by active reservations
+func (p *Product) Reserved() int { return p.stock.reserved }
+
+// Reserve holds quantity units for an order until ttl has passed
+func (p *Product) Reserve(orderID string, quantity int, ttl time.Duration) (*Reservation, error) {
+	if orderID == "" {
+		return nil, errors.New("order ID is required")
+	}
+	if quantity <= 0 {
+		return nil, errors.New("reserved quantity must be positive")
+	}
+	if ttl <= 0 {
+		return nil, errors.New("reservation TTL must be positive")
+	}
+	if p.status != StatusActive {
+		return nil, ErrNotReservable
+	}
+	if quantity > p.Available() {
+		return nil, ErrInsufficientStock
+	}
+
+	now := time.Now().UTC()
+	r := &Reservation{
+		ID:        uuid.New(),
+		TenantID:  p.tenantID,
+		ProductID: p.id,
+		OrderID:   orderID,
+		Quantity:  quantity,
+		Status:    ReservationActive,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+	p.stock.reserved += quantity
+	p.version++
+	p.record(StockReserved{
+		ProductID:     p.id,
+		ReservationID: r.ID,
+		OrderID:       orderID,
+		Quantity:      quantity,
+		Available:     p.Available(),
+		ExpiresAt:     r.ExpiresAt,
+		OccurredAt:    now,
+	})
+	return r, nil
+}
+
+// ConfirmReservation turns the reserved units into a sale, taking them off the stock
+func (p *Product) ConfirmReservation(r *Reservation) error {
+	if r.ProductID != p.id {
+		return errors.New("reservation belongs to another product")
+	}
+	now := time.Now().UTC()
+	if r.Status == ReservationActive && !r.IsActive(now) {
+		return ErrReservationExpired
+	}
+	if err := r.close(ReservationConfirmed, now); err != nil {
+		return err
+	}
+
+	// The units leave the reservation and the stock together, so the
+	// available stock stays the same
+	p.record(StockReservationConfirmed{ProductID: p.id, ReservationID: r.ID, OrderID: r.OrderID, Quantity: r.Quantity, Available: p.Available(), OccurredAt: now})
+	p.stock.reserved -= r.Quantity
+	p.version++
+	p.changeStock(p.stock.quantity-r.Quantity, MovementSale, r.OrderID, "")
+	return nil
+}
+
+// ReleaseReservation returns the reserved units to the available stock
+func (p *Product) ReleaseReservation(r *Reservation) error {
+	if r.ProductID != p.id {
+		return errors.New("reservation belongs to another product")
+	}
+	now := time.Now().UTC()
+	if err := r.close(ReservationReleased, now); err != nil {
+		return err
+	}
+
+	p.stock.reserved -= r.Quantity
+	p.version++
+	p.record(StockReservationReleased{ProductID: p.id, ReservationID: r.ID, OrderID: r.OrderID, Quantity: r.Quantity, Available: p.Available(), OccurredAt: now})
+	return nil
+}
+
+// ExpireReservation returns the units of a reservation whose TTL has passed
+func (p *Product) ExpireReservation(r *Reservation) error {
+	if r.ProductID != p.id {
+		return errors.New("reservation belongs to another product")
+	}
+	now := time.Now().UTC()
+	if r.IsActive(now) {
+		return errors.New("reservation has not expired yet")
+	}
+	if err := r.close(ReservationExpired, now); err != nil {
+		return err
+	}
+
+	p.stock.reserved -= r.Quantity
+	p.version++
+	p.record(StockReservationExpired{ProductID: p.id, ReservationID: r.ID, OrderID: r.OrderID, Quantity: r.Quantity, Available: p.Available(), OccurredAt: now})
+	return nil
+}
@@ -0,0 +1,152 @@
+package product
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func newActiveProduct(t *testing.T, quantity int) *Product {
+	t.Helper()
+	price, _ := NewPrice(10, "USD")
+	stock, _ := NewStock(quantity, "pcs")
+	p, err := NewProduct("Widget", "", price, stock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Activate(); err != nil {
+		t.Fatal(err)
+	}
+	p.ClearEvents()
+	p.ClearMovements()
+	return p
+}
+
+func eventNames(p *Product) []string {
+	var names []string
+	for _, e := range p.Events() {
+		names = append(names, e.EventName())
+	}
+	return names
+}
+
+func TestReserve(t *testing.T) {
+	p := newActiveProduct(t, 10)
+
+	r, err := p.Reserve("order-1", 4, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Status != ReservationActive || r.Quantity != 4 || !r.IsActive(time.Now()) {
+		t.Errorf("reservation = %+v", r)
+	}
+	if p.Stock() != 10 || p.Reserved() != 4 || p.Available() != 6 {
+		t.Errorf("stock %d, reserved %d, available %d; want 10, 4, 6", p.Stock(), p.Reserved(), p.Available())
+	}
+	if len(p.Movements()) != 0 {
+		t.Errorf("reserving moved stock: %+v", p.Movements())
+	}
+
+	if _, err := p.Reserve("order-2", 7, time.Minute); !errors.Is(err, ErrInsufficientStock) {
+		t.Errorf("reserving more than available: err = %v, want %v", err, ErrInsufficientStock)
+	}
+	if _, err := p.Reserve("order-2", 0, time.Minute); err == nil {
+		t.Error("reserving nothing succeeded")
+	}
+	if err := p.UpdateStock(3); !errors.Is(err, ErrInsufficientStock) {
+		t.Errorf("stock below reserved: err = %v, want %v", err, ErrInsufficientStock)
+	}
+}
+
+func TestReserveInactiveProduct(t *testing.T) {
+	p := newActiveProduct(t, 10)
+	if err := p.Deactivate(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Reserve("order-1", 1, time.Minute); !errors.Is(err, ErrNotReservable) {
+		t.Errorf("err = %v, want %v", err, ErrNotReservable)
+	}
+}
+
+func TestConfirmReservation(t *testing.T) {
+	p := newActiveProduct(t, 10)
+	r, _ := p.Reserve("order-1", 4, time.Minute)
+	p.ClearEvents()
+
+	if err := p.ConfirmReservation(r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Status != ReservationConfirmed || r.ClosedAt == nil {
+		t.Errorf("reservation = %+v", r)
+	}
+	if p.Stock() != 6 || p.Reserved() != 0 || p.Available() != 6 {
+		t.Errorf("stock %d, reserved %d, available %d; want 6, 0, 6", p.Stock(), p.Reserved(), p.Available())
+	}
+
+	names := eventNames(p)
+	if len(names) != 2 || names[0] != EventReservationConfirmed || names[1] != EventProductStockChanged {
+		t.Errorf("events = %v, want confirmation then stock change", names)
+	}
+	m := p.Movements()
+	if len(m) != 1 || m[0].Type != MovementSale || m[0].Delta != -4 || m[0].Reference != "order-1" {
+		t.Errorf("movements = %+v, want a sale of 4 for order-1", m)
+	}
+
+	if err := p.ConfirmReservation(r); !errors.Is(err, ErrReservationClosed) {
+		t.Errorf("confirming twice: err = %v, want %v", err, ErrReservationClosed)
+	}
+}
+
+func TestConfirmExpiredReservation(t *testing.T) {
+	p := newActiveProduct(t, 10)
+	r, _ := p.Reserve("order-1", 4, time.Minute)
+	r.ExpiresAt = time.Now().Add(-time.Second)
+
+	if err := p.ConfirmReservation(r); !errors.Is(err, ErrReservationExpired) {
+		t.Errorf("err = %v, want %v", err, ErrReservationExpired)
+	}
+	if p.Stock() != 10 || p.Reserved() != 4 {
+		t.Errorf("stock %d, reserved %d; want 10, 4", p.Stock(), p.Reserved())
+	}
+}
+
+func TestReleaseReservation(t *testing.T) {
+	p := newActiveProduct(t, 10)
+	r, _ := p.Reserve("order-1", 4, time.Minute)
+
+	if err := p.ReleaseReservation(r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Status != ReservationReleased {
+		t.Errorf("status = %s, want %s", r.Status, ReservationReleased)
+	}
+	if p.Stock() != 10 || p.Available() != 10 {
+		t.Errorf("stock %d, available %d; want 10, 10", p.Stock(), p.Available())
+	}
+	if len(p.Movements()) != 0 {
+		t.Errorf("releasing moved stock: %+v", p.Movements())
+	}
+	if err := p.ReleaseReservation(r); !errors.Is(err, ErrReservationClosed) {
+		t.Errorf("releasing twice: err = %v, want %v", err, ErrReservationClosed)
+	}
+}
+
+func TestExpireReservation(t *testing.T) {
+	p := newActiveProduct(t, 10)
+	r, _ := p.Reserve("order-1", 4, time.Minute)
+
+	if err := p.ExpireReservation(r); err == nil {
+		t.Fatal("expired a reservation before its TTL passed")
+	}
+
+	r.ExpiresAt = time.Now().Add(-time.Second)
+	if err := p.ExpireReservation(r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Status != ReservationExpired || p.Available() != 10 {
+		t.Errorf("status %s, available %d; want %s, 10", r.Status, p.Available(), ReservationExpired)
+	}
+	if err := p.ExpireReservation(r); !errors.Is(err, ErrReservationClosed) {
+		t.Errorf("expiring twice: err = %v, want %v", err, ErrReservationClosed)
+	}
+}
@@ -0,0 +1,111 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/product"
+	"github.com/google/uuid"
+)
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "PENDING"
+	DeliverySucceeded DeliveryStatus = "SUCCEEDED"
+	DeliveryDead      DeliveryStatus = "DEAD"
+)
+
+// Envelope is the JSON body posted to subscribers. ID is stable across
+// retries and redeliveries so receivers can deduplicate.
+type Envelope struct {
+	ID         uuid.UUID       `json:"id"`
+	Type       string          `json:"type"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// Delivery is one event to be sent to one subscription
+type Delivery struct {
+	ID             uuid.UUID       `json:"id"`
+	TenantID       string          `json:"-"`
+	SubscriptionID uuid.UUID       `json:"subscription_id"`
+	EventID        uuid.UUID       `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"-"`
+	Status         DeliveryStatus  `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastError      string          `json:"last_error,omitempty"`
+	LastStatusCode int             `json:"last_status_code,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+}
+
+// Factory method
+func NewDelivery(subscriptionID uuid.UUID, event product.StoredEvent) (*Delivery, error) {
+	payload, err := json.Marshal(Envelope{
+		ID:         event.ID,
+		Type:       event.Type,
+		OccurredAt: event.OccurredAt,
+		Data:       event.Payload,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	return &Delivery{
+		ID:             uuid.New(),
+		TenantID:       event.TenantID,
+		SubscriptionID: subscriptionID,
+		EventID:        event.ID,
+		EventType:      event.Type,
+		Payload:        payload,
+		Status:         DeliveryPending,
+		NextAttemptAt:  now,
+		CreatedAt:      now,
+	}, nil
+}
+
+// Business methods
+func (d *Delivery) Succeed(statusCode int) {
+	now := time.Now().UTC()
+	d.Attempts++
+	d.Status = DeliverySucceeded
+	d.LastStatusCode = statusCode
+	d.LastError = ""
+	d.DeliveredAt = &now
+}
+
+// Fail records a failed attempt. The delivery is retried after retryIn, or
+// dead-lettered once maxAttempts have been used.
+func (d *Delivery) Fail(statusCode int, reason string, maxAttempts int, retryIn time.Duration) {
+	d.Attempts++
+	d.LastStatusCode = statusCode
+	d.LastError = reason
+	if d.Attempts >= maxAttempts {
+		d.Status = DeliveryDead
+		return
+	}
+	d.NextAttemptAt = time.Now().UTC().Add(retryIn)
+}
+
+// Kill dead-letters the delivery immediately
+func (d *Delivery) Kill(reason string) {
+	d.Status = DeliveryDead
+	d.LastError = reason
+}
+
+// Redeliver queues the delivery again with a fresh attempt budget
+func (d *Delivery) Redeliver() error {
+	if d.Status == DeliveryPending {
+		return errors.New("delivery is already pending")
+	}
+	d.Status = DeliveryPending
+	d.Attempts = 0
+	d.NextAttemptAt = time.Now().UTC()
+	d.DeliveredAt = nil
+	return nil
+}
@@ -0,0 +1,36 @@
+package webhook
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Repository persists subscriptions and their deliveries
+type Repository interface {
+	SaveSubscription(ctx context.Context, subscription *Subscription) error
+	UpdateSubscription(ctx context.Context, subscription *Subscription) error
+	DeleteSubscription(ctx context.Context, id uuid.UUID) error
+	GetSubscription(ctx context.Context, id uuid.UUID) (*Subscription, error)
+	ListSubscriptions(ctx context.Context) ([]Subscription, error)
+
+	GetDelivery(ctx context.Context, id uuid.UUID) (*Delivery, error)
+	ListDeliveries(ctx context.Context, filter DeliveryFilter) ([]Delivery, error)
+	UpdateDelivery(ctx context.Context, delivery *Delivery) error
+	// FanOut turns up to limit new product events into deliveries for every
+	// matching subscription and advances the consumer offset atomically.
+	// It returns the number of events consumed.
+	FanOut(ctx context.Context, limit int) (int, error)
+	// ClaimDue leases up to limit pending deliveries whose next attempt is
+	// due, hiding them from other workers for the lease duration.
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]Delivery, error)
+}
+
+// DeliveryFilter represents query filters for deliveries
+type DeliveryFilter struct {
+	SubscriptionID uuid.UUID
+	Status         *DeliveryStatus
+	PageSize       int
+	PageNumber     int
+}
@@ -0,0 +1,109 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/product"
+	"github.com/google/uuid"
+)
+
+// minSecretLength is the minimum length of a caller supplied shared secret
+const minSecretLength = 16
+
+// Subscription registers a partner endpoint for product lifecycle events.
+// An empty EventTypes list subscribes to every event.
+type Subscription struct {
+	ID          uuid.UUID `json:"id"`
+	TenantID    string    `json:"-"`
+	URL         string    `json:"url"`
+	Description string    `json:"description"`
+	EventTypes  []string  `json:"event_types"`
+	Secret      string    `json:"-"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Factory method. A random secret is generated when none is given.
+func NewSubscription(endpoint, description string, eventTypes []string, secret string) (*Subscription, error) {
+	if secret == "" {
+		var err error
+		if secret, err = generateSecret(); err != nil {
+			return nil, err
+		}
+	} else if len(secret) < minSecretLength {
+		return nil, fmt.Errorf("secret must be at least %d characters", minSecretLength)
+	}
+
+	now := time.Now().UTC()
+	s := &Subscription{
+		ID:        uuid.New(),
+		Secret:    secret,
+		Active:    true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.Change(endpoint, description, eventTypes, true); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Business methods
+func (s *Subscription) Change(endpoint, description string, eventTypes []string, active bool) error {
+	if err := validateEndpoint(endpoint); err != nil {
+		return err
+	}
+	for _, eventType := range eventTypes {
+		if !slices.Contains(product.EventNames, eventType) {
+			return fmt.Errorf("unknown event type %q", eventType)
+		}
+	}
+
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+
+	s.URL = endpoint
+	s.Description = description
+	s.EventTypes = eventTypes
+	s.Active = active
+	s.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// Matches reports whether the subscription wants the given event type
+func (s *Subscription) Matches(eventType string) bool {
+	if !s.Active {
+		return false
+	}
+	return len(s.EventTypes) == 0 || slices.Contains(s.EventTypes, eventType)
+}
+
+func validateEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.New("url must use http or https")
+	}
+	if u.Host == "" {
+		return errors.New("url must be absolute")
+	}
+	return nil
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
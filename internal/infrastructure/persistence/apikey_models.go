@@ -0,0 +1,74 @@
+package persistence
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/apikey"
+	"github.com/google/uuid"
+)
+
+// APIKeyModel is the GORM model for API keys
+type APIKeyModel struct {
+	ID            uuid.UUID `gorm:"type:uuid;primary_key"`
+	TenantID      string    `gorm:"not null;size:64;index"`
+	Name          string    `gorm:"not null"`
+	Prefix        string    `gorm:"not null;size:32;uniqueIndex"`
+	Hash          string    `gorm:"not null;size:64"`
+	Scopes        string    `gorm:"type:jsonb;not null;default:'[]'"`
+	RateLimitTier string    `gorm:"not null;size:50"`
+	CreatedBy     string
+	CreatedAt     time.Time `gorm:"not null"`
+	ExpiresAt     *time.Time
+	LastUsedAt    *time.Time
+	RevokedAt     *time.Time
+}
+
+// TableName overrides the table name
+func (APIKeyModel) TableName() string {
+	return "api_keys"
+}
+
+func (m *APIKeyModel) ToDomain() (*apikey.APIKey, error) {
+	scopes := []string{}
+	if err := json.Unmarshal([]byte(m.Scopes), &scopes); err != nil {
+		return nil, err
+	}
+
+	return &apikey.APIKey{
+		ID:            m.ID,
+		TenantID:      m.TenantID,
+		Name:          m.Name,
+		Prefix:        m.Prefix,
+		Hash:          m.Hash,
+		Scopes:        scopes,
+		RateLimitTier: m.RateLimitTier,
+		CreatedBy:     m.CreatedBy,
+		CreatedAt:     m.CreatedAt,
+		ExpiresAt:     m.ExpiresAt,
+		LastUsedAt:    m.LastUsedAt,
+		RevokedAt:     m.RevokedAt,
+	}, nil
+}
+
+func APIKeyFromDomain(k *apikey.APIKey) (*APIKeyModel, error) {
+	scopes, err := json.Marshal(k.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &APIKeyModel{
+		ID:            k.ID,
+		TenantID:      k.TenantID,
+		Name:          k.Name,
+		Prefix:        k.Prefix,
+		Hash:          k.Hash,
+		Scopes:        string(scopes),
+		RateLimitTier: k.RateLimitTier,
+		CreatedBy:     k.CreatedBy,
+		CreatedAt:     k.CreatedAt,
+		ExpiresAt:     k.ExpiresAt,
+		LastUsedAt:    k.LastUsedAt,
+		RevokedAt:     k.RevokedAt,
+	}, nil
+}
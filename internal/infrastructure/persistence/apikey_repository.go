@@ -0,0 +1,122 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/apikey"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{
+		db: db,
+	}
+}
+
+func (r *APIKeyRepository) Save(ctx context.Context, k *apikey.APIKey) (err error) {
+	defer observe("api_keys", "Save", time.Now(), &err)
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+	k.TenantID = tenantID
+
+	model, err := APIKeyFromDomain(k)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	if err := r.db.WithContext(ctx).Create(model).Error; err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return nil
+}
+
+func (r *APIKeyRepository) GetByID(ctx context.Context, id uuid.UUID) (_ *apikey.APIKey, err error) {
+	defer observe("api_keys", "GetByID", time.Now(), &err)
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var model APIKeyModel
+	if err := r.db.WithContext(ctx).First(&model, "id = ? AND tenant_id = ?", id, tenantID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fiber.NewError(fiber.StatusNotFound, apikey.ErrNotFound.Error())
+		}
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return model.ToDomain()
+}
+
+func (r *APIKeyRepository) List(ctx context.Context) (_ []apikey.APIKey, err error) {
+	defer observe("api_keys", "List", time.Now(), &err)
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var models []APIKeyModel
+	if err := r.db.WithContext(ctx).Where("tenant_id = ?", tenantID).Order("created_at").Find(&models).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	keys := make([]apikey.APIKey, len(models))
+	for i := range models {
+		k, err := models[i].ToDomain()
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		keys[i] = *k
+	}
+
+	return keys, nil
+}
+
+func (r *APIKeyRepository) Revoke(ctx context.Context, k *apikey.APIKey) (err error) {
+	defer observe("api_keys", "Revoke", time.Now(), &err)
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	result := r.db.WithContext(ctx).Model(&APIKeyModel{}).
+		Where("id = ? AND tenant_id = ? AND revoked_at IS NULL", k.ID, tenantID).
+		Update("revoked_at", k.RevokedAt)
+	if result.Error != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, result.Error.Error())
+	}
+
+	if result.RowsAffected == 0 {
+		return fiber.NewError(fiber.StatusConflict, apikey.ErrAlreadyRevoked.Error())
+	}
+
+	return nil
+}
+
+func (r *APIKeyRepository) FindByPrefix(ctx context.Context, prefix string) (_ *apikey.APIKey, err error) {
+	defer observe("api_keys", "FindByPrefix", time.Now(), &err)
+	var model APIKeyModel
+	if err := r.db.WithContext(ctx).First(&model, "prefix = ?", prefix).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apikey.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return model.ToDomain()
+}
+
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time, interval time.Duration) (err error) {
+	defer observe("api_keys", "TouchLastUsed", time.Now(), &err)
+	return r.db.WithContext(ctx).Model(&APIKeyModel{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, at.Add(-interval)).
+		Update("last_used_at", at).Error
+}
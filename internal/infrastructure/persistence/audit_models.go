@@ -0,0 +1,68 @@
+package persistence
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/audit"
+	"github.com/google/uuid"
+)
+
+// AuditEntryModel is the GORM model for audit entries
+type AuditEntryModel struct {
+	ID            uuid.UUID `gorm:"type:uuid;primary_key"`
+	TenantID      string    `gorm:"not null;size:64"`
+	Actor         string    `gorm:"not null"`
+	CommandType   string    `gorm:"not null;size:100"`
+	AggregateType string    `gorm:"not null;size:50"`
+	AggregateID   uuid.UUID `gorm:"type:uuid;not null"`
+	Changes       string    `gorm:"type:jsonb;not null;default:'{}'"`
+	TraceID       string    `gorm:"size:32"`
+	ClientIP      string    `gorm:"size:45"`
+	OccurredAt    time.Time `gorm:"not null"`
+}
+
+// TableName overrides the table name
+func (AuditEntryModel) TableName() string {
+	return "audit_entries"
+}
+
+func (m *AuditEntryModel) ToDomain() (*audit.Entry, error) {
+	changes := map[string]audit.Change{}
+	if err := json.Unmarshal([]byte(m.Changes), &changes); err != nil {
+		return nil, err
+	}
+
+	return &audit.Entry{
+		ID:            m.ID,
+		TenantID:      m.TenantID,
+		Actor:         m.Actor,
+		CommandType:   m.CommandType,
+		AggregateType: m.AggregateType,
+		AggregateID:   m.AggregateID,
+		Changes:       changes,
+		TraceID:       m.TraceID,
+		ClientIP:      m.ClientIP,
+		OccurredAt:    m.OccurredAt,
+	}, nil
+}
+
+func AuditEntryFromDomain(e *audit.Entry) (*AuditEntryModel, error) {
+	changes, err := json.Marshal(e.Changes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuditEntryModel{
+		ID:            e.ID,
+		TenantID:      e.TenantID,
+		Actor:         e.Actor,
+		CommandType:   e.CommandType,
+		AggregateType: e.AggregateType,
+		AggregateID:   e.AggregateID,
+		Changes:       string(changes),
+		TraceID:       e.TraceID,
+		ClientIP:      e.ClientIP,
+		OccurredAt:    e.OccurredAt,
+	}, nil
+}
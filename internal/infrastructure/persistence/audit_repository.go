@@ -0,0 +1,76 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/audit"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// AuditRepository only ever inserts; the table itself rejects updates and
+// deletes
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{
+		db: db,
+	}
+}
+
+func (r *AuditRepository) Append(ctx context.Context, e *audit.Entry) (err error) {
+	defer observe("audit", "Append", time.Now(), &err)
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+	e.TenantID = tenantID
+
+	model, err := AuditEntryFromDomain(e)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	if err := r.db.WithContext(ctx).Create(model).Error; err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return nil
+}
+
+func (r *AuditRepository) List(ctx context.Context, filter audit.Filter) (_ []audit.Entry, _ int64, err error) {
+	defer observe("audit", "List", time.Now(), &err)
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := r.db.WithContext(ctx).Model(&AuditEntryModel{}).
+		Where("tenant_id = ? AND aggregate_type = ? AND aggregate_id = ?", tenantID, filter.AggregateType, filter.AggregateID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	if filter.PageSize > 0 {
+		query = query.Offset(filter.PageSize * filter.PageNumber).Limit(filter.PageSize)
+	}
+
+	var models []AuditEntryModel
+	if err := query.Order("occurred_at DESC, id").Find(&models).Error; err != nil {
+		return nil, 0, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	entries := make([]audit.Entry, len(models))
+	for i := range models {
+		e, err := models[i].ToDomain()
+		if err != nil {
+			return nil, 0, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		entries[i] = *e
+	}
+
+	return entries, total, nil
+}
@@ -0,0 +1,119 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/product"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ProductEventModel is a row of the append-only product event log. It is
+// written in the same transaction as the aggregate change (transactional
+// outbox) and read by consumers such as webhook delivery.
+type ProductEventModel struct {
+	Position   int64     `gorm:"primaryKey;autoIncrement"`
+	EventID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex"`
+	TenantID   string    `gorm:"not null;size:64;default:'default'"`
+	ProductID  uuid.UUID `gorm:"type:uuid;not null;index"`
+	EventType  string    `gorm:"not null;size:50"`
+	Payload    string    `gorm:"type:jsonb;not null"`
+	OccurredAt time.Time `gorm:"not null"`
+}
+
+// TableName overrides the table name
+func (ProductEventModel) TableName() string {
+	return "product_events"
+}
+
+func (m *ProductEventModel) ToDomain() product.StoredEvent {
+	return product.StoredEvent{
+		Position:   m.Position,
+		ID:         m.EventID,
+		TenantID:   m.TenantID,
+		ProductID:  m.ProductID,
+		Type:       m.EventType,
+		Payload:    json.RawMessage(m.Payload),
+		OccurredAt: m.OccurredAt,
+	}
+}
+
+// eventLogLockKey is the advisory lock serializing appends to the event log
+const eventLogLockKey = 7301
+
+// appendEvents writes a tenant's domain events to the event log using the given transaction.
+// Appends are serialized with a transaction scoped advisory lock so positions
+// become visible in order; consumers reading "after position N" can then never
+// miss an event whose transaction committed late. It must be the last
+// statement of the transaction, so the lock is only held while positions are
+// assigned and the transaction commits, not while the aggregate is written.
+func appendEvents(tx *gorm.DB, tenantID string, events []product.ProductEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", eventLogLockKey).Error; err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	models := make([]ProductEventModel, len(events))
+	for i, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		models[i] = ProductEventModel{
+			EventID:    uuid.New(),
+			TenantID:   tenantID,
+			ProductID:  event.AggregateID(),
+			EventType:  event.EventName(),
+			Payload:    string(payload),
+			OccurredAt: now,
+		}
+	}
+
+	return tx.Create(&models).Error
+}
+
+type EventLogRepository struct {
+	db *gorm.DB
+}
+
+func NewEventLogRepository(db *gorm.DB) *EventLogRepository {
+	return &EventLogRepository{
+		db: db,
+	}
+}
+
+func (r *EventLogRepository) ReadAfter(ctx context.Context, position int64, limit int) (_ []product.StoredEvent, err error) {
+	defer observe("event_log", "ReadAfter", time.Now(), &err)
+	var models []ProductEventModel
+	if err := r.db.WithContext(ctx).
+		Where("position > ?", position).
+		Order("position").
+		Limit(limit).
+		Find(&models).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	events := make([]product.StoredEvent, len(models))
+	for i := range models {
+		events[i] = models[i].ToDomain()
+	}
+	return events, nil
+}
+
+func (r *EventLogRepository) LatestPosition(ctx context.Context) (_ int64, err error) {
+	defer observe("event_log", "LatestPosition", time.Now(), &err)
+	var position int64
+	if err := r.db.WithContext(ctx).Model(&ProductEventModel{}).
+		Select("COALESCE(MAX(position), 0)").
+		Scan(&position).Error; err != nil {
+		return 0, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return position, nil
+}
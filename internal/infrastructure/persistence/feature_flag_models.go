@@ -0,0 +1,78 @@
+package persistence
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/featureflag"
+	"github.com/google/uuid"
+)
+
+// FeatureFlagModel is a flag changed through the admin API
+type FeatureFlagModel struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key"`
+	Key         string    `gorm:"not null;size:64;uniqueIndex"`
+	Description string    `gorm:"not null;default:''"`
+	Enabled     bool      `gorm:"not null"`
+	Rollout     int       `gorm:"not null"`
+	Tenants     string    `gorm:"type:jsonb;not null;default:'[]'"`
+	Subjects    string    `gorm:"type:jsonb;not null;default:'[]'"`
+	UpdatedBy   string
+	UpdatedAt   time.Time `gorm:"not null"`
+}
+
+// TableName overrides the table name
+func (FeatureFlagModel) TableName() string {
+	return "feature_flags"
+}
+
+func (m *FeatureFlagModel) ToDomain() (*featureflag.Flag, error) {
+	tenants := []string{}
+	if err := json.Unmarshal([]byte(m.Tenants), &tenants); err != nil {
+		return nil, err
+	}
+	subjects := []string{}
+	if err := json.Unmarshal([]byte(m.Subjects), &subjects); err != nil {
+		return nil, err
+	}
+
+	updatedAt := m.UpdatedAt
+	return &featureflag.Flag{
+		ID:          m.ID,
+		Key:         m.Key,
+		Description: m.Description,
+		Enabled:     m.Enabled,
+		Rollout:     m.Rollout,
+		Tenants:     tenants,
+		Subjects:    subjects,
+		Source:      featureflag.SourceDatabase,
+		UpdatedBy:   m.UpdatedBy,
+		UpdatedAt:   &updatedAt,
+	}, nil
+}
+
+func FeatureFlagFromDomain(f *featureflag.Flag) (*FeatureFlagModel, error) {
+	tenants, err := json.Marshal(f.Tenants)
+	if err != nil {
+		return nil, err
+	}
+	subjects, err := json.Marshal(f.Subjects)
+	if err != nil {
+		return nil, err
+	}
+
+	model := &FeatureFlagModel{
+		ID:          f.ID,
+		Key:         f.Key,
+		Description: f.Description,
+		Enabled:     f.Enabled,
+		Rollout:     f.Rollout,
+		Tenants:     string(tenants),
+		Subjects:    string(subjects),
+		UpdatedBy:   f.UpdatedBy,
+	}
+	if f.UpdatedAt != nil {
+		model.UpdatedAt = *f.UpdatedAt
+	}
+	return model, nil
+}
@@ -0,0 +1,72 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/featureflag"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FeatureFlagRepository struct {
+	db *gorm.DB
+}
+
+func NewFeatureFlagRepository(db *gorm.DB) *FeatureFlagRepository {
+	return &FeatureFlagRepository{
+		db: db,
+	}
+}
+
+func (r *FeatureFlagRepository) List(ctx context.Context) (_ []featureflag.Flag, err error) {
+	defer observe("feature_flags", "List", time.Now(), &err)
+	var models []FeatureFlagModel
+	if err := r.db.WithContext(ctx).Order("key").Find(&models).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	flags := make([]featureflag.Flag, len(models))
+	for i := range models {
+		f, err := models[i].ToDomain()
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		flags[i] = *f
+	}
+
+	return flags, nil
+}
+
+func (r *FeatureFlagRepository) Save(ctx context.Context, f *featureflag.Flag) (err error) {
+	defer observe("feature_flags", "Save", time.Now(), &err)
+	now := time.Now().UTC()
+	f.UpdatedAt = &now
+
+	model, err := FeatureFlagFromDomain(f)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"description", "enabled", "rollout", "tenants", "subjects", "updated_by", "updated_at"}),
+	}).Create(model).Error; err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return nil
+}
+
+func (r *FeatureFlagRepository) Delete(ctx context.Context, key string) (err error) {
+	defer observe("feature_flags", "Delete", time.Now(), &err)
+	result := r.db.WithContext(ctx).Where("key = ?", key).Delete(&FeatureFlagModel{})
+	if result.Error != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, result.Error.Error())
+	}
+
+	if result.RowsAffected == 0 {
+		return fiber.NewError(fiber.StatusNotFound, featureflag.ErrNotChanged.Error())
+	}
+
+	return nil
+}
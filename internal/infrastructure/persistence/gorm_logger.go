@@ -4,61 +4,141 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 	gormlogger "gorm.io/gorm/logger"
 )
 
+var slowQueries = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "gorm_slow_queries_total",
+	Help: "Queries slower than the configured threshold, by SQL statement type.",
+}, []string{"statement"})
+
 type GormZapLogger struct {
 	logger        *zap.Logger
+	level         gormlogger.LogLevel
 	slowThreshold time.Duration
+	redactParams  bool
 }
 
-func NewGormZapLogger(zapLogger *zap.Logger) gormlogger.Interface {
+func NewGormZapLogger(zapLogger *zap.Logger, cfg config.DatabaseConfig) (*GormZapLogger, error) {
+	level, err := parseLogLevel(cfg.LogLevel)
+	if err != nil {
+		return nil, err
+	}
+
 	return &GormZapLogger{
 		logger:        zapLogger,
-		slowThreshold: 200 * time.Millisecond,
+		level:         level,
+		slowThreshold: cfg.SlowThreshold,
+		redactParams:  cfg.RedactParams,
+	}, nil
+}
+
+func parseLogLevel(level string) (gormlogger.LogLevel, error) {
+	switch strings.ToLower(level) {
+	case "silent":
+		return gormlogger.Silent, nil
+	case "error":
+		return gormlogger.Error, nil
+	case "warn", "":
+		return gormlogger.Warn, nil
+	case "info":
+		return gormlogger.Info, nil
 	}
+	return 0, fmt.Errorf("unknown database log level %q", level)
 }
 
+// LogMode returns a copy logging at level, e.g. for db.Debug()
 func (l *GormZapLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
-	return l
+	copied := *l
+	copied.level = level
+	return &copied
 }
 
 func (l *GormZapLogger) Info(ctx context.Context, msg string, data ...interface{}) {
-	l.logger.Info(fmt.Sprintf(msg, data...))
+	if l.level >= gormlogger.Info {
+		l.logger.Info(fmt.Sprintf(msg, data...), logger.GetTraceFields(ctx)...)
+	}
 }
 
 func (l *GormZapLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
-	l.logger.Warn(fmt.Sprintf(msg, data...))
+	if l.level >= gormlogger.Warn {
+		l.logger.Warn(fmt.Sprintf(msg, data...), logger.GetTraceFields(ctx)...)
+	}
 }
 
 func (l *GormZapLogger) Error(ctx context.Context, msg string, data ...interface{}) {
-	l.logger.Error(fmt.Sprintf(msg, data...))
+	if l.level >= gormlogger.Error {
+		l.logger.Error(fmt.Sprintf(msg, data...), logger.GetTraceFields(ctx)...)
+	}
+}
+
+// ParamsFilter masks bound values when redaction is enabled. GORM calls it
+// through gorm.ParamsFilter before rendering the SQL that Trace logs.
+func (l *GormZapLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	if !l.redactParams {
+		return sql, params
+	}
+	redacted := make([]interface{}, len(params))
+	for i := range redacted {
+		redacted[i] = "[redacted]"
+	}
+	return sql, redacted
 }
 
 func (l *GormZapLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
 	elapsed := time.Since(begin)
-	sql, rows := fc()
+	failed := err != nil && !errors.Is(err, gorm.ErrRecordNotFound)
+	slow := l.slowThreshold > 0 && elapsed > l.slowThreshold
 
-	fields := []zap.Field{
-		zap.String("sql", sql),
-		zap.Int64("rows", rows),
-		zap.Duration("elapsed", elapsed),
+	// Slow queries are counted even when they are not logged
+	var sql string
+	var rows int64
+	if slow {
+		sql, rows = fc()
+		slowQueries.WithLabelValues(statementType(sql)).Inc()
 	}
 
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		fields = append(fields, zap.Error(err))
-		l.logger.Error("gorm-trace", fields...)
+	logged := failed && l.level >= gormlogger.Error ||
+		slow && l.level >= gormlogger.Warn ||
+		l.level >= gormlogger.Info
+	if !logged {
 		return
 	}
 
-	if elapsed > l.slowThreshold {
-		l.logger.Warn("gorm-trace-slow-query", fields...)
-		return
+	if !slow {
+		sql, rows = fc()
 	}
+	fields := append(logger.GetTraceFields(ctx),
+		zap.String("sql", sql),
+		zap.Int64("rows", rows),
+		zap.Duration("elapsed", elapsed),
+	)
 
-	l.logger.Debug("gorm-trace", fields...)
+	switch {
+	case failed:
+		l.logger.Error("gorm-trace", append(fields, zap.Error(err))...)
+	case slow:
+		l.logger.Warn("gorm-trace-slow-query", append(fields, zap.Duration("threshold", l.slowThreshold))...)
+	default:
+		l.logger.Info("gorm-trace", fields...)
+	}
+}
+
+// statementType is the SQL verb of a statement, which bounds the metric labels
+func statementType(sql string) string {
+	verb, _, _ := strings.Cut(strings.TrimSpace(sql), " ")
+	switch verb = strings.ToUpper(verb); verb {
+	case "SELECT", "INSERT", "UPDATE", "DELETE", "WITH":
+		return strings.ToLower(verb)
+	}
+	return "other"
 }
@@ -9,15 +9,17 @@ import (
 // ProductModel is the GORM model for Product aggregate
 type ProductModel struct {
 	gorm.Model
-	ID          uuid.UUID `gorm:"type:uuid;primary_key"`
-	Name        string    `gorm:"not null"`
-	Description string
-	PriceAmount float64               `gorm:"not null"`
-	Currency    string                `gorm:"not null;size:3"`
-	StockLevel  int                   `gorm:"not null"`
-	StockUnit   string                `gorm:"not null"`
-	Status      product.ProductStatus `gorm:"not null"`
-	Version     int                   `gorm:"not null"`
+	ID            uuid.UUID `gorm:"type:uuid;primary_key"`
+	TenantID      string    `gorm:"not null;size:64;default:'default';index"`
+	Name          string    `gorm:"not null"`
+	Description   string
+	PriceAmount   float64               `gorm:"not null"`
+	Currency      string                `gorm:"not null;size:3"`
+	StockLevel    int                   `gorm:"not null"`
+	StockUnit     string                `gorm:"not null"`
+	ReservedStock int                   `gorm:"not null;default:0"`
+	Status        product.ProductStatus `gorm:"not null"`
+	Version       int                   `gorm:"not null"`
 }
 
 // TableName overrides the table name
@@ -48,8 +50,14 @@ func (p *ProductModel) ToDomain() (*product.Product, error) {
 	// Note: This assumes we have access to these setters in the domain model
 	// You might need to add these methods to your domain model
 	prod.SetID(p.ID)
+	prod.SetTenantID(p.TenantID)
 	prod.SetStatus(p.Status)
 	prod.SetVersion(p.Version)
+	prod.SetReservedStock(p.ReservedStock)
+
+	// Loading an existing product is not a business event
+	prod.ClearEvents()
+	prod.ClearMovements()
 
 	return prod, nil
 }
@@ -57,14 +65,16 @@ func (p *ProductModel) ToDomain() (*product.Product, error) {
 // FromDomain creates a GORM model from domain model
 func FromDomain(p *product.Product) *ProductModel {
 	return &ProductModel{
-		ID:          p.ID(),
-		Name:        p.Name(),
-		Description: p.Description(),
-		PriceAmount: p.Price(),
-		Currency:    p.Currency(),
-		StockLevel:  p.Stock(),
-		StockUnit:   p.StockUnit(),
-		Status:      p.Status(),
-		Version:     p.Version(),
+		ID:            p.ID(),
+		TenantID:      p.TenantID(),
+		Name:          p.Name(),
+		Description:   p.Description(),
+		PriceAmount:   p.Price(),
+		Currency:      p.Currency(),
+		StockLevel:    p.Stock(),
+		StockUnit:     p.StockUnit(),
+		ReservedStock: p.Reserved(),
+		Status:        p.Status(),
+		Version:       p.Version(),
 	}
 }
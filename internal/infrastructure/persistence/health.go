@@ -0,0 +1,129 @@
+package persistence
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/health"
+	"gorm.io/gorm"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// HealthChecks probes the database, its connection pool, the applied schema
+// and how far webhook fan-out lags behind the event log (the outbox)
+func HealthChecks(db *gorm.DB, cfg config.HealthConfig) []health.Check {
+	return []health.Check{
+		{
+			Name:     "database",
+			Critical: true,
+			Run: func(ctx context.Context) (any, error) {
+				sqlDB, err := db.DB()
+				if err != nil {
+					return nil, err
+				}
+				return nil, sqlDB.PingContext(ctx)
+			},
+		},
+		{
+			Name: "database_pool",
+			Run: func(ctx context.Context) (any, error) {
+				sqlDB, err := db.DB()
+				if err != nil {
+					return nil, err
+				}
+				stats := sqlDB.Stats()
+				details := map[string]interface{}{
+					"open":          stats.OpenConnections,
+					"in_use":        stats.InUse,
+					"idle":          stats.Idle,
+					"max_open":      stats.MaxOpenConnections,
+					"wait_count":    stats.WaitCount,
+					"wait_duration": stats.WaitDuration.String(),
+				}
+				if stats.MaxOpenConnections > 0 && float64(stats.InUse)/float64(stats.MaxOpenConnections) >= cfg.PoolSaturation {
+					return details, fmt.Errorf("%d of %d connections in use", stats.InUse, stats.MaxOpenConnections)
+				}
+				return details, nil
+			},
+		},
+		{
+			Name:     "migrations",
+			Critical: true,
+			Run: func(ctx context.Context) (any, error) {
+				return pendingMigrations(ctx, db)
+			},
+		},
+		{
+			Name: "outbox_lag",
+			Run: func(ctx context.Context) (any, error) {
+				var lag int64
+				if err := db.WithContext(ctx).Raw(`
+					SELECT COALESCE((SELECT MAX(position) FROM product_events), 0)
+						- COALESCE((SELECT position FROM event_consumer_offsets WHERE consumer = ?), 0)`,
+					webhookConsumer,
+				).Scan(&lag).Error; err != nil {
+					return nil, err
+				}
+				details := map[string]interface{}{"consumer": webhookConsumer, "lag": lag}
+				if lag > cfg.OutboxLag {
+					return details, fmt.Errorf("%d events behind", lag)
+				}
+				return details, nil
+			},
+		},
+	}
+}
+
+// pendingMigrations compares the embedded goose migrations with those applied.
+// Databases that were never migrated with goose are only set up by
+// AutoMigrate and have nothing pending.
+func pendingMigrations(ctx context.Context, db *gorm.DB) (any, error) {
+	var managed bool
+	if err := db.WithContext(ctx).Raw("SELECT to_regclass('goose_db_version') IS NOT NULL").Scan(&managed).Error; err != nil {
+		return nil, err
+	}
+	if !managed {
+		return map[string]interface{}{"managed_by": "automigrate"}, nil
+	}
+
+	var applied []int64
+	if err := db.WithContext(ctx).Raw("SELECT version_id FROM goose_db_version WHERE is_applied").Scan(&applied).Error; err != nil {
+		return nil, err
+	}
+	isApplied := make(map[int64]bool, len(applied))
+	for _, version := range applied {
+		isApplied[version] = true
+	}
+
+	files, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+	var pending []string
+	for _, file := range files {
+		name := strings.TrimPrefix(file, "migrations/")
+		prefix, _, _ := strings.Cut(name, "_")
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			continue
+		}
+		if !isApplied[version] {
+			pending = append(pending, name)
+		}
+	}
+	sort.Strings(pending)
+
+	details := map[string]interface{}{"managed_by": "goose", "available": len(files), "pending": pending}
+	if len(pending) > 0 {
+		return details, fmt.Errorf("%d pending migrations", len(pending))
+	}
+	return details, nil
+}
@@ -0,0 +1,101 @@
+package persistence
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/job"
+	"github.com/google/uuid"
+)
+
+// JobModel is the GORM model for background jobs
+type JobModel struct {
+	ID              uuid.UUID  `gorm:"type:uuid;primary_key"`
+	TenantID        string     `gorm:"not null;size:64;default:'default'"`
+	Type            job.Type   `gorm:"not null;size:50"`
+	Status          job.Status `gorm:"not null;size:20;index"`
+	Payload         string     `gorm:"type:jsonb;not null"`
+	Cursor          string
+	Total           int    `gorm:"not null;default:0"`
+	Processed       int    `gorm:"not null;default:0"`
+	Succeeded       int    `gorm:"not null;default:0"`
+	Failed          int    `gorm:"not null;default:0"`
+	Errors          string `gorm:"type:jsonb;not null;default:'[]'"`
+	Error           string
+	CancelRequested bool `gorm:"not null;default:false"`
+	Attempts        int  `gorm:"not null;default:0"`
+	LockedBy        string
+	LockedUntil     *time.Time
+	SubmittedBy     string    `gorm:"size:255"`
+	CreatedAt       time.Time `gorm:"not null;index"`
+	UpdatedAt       time.Time
+	StartedAt       *time.Time
+	FinishedAt      *time.Time
+}
+
+// TableName overrides the table name
+func (JobModel) TableName() string {
+	return "jobs"
+}
+
+// ToDomain converts GORM model to domain model
+func (m *JobModel) ToDomain() (*job.Job, error) {
+	itemErrors := []job.ItemError{}
+	if m.Errors != "" {
+		if err := json.Unmarshal([]byte(m.Errors), &itemErrors); err != nil {
+			return nil, err
+		}
+	}
+
+	return &job.Job{
+		ID:              m.ID,
+		TenantID:        m.TenantID,
+		Type:            m.Type,
+		Status:          m.Status,
+		Payload:         json.RawMessage(m.Payload),
+		Cursor:          m.Cursor,
+		Total:           m.Total,
+		Processed:       m.Processed,
+		Succeeded:       m.Succeeded,
+		Failed:          m.Failed,
+		Errors:          itemErrors,
+		Error:           m.Error,
+		CancelRequested: m.CancelRequested,
+		Attempts:        m.Attempts,
+		LockedBy:        m.LockedBy,
+		SubmittedBy:     m.SubmittedBy,
+		CreatedAt:       m.CreatedAt,
+		StartedAt:       m.StartedAt,
+		FinishedAt:      m.FinishedAt,
+	}, nil
+}
+
+// JobFromDomain creates a GORM model from domain model
+func JobFromDomain(j *job.Job) (*JobModel, error) {
+	itemErrors, err := json.Marshal(j.Errors)
+	if err != nil {
+		return nil, err
+	}
+
+	return &JobModel{
+		ID:              j.ID,
+		TenantID:        j.TenantID,
+		Type:            j.Type,
+		Status:          j.Status,
+		Payload:         string(j.Payload),
+		Cursor:          j.Cursor,
+		Total:           j.Total,
+		Processed:       j.Processed,
+		Succeeded:       j.Succeeded,
+		Failed:          j.Failed,
+		Errors:          string(itemErrors),
+		Error:           j.Error,
+		CancelRequested: j.CancelRequested,
+		Attempts:        j.Attempts,
+		LockedBy:        j.LockedBy,
+		SubmittedBy:     j.SubmittedBy,
+		CreatedAt:       j.CreatedAt,
+		StartedAt:       j.StartedAt,
+		FinishedAt:      j.FinishedAt,
+	}, nil
+}
@@ -0,0 +1,198 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/job"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type JobRepository struct {
+	db *gorm.DB
+}
+
+func NewJobRepository(db *gorm.DB) *JobRepository {
+	return &JobRepository{
+		db: db,
+	}
+}
+
+// Save stores a new job for the tenant of ctx, which the worker restores when running it
+func (r *JobRepository) Save(ctx context.Context, j *job.Job) (err error) {
+	defer observe("jobs", "Save", time.Now(), &err)
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+	j.TenantID = tenantID
+
+	model, err := JobFromDomain(j)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	if err := r.db.WithContext(ctx).Create(model).Error; err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return nil
+}
+
+func (r *JobRepository) GetByID(ctx context.Context, id uuid.UUID) (_ *job.Job, err error) {
+	defer observe("jobs", "GetByID", time.Now(), &err)
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var model JobModel
+	if err := r.db.WithContext(ctx).First(&model, "id = ? AND tenant_id = ?", id, tenantID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fiber.NewError(fiber.StatusNotFound, job.ErrNotFound.Error())
+		}
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return model.ToDomain()
+}
+
+func (r *JobRepository) ClaimNext(ctx context.Context, workerID string, lease time.Duration) (_ *job.Job, err error) {
+	defer observe("jobs", "ClaimNext", time.Now(), &err)
+	now := time.Now().UTC()
+
+	var model JobModel
+	err = r.db.WithContext(ctx).Raw(`
+		UPDATE jobs
+		SET status = ?, locked_by = ?, locked_until = ?, attempts = attempts + 1,
+			started_at = COALESCE(started_at, ?), updated_at = ?
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE status = ? OR (status = ? AND locked_until < ?)
+			ORDER BY created_at
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING *`,
+		job.StatusRunning, workerID, now.Add(lease), now, now,
+		job.StatusPending, job.StatusRunning, now,
+	).Scan(&model).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if model.ID == uuid.Nil {
+		return nil, nil
+	}
+
+	return model.ToDomain()
+}
+
+func (r *JobRepository) SaveProgress(ctx context.Context, j *job.Job, lease time.Duration) (err error) {
+	defer observe("jobs", "SaveProgress", time.Now(), &err)
+	itemErrors, err := json.Marshal(j.Errors)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	updates := map[string]interface{}{
+		"status":       j.Status,
+		"cursor":       j.Cursor,
+		"total":        j.Total,
+		"processed":    j.Processed,
+		"succeeded":    j.Succeeded,
+		"failed":       j.Failed,
+		"errors":       string(itemErrors),
+		"error":        j.Error,
+		"locked_until": now.Add(lease),
+		"updated_at":   now,
+		"finished_at":  j.FinishedAt,
+	}
+	if j.IsFinished() {
+		updates["locked_by"] = ""
+		updates["locked_until"] = nil
+	}
+
+	var model JobModel
+	result := r.db.WithContext(ctx).Model(&model).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "cancel_requested"}}}).
+		Where("id = ? AND locked_by = ?", j.ID, j.LockedBy).
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return job.ErrLeaseLost
+	}
+
+	j.CancelRequested = model.CancelRequested
+	return nil
+}
+
+func (r *JobRepository) Release(ctx context.Context, j *job.Job) (err error) {
+	defer observe("jobs", "Release", time.Now(), &err)
+	result := r.db.WithContext(ctx).Model(&JobModel{}).
+		Where("id = ? AND locked_by = ? AND status = ?", j.ID, j.LockedBy, job.StatusRunning).
+		Updates(map[string]interface{}{
+			"status":       job.StatusPending,
+			"locked_by":    "",
+			"locked_until": nil,
+			"attempts":     gorm.Expr("GREATEST(attempts - 1, 0)"),
+			"updated_at":   time.Now().UTC(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return job.ErrLeaseLost
+	}
+
+	return nil
+}
+
+func (r *JobRepository) RequestCancel(ctx context.Context, id uuid.UUID) (_ *job.Job, err error) {
+	defer observe("jobs", "RequestCancel", time.Now(), &err)
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var model JobModel
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&model, "id = ? AND tenant_id = ?", id, tenantID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fiber.NewError(fiber.StatusNotFound, job.ErrNotFound.Error())
+			}
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		updates := map[string]interface{}{"cancel_requested": true, "updated_at": now}
+		switch model.Status {
+		case job.StatusPending:
+			// Nobody is working on it, so it can be cancelled right away
+			updates["status"] = job.StatusCancelled
+			updates["finished_at"] = now
+		case job.StatusRunning:
+			// The owning worker observes the flag at its next checkpoint
+		default:
+			return fiber.NewError(fiber.StatusConflict, job.ErrAlreadyFinished.Error())
+		}
+
+		if err := tx.Model(&model).Updates(updates).Error; err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, id)
+}
@@ -0,0 +1,52 @@
+package persistence
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	repositoryCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "repository_calls_total",
+		Help: "Repository method calls by outcome.",
+	}, []string{"repository", "method", "outcome"})
+
+	repositoryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "repository_call_duration_seconds",
+		Help:    "Duration of repository method calls.",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"repository", "method"})
+
+	optimisticLockConflicts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "optimistic_lock_conflicts_total",
+		Help: "Updates rejected because the aggregate was modified concurrently.",
+	}, []string{"aggregate"})
+)
+
+// observe records a repository call. Defer it first thing in the method with
+// a pointer to its named error result.
+func observe(repository, method string, start time.Time, err *error) {
+	repositoryDuration.WithLabelValues(repository, method).Observe(time.Since(start).Seconds())
+	repositoryCalls.WithLabelValues(repository, method, outcome(*err)).Inc()
+}
+
+// outcome maps an error onto a small, fixed set of label values
+func outcome(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		switch fiberErr.Code {
+		case fiber.StatusNotFound:
+			return "not_found"
+		case fiber.StatusConflict:
+			return "conflict"
+		}
+	}
+	return "error"
+}
@@ -0,0 +1,30 @@
+-- +goose Up
+ALTER TABLE products ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+CREATE INDEX idx_products_tenant_id ON products(tenant_id);
+
+ALTER TABLE product_events ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE jobs ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+
+ALTER TABLE webhook_subscriptions ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+CREATE INDEX idx_webhook_subscriptions_tenant_id ON webhook_subscriptions(tenant_id);
+ALTER TABLE webhook_deliveries ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+
+-- Row level security for tenancy.rowlevelsecurity. The application sets
+-- app.tenant_id per transaction; table owners and BYPASSRLS roles are not
+-- subject to the policy, so that mode needs a dedicated application role.
+ALTER TABLE products ENABLE ROW LEVEL SECURITY;
+CREATE POLICY products_tenant_isolation ON products
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
+
+-- +goose Down
+DROP POLICY IF EXISTS products_tenant_isolation ON products;
+ALTER TABLE products DISABLE ROW LEVEL SECURITY;
+
+ALTER TABLE webhook_deliveries DROP COLUMN tenant_id;
+DROP INDEX IF EXISTS idx_webhook_subscriptions_tenant_id;
+ALTER TABLE webhook_subscriptions DROP COLUMN tenant_id;
+ALTER TABLE jobs DROP COLUMN tenant_id;
+ALTER TABLE product_events DROP COLUMN tenant_id;
+DROP INDEX IF EXISTS idx_products_tenant_id;
+ALTER TABLE products DROP COLUMN tenant_id;
@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE api_keys (
+    id UUID PRIMARY KEY,
+    tenant_id VARCHAR(64) NOT NULL,
+    name TEXT NOT NULL,
+    prefix VARCHAR(32) NOT NULL UNIQUE,
+    hash VARCHAR(64) NOT NULL,
+    scopes JSONB NOT NULL DEFAULT '[]',
+    rate_limit_tier VARCHAR(50) NOT NULL,
+    created_by TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_api_keys_tenant_id ON api_keys(tenant_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,36 @@
+-- +goose Up
+CREATE TABLE audit_entries (
+    id UUID PRIMARY KEY,
+    tenant_id VARCHAR(64) NOT NULL,
+    actor TEXT NOT NULL,
+    command_type VARCHAR(100) NOT NULL,
+    aggregate_type VARCHAR(50) NOT NULL,
+    aggregate_id UUID NOT NULL,
+    changes JSONB NOT NULL DEFAULT '{}',
+    trace_id VARCHAR(32),
+    client_ip VARCHAR(45),
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_entries_aggregate ON audit_entries(tenant_id, aggregate_type, aggregate_id, occurred_at DESC);
+
+-- Audit entries are append only
+-- +goose StatementBegin
+CREATE FUNCTION reject_audit_entry_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit entries are append only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER audit_entries_append_only
+    BEFORE UPDATE OR DELETE ON audit_entries
+    FOR EACH ROW EXECUTE FUNCTION reject_audit_entry_change();
+
+CREATE TRIGGER audit_entries_no_truncate
+    BEFORE TRUNCATE ON audit_entries
+    FOR EACH STATEMENT EXECUTE FUNCTION reject_audit_entry_change();
+
+-- +goose Down
+DROP TABLE IF EXISTS audit_entries;
+DROP FUNCTION IF EXISTS reject_audit_entry_change();
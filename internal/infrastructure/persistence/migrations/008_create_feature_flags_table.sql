@@ -0,0 +1,16 @@
+-- +goose Up
+-- Flags changed through the admin API; they take precedence over the config file
+CREATE TABLE feature_flags (
+    id UUID PRIMARY KEY,
+    key VARCHAR(64) NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL,
+    rollout INTEGER NOT NULL CHECK (rollout BETWEEN 0 AND 100),
+    tenants JSONB NOT NULL DEFAULT '[]',
+    subjects JSONB NOT NULL DEFAULT '[]',
+    updated_by TEXT,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS feature_flags;
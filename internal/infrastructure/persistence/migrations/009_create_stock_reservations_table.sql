@@ -0,0 +1,26 @@
+-- +goose Up
+-- Units held by active reservations; stock_level - reserved_stock is available
+ALTER TABLE products ADD COLUMN reserved_stock INTEGER NOT NULL DEFAULT 0
+    CHECK (reserved_stock >= 0 AND reserved_stock <= stock_level);
+
+CREATE TABLE stock_reservations (
+    id UUID PRIMARY KEY,
+    tenant_id VARCHAR(64) NOT NULL,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    order_id VARCHAR(255) NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    status VARCHAR(20) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    closed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_stock_reservations_product_id ON stock_reservations(product_id);
+-- An order holds at most one active reservation of a product
+CREATE UNIQUE INDEX idx_stock_reservations_active_order ON stock_reservations(tenant_id, product_id, order_id) WHERE status = 'ACTIVE';
+-- Found by the expiry sweeper
+CREATE INDEX idx_stock_reservations_expires_at ON stock_reservations(expires_at) WHERE status = 'ACTIVE';
+
+-- +goose Down
+DROP TABLE IF EXISTS stock_reservations;
+ALTER TABLE products DROP COLUMN reserved_stock;
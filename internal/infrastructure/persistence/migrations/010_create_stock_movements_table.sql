@@ -0,0 +1,42 @@
+-- +goose Up
+-- Products stocked before the ledger existed get an opening balance movement
+-- with their first stock change
+CREATE TABLE stock_movements (
+    position BIGSERIAL PRIMARY KEY,
+    id UUID NOT NULL UNIQUE,
+    tenant_id VARCHAR(64) NOT NULL,
+    product_id UUID NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    delta INTEGER NOT NULL,
+    balance INTEGER NOT NULL CHECK (balance >= 0),
+    reference VARCHAR(255) NOT NULL DEFAULT '',
+    idempotency_key VARCHAR(255),
+    actor TEXT NOT NULL,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_stock_movements_product ON stock_movements(tenant_id, product_id, occurred_at DESC);
+
+-- A retried request finds the movement it made instead of moving the stock again
+CREATE UNIQUE INDEX idx_stock_movements_idempotency_key ON stock_movements(product_id, idempotency_key);
+
+-- Movements are append only
+-- +goose StatementBegin
+CREATE FUNCTION reject_stock_movement_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'stock movements are append only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER stock_movements_append_only
+    BEFORE UPDATE OR DELETE ON stock_movements
+    FOR EACH ROW EXECUTE FUNCTION reject_stock_movement_change();
+
+CREATE TRIGGER stock_movements_no_truncate
+    BEFORE TRUNCATE ON stock_movements
+    FOR EACH STATEMENT EXECUTE FUNCTION reject_stock_movement_change();
+
+-- +goose Down
+DROP TABLE IF EXISTS stock_movements;
+DROP FUNCTION IF EXISTS reject_stock_movement_change();
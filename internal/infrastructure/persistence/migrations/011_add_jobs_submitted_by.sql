@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE jobs ADD COLUMN submitted_by VARCHAR(255);
+
+-- +goose Down
+ALTER TABLE jobs DROP COLUMN submitted_by;
@@ -0,0 +1,68 @@
+package persistence
+
+import (
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/product"
+	"github.com/google/uuid"
+)
+
+// StockMovementModel is a row of the append-only stock ledger. It is written
+// in the same transaction as the stock level it explains.
+type StockMovementModel struct {
+	Position  int64                `gorm:"primaryKey;autoIncrement"`
+	ID        uuid.UUID            `gorm:"type:uuid;not null;uniqueIndex"`
+	TenantID  string               `gorm:"not null;size:64"`
+	ProductID uuid.UUID            `gorm:"type:uuid;not null;index;uniqueIndex:idx_stock_movements_idempotency_key,priority:1"`
+	Type      product.MovementType `gorm:"not null;size:20"`
+	Delta     int                  `gorm:"not null"`
+	Balance   int                  `gorm:"not null"`
+	Reference string               `gorm:"not null;size:255;default:''"`
+	// Null unless given, as Postgres never considers nulls duplicates
+	IdempotencyKey *string   `gorm:"size:255;uniqueIndex:idx_stock_movements_idempotency_key,priority:2"`
+	Actor          string    `gorm:"not null"`
+	OccurredAt     time.Time `gorm:"not null"`
+}
+
+// TableName overrides the table name
+func (StockMovementModel) TableName() string {
+	return "stock_movements"
+}
+
+func (m *StockMovementModel) ToDomain() product.Movement {
+	movement := product.Movement{
+		ID:         m.ID,
+		TenantID:   m.TenantID,
+		ProductID:  m.ProductID,
+		Type:       m.Type,
+		Delta:      m.Delta,
+		Balance:    m.Balance,
+		Reference:  m.Reference,
+		Actor:      m.Actor,
+		Position:   m.Position,
+		OccurredAt: m.OccurredAt,
+	}
+	if m.IdempotencyKey != nil {
+		movement.IdempotencyKey = *m.IdempotencyKey
+	}
+	return movement
+}
+
+func StockMovementFromDomain(m *product.Movement) *StockMovementModel {
+	model := &StockMovementModel{
+		ID:         m.ID,
+		TenantID:   m.TenantID,
+		ProductID:  m.ProductID,
+		Type:       m.Type,
+		Delta:      m.Delta,
+		Balance:    m.Balance,
+		Reference:  m.Reference,
+		Actor:      m.Actor,
+		OccurredAt: m.OccurredAt,
+	}
+	if m.IdempotencyKey != "" {
+		key := m.IdempotencyKey
+		model.IdempotencyKey = &key
+	}
+	return model
+}
@@ -0,0 +1,164 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/product"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/auth"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// appendMovements writes a product's stock movements to the ledger using the
+// given transaction. A product without movements yet first gets an opening
+// balance for the stock it had before, so its ledger adds up to its level.
+func appendMovements(ctx context.Context, tx *gorm.DB, tenantID string, movements []product.Movement) error {
+	if len(movements) == 0 {
+		return nil
+	}
+
+	first := movements[0]
+	models := make([]StockMovementModel, 0, len(movements)+1)
+	if opening := first.Balance - first.Delta; opening != 0 {
+		var tracked bool
+		if err := tx.Raw("SELECT EXISTS (SELECT 1 FROM stock_movements WHERE product_id = ?)", first.ProductID).
+			Scan(&tracked).Error; err != nil {
+			return err
+		}
+		if !tracked {
+			models = append(models, StockMovementModel{
+				ID:         uuid.New(),
+				TenantID:   tenantID,
+				ProductID:  first.ProductID,
+				Type:       product.MovementAdjustment,
+				Delta:      opening,
+				Balance:    opening,
+				Reference:  product.OpeningBalance,
+				Actor:      product.SystemActor,
+				OccurredAt: first.OccurredAt,
+			})
+		}
+	}
+
+	actor := movementActor(ctx)
+	for i := range movements {
+		model := StockMovementFromDomain(&movements[i])
+		model.TenantID = tenantID
+		model.Actor = actor
+		models = append(models, *model)
+	}
+	return tx.Create(&models).Error
+}
+
+// movementActor is the authenticated caller, or SystemActor for background work
+func movementActor(ctx context.Context) string {
+	if claims, ok := auth.ClaimsFromContext(ctx); ok && claims.Subject != "" {
+		return claims.Subject
+	}
+	return product.SystemActor
+}
+
+func (r *ProductRepository) MoveStock(ctx context.Context, productID uuid.UUID, movementType product.MovementType, quantity int, reference, idempotencyKey string) (_ *product.Movement, err error) {
+	defer observe("stock_movements", "MoveStock", time.Now(), &err)
+	var id uuid.UUID
+	err = r.withLockedProduct(ctx, productID, func(tx *gorm.DB, p *product.Product) error {
+		// The product lock orders this lookup before the insert of a retry
+		if idempotencyKey != "" {
+			var previous StockMovementModel
+			err := tx.Where("product_id = ? AND idempotency_key = ?", productID, idempotencyKey).Limit(1).Find(&previous).Error
+			if err != nil {
+				return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+			}
+			if previous.Position != 0 {
+				m := previous.ToDomain()
+				if !m.Repeats(movementType, quantity, reference) {
+					return stockError(product.ErrIdempotencyKeyReused)
+				}
+				id = m.ID
+				return nil
+			}
+		}
+
+		m, err := p.MoveStock(movementType, quantity, reference, idempotencyKey)
+		if err != nil {
+			return stockError(err)
+		}
+		id = m.ID
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Read back for the actor and position assigned on insert
+	var model StockMovementModel
+	err = r.scoped(ctx, func(db *gorm.DB, tenantID string) error {
+		if err := db.Where("tenant_id = ? AND id = ?", tenantID, id).First(&model).Error; err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	m := model.ToDomain()
+	return &m, nil
+}
+
+func (r *ProductRepository) ListMovements(ctx context.Context, filter product.MovementFilter) (_ []product.Movement, _ int64, err error) {
+	defer observe("stock_movements", "ListMovements", time.Now(), &err)
+	var models []StockMovementModel
+	var total int64
+	err = r.scoped(ctx, func(db *gorm.DB, tenantID string) error {
+		query := db.Model(&StockMovementModel{}).Where("tenant_id = ? AND product_id = ?", tenantID, filter.ProductID)
+		if filter.From != nil {
+			query = query.Where("occurred_at >= ?", *filter.From)
+		}
+		if filter.To != nil {
+			query = query.Where("occurred_at < ?", *filter.To)
+		}
+
+		if err := query.Count(&total).Error; err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		if filter.PageSize > 0 {
+			query = query.Offset(filter.PageSize * filter.PageNumber).Limit(filter.PageSize)
+		}
+		if err := query.Order("position DESC").Find(&models).Error; err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	movements := make([]product.Movement, len(models))
+	for i := range models {
+		movements[i] = models[i].ToDomain()
+	}
+	return movements, total, nil
+}
+
+func (r *ProductRepository) LedgerBalance(ctx context.Context, productID uuid.UUID) (_ int, _ bool, err error) {
+	defer observe("stock_movements", "LedgerBalance", time.Now(), &err)
+	var result struct {
+		Balance   int
+		Movements int64
+	}
+	err = r.scoped(ctx, func(db *gorm.DB, tenantID string) error {
+		if err := db.Model(&StockMovementModel{}).
+			Select("COALESCE(SUM(delta), 0) AS balance, COUNT(*) AS movements").
+			Where("tenant_id = ? AND product_id = ?", tenantID, productID).
+			Scan(&result).Error; err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	return result.Balance, result.Movements > 0, nil
+}
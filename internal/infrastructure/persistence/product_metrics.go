@@ -0,0 +1,60 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/product"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ProductStatusCollector exposes the number of products per status across
+// all tenants. It counts on every scrape, so the value is never stale.
+type ProductStatusCollector struct {
+	db      *gorm.DB
+	timeout time.Duration
+	desc    *prometheus.Desc
+}
+
+func NewProductStatusCollector(db *gorm.DB, timeout time.Duration) *ProductStatusCollector {
+	return &ProductStatusCollector{
+		db:      db,
+		timeout: timeout,
+		desc:    prometheus.NewDesc("products", "Number of products by status across all tenants.", []string{"status"}, nil),
+	}
+}
+
+func (c *ProductStatusCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *ProductStatusCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	var rows []struct {
+		Status product.ProductStatus
+		Count  int64
+	}
+	if err := c.db.WithContext(ctx).Model(&ProductModel{}).Select("status, COUNT(*) AS count").Group("status").Scan(&rows).Error; err != nil {
+		zap.L().Warn("Failed to count products by status", zap.Error(err))
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+		return
+	}
+
+	// Report every status so that a status without products reads 0 rather than no data
+	counts := map[product.ProductStatus]int64{
+		product.StatusDraft:        0,
+		product.StatusActive:       0,
+		product.StatusInactive:     0,
+		product.StatusDiscontinued: 0,
+	}
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	for status, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(count), string(status))
+	}
+}
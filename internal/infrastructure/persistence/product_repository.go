@@ -2,6 +2,7 @@ package persistence
 
 import (
 	"context"
+	"time"
 
 	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/product"
 	"github.com/google/uuid"
@@ -11,89 +12,309 @@ import (
 
 type ProductRepository struct {
 	db *gorm.DB
+	// rowLevelSecurity additionally sets app.tenant_id for the products row
+	// level security policy, see migration 004
+	rowLevelSecurity bool
 }
 
-func NewProductRepository(db *gorm.DB) *ProductRepository {
+func NewProductRepository(db *gorm.DB, rowLevelSecurity bool) *ProductRepository {
 	return &ProductRepository{
-		db: db,
+		db:               db,
+		rowLevelSecurity: rowLevelSecurity,
 	}
 }
 
+// scoped runs fn for the tenant of ctx and fails without one. Every query fn
+// makes must filter on tenantID; in row level security mode fn runs in a
+// transaction where Postgres enforces the same scope.
+func (r *ProductRepository) scoped(ctx context.Context, fn func(db *gorm.DB, tenantID string) error) error {
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	db := r.db.WithContext(ctx)
+	if !r.rowLevelSecurity {
+		return fn(db, tenantID)
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT set_config('app.tenant_id', ?, true)", tenantID).Error; err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		return fn(tx, tenantID)
+	})
+}
+
 // Write Repository Implementation
-func (r *ProductRepository) Save(ctx context.Context, product *product.Product) error {
-	model := FromDomain(product)
-	return r.db.WithContext(ctx).Create(model).Error
+func (r *ProductRepository) Save(ctx context.Context, p *product.Product) (err error) {
+	defer observe("products", "Save", time.Now(), &err)
+	err = r.scoped(ctx, func(db *gorm.DB, tenantID string) error {
+		if p.TenantID() == "" {
+			p.SetTenantID(tenantID)
+		} else if p.TenantID() != tenantID {
+			return fiber.NewError(fiber.StatusInternalServerError, "product belongs to another tenant")
+		}
+
+		model := FromDomain(p)
+		return db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(model).Error; err != nil {
+				return err
+			}
+			if err := appendMovements(ctx, tx, tenantID, p.Movements()); err != nil {
+				return err
+			}
+			return appendEvents(tx, tenantID, p.Events())
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	p.ClearEvents()
+	p.ClearMovements()
+	return nil
 }
 
-func (r *ProductRepository) Update(ctx context.Context, product *product.Product) error {
+func (r *ProductRepository) Update(ctx context.Context, product *product.Product) (err error) {
+	defer observe("products", "Update", time.Now(), &err)
 	model := FromDomain(product)
-	result := r.db.WithContext(ctx).Model(&ProductModel{}).
-		Where("id = ? AND version = ?", model.ID, model.Version-1).
-		Updates(model)
+	err = r.scoped(ctx, func(db *gorm.DB, tenantID string) error {
+		return db.Transaction(func(tx *gorm.DB) error {
+			// Selected, as struct updates skip zero values such as an empty stock
+			result := tx.Model(&ProductModel{}).
+				Where("id = ? AND tenant_id = ? AND version = ?", model.ID, tenantID, model.Version-1).
+				Select("name", "description", "price_amount", "currency", "stock_level", "stock_unit", "reserved_stock", "status", "version").
+				Updates(model)
 
-	if result.Error != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, result.Error.Error())
-	}
+			if result.Error != nil {
+				return fiber.NewError(fiber.StatusInternalServerError, result.Error.Error())
+			}
 
-	if result.RowsAffected == 0 {
-		return fiber.NewError(fiber.StatusConflict, "product has been modified by another process")
+			if result.RowsAffected == 0 {
+				optimisticLockConflicts.WithLabelValues("product").Inc()
+				return fiber.NewError(fiber.StatusConflict, "product has been modified by another process")
+			}
+
+			if err := appendMovements(ctx, tx, tenantID, product.Movements()); err != nil {
+				return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+			}
+			if err := appendEvents(tx, tenantID, product.Events()); err != nil {
+				return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return err
 	}
 
+	product.ClearEvents()
+	product.ClearMovements()
 	return nil
 }
 
-func (r *ProductRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	result := r.db.WithContext(ctx).Delete(&ProductModel{}, id)
-	if result.Error != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, result.Error.Error())
-	}
+func (r *ProductRepository) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	defer observe("products", "Delete", time.Now(), &err)
+	return r.scoped(ctx, func(db *gorm.DB, tenantID string) error {
+		return db.Transaction(func(tx *gorm.DB) error {
+			// Products are soft deleted, so their reservations are released
+			// here rather than by a cascade. The lock keeps new ones out.
+			model, p, err := lockProduct(tx, tenantID, id)
+			if err != nil {
+				return err
+			}
+			if err := releaseReservations(tx, p); err != nil {
+				return err
+			}
+			if p.Version() != model.Version {
+				if err := storeStock(tx, model, p); err != nil {
+					return err
+				}
+			}
 
-	if result.RowsAffected == 0 {
-		return fiber.NewError(fiber.StatusNotFound, "product not found")
-	}
+			result := tx.Where("tenant_id = ?", tenantID).Delete(&ProductModel{}, id)
+			if result.Error != nil {
+				return fiber.NewError(fiber.StatusInternalServerError, result.Error.Error())
+			}
 
-	return nil
+			if result.RowsAffected == 0 {
+				return fiber.NewError(fiber.StatusNotFound, "product not found")
+			}
+
+			deleted := product.ProductDeleted{ProductID: id, OccurredAt: time.Now().UTC()}
+			if err := appendEvents(tx, tenantID, append(p.Events(), deleted)); err != nil {
+				return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+			}
+			return nil
+		})
+	})
 }
 
-func (r *ProductRepository) GetByID(ctx context.Context, id uuid.UUID) (*product.Product, error) {
+func (r *ProductRepository) GetByID(ctx context.Context, id uuid.UUID) (_ *product.Product, err error) {
+	defer observe("products", "GetByID", time.Now(), &err)
 	var model ProductModel
-	if err := r.db.WithContext(ctx).First(&model, id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, fiber.NewError(fiber.StatusNotFound, "product not found")
+	err = r.scoped(ctx, func(db *gorm.DB, tenantID string) error {
+		if err := db.Where("tenant_id = ?", tenantID).First(&model, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fiber.NewError(fiber.StatusNotFound, "product not found")
+			}
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 		}
-		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return model.ToDomain()
 }
 
 // Read Repository Implementation
-func (r *ProductRepository) FindByID(ctx context.Context, id uuid.UUID) (*product.ProductReadModel, error) {
+func (r *ProductRepository) FindByID(ctx context.Context, id uuid.UUID) (_ *product.ProductReadModel, err error) {
+	defer observe("products", "FindByID", time.Now(), &err)
 	var model ProductModel
-	if err := r.db.WithContext(ctx).First(&model, id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, fiber.NewError(fiber.StatusNotFound, "product not found")
+	err = r.scoped(ctx, func(db *gorm.DB, tenantID string) error {
+		if err := db.Where("tenant_id = ?", tenantID).First(&model, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fiber.NewError(fiber.StatusNotFound, "product not found")
+			}
+			return err
 		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return &product.ProductReadModel{
-		ID:          model.ID,
-		Name:        model.Name,
-		Description: model.Description,
-		PriceAmount: model.PriceAmount,
-		Currency:    model.Currency,
-		StockLevel:  model.StockLevel,
-		StockUnit:   model.StockUnit,
-		Status:      model.Status,
-		Version:     model.Version,
+		ID:             model.ID,
+		TenantID:       model.TenantID,
+		Name:           model.Name,
+		Description:    model.Description,
+		PriceAmount:    model.PriceAmount,
+		Currency:       model.Currency,
+		StockLevel:     model.StockLevel,
+		StockUnit:      model.StockUnit,
+		ReservedStock:  model.ReservedStock,
+		AvailableStock: model.StockLevel - model.ReservedStock,
+		Status:         model.Status,
+		Version:        model.Version,
 	}, nil
 }
 
-func (r *ProductRepository) FindAll(ctx context.Context, filter product.ProductFilter) ([]product.ProductReadModel, error) {
+func (r *ProductRepository) FindAll(ctx context.Context, filter product.ProductFilter) (_ []product.ProductReadModel, err error) {
+	defer observe("products", "FindAll", time.Now(), &err)
+	var models []ProductModel
+	err = r.scoped(ctx, func(db *gorm.DB, tenantID string) error {
+		query := applyFilter(db.Where("tenant_id = ?", tenantID), filter)
+
+		// Apply pagination
+		if filter.PageSize > 0 {
+			offset := filter.PageSize * filter.PageNumber
+			query = query.Offset(offset).Limit(filter.PageSize)
+		}
+
+		if err := query.Find(&models).Error; err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toReadModels(models), nil
+}
+
+func (r *ProductRepository) FindByStatus(ctx context.Context, status product.ProductStatus) (_ []product.ProductReadModel, err error) {
+	defer observe("products", "FindByStatus", time.Now(), &err)
+	var models []ProductModel
+	err = r.scoped(ctx, func(db *gorm.DB, tenantID string) error {
+		if err := db.Where("tenant_id = ? AND status = ?", tenantID, status).Find(&models).Error; err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toReadModels(models), nil
+}
+
+func (r *ProductRepository) FindIDs(ctx context.Context, filter product.ProductFilter, after uuid.UUID, limit int) (_ []uuid.UUID, err error) {
+	defer observe("products", "FindIDs", time.Now(), &err)
+	var ids []uuid.UUID
+	err = r.scoped(ctx, func(db *gorm.DB, tenantID string) error {
+		query := applyFilter(db.Model(&ProductModel{}).Where("tenant_id = ?", tenantID), filter)
+		if after != uuid.Nil {
+			query = query.Where("id > ?", after)
+		}
+
+		if err := query.Order("id").Limit(limit).Pluck("id", &ids).Error; err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (r *ProductRepository) FindAfter(ctx context.Context, filter product.ProductFilter, after uuid.UUID, limit int) (_ []product.ProductReadModel, err error) {
+	defer observe("products", "FindAfter", time.Now(), &err)
 	var models []ProductModel
-	query := r.db.WithContext(ctx)
+	err = r.scoped(ctx, func(db *gorm.DB, tenantID string) error {
+		query := applyFilter(db.Where("tenant_id = ?", tenantID), filter)
+		if after != uuid.Nil {
+			query = query.Where("id > ?", after)
+		}
+
+		if err := query.Order("id").Limit(limit).Find(&models).Error; err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toReadModels(models), nil
+}
+
+func (r *ProductRepository) FindByIDs(ctx context.Context, ids []uuid.UUID) (_ []product.ProductReadModel, err error) {
+	defer observe("products", "FindByIDs", time.Now(), &err)
+	var models []ProductModel
+	err = r.scoped(ctx, func(db *gorm.DB, tenantID string) error {
+		if err := db.Where("tenant_id = ? AND id IN ?", tenantID, ids).Find(&models).Error; err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toReadModels(models), nil
+}
 
+func (r *ProductRepository) Count(ctx context.Context, filter product.ProductFilter) (_ int64, err error) {
+	defer observe("products", "Count", time.Now(), &err)
+	var count int64
+	err = r.scoped(ctx, func(db *gorm.DB, tenantID string) error {
+		query := applyFilter(db.Model(&ProductModel{}).Where("tenant_id = ?", tenantID), filter)
+		if err := query.Count(&count).Error; err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		return nil
+	})
+	return count, err
+}
+
+// applyFilter adds the WHERE clauses of a product filter to the query
+func applyFilter(query *gorm.DB, filter product.ProductFilter) *gorm.DB {
 	if filter.MinPrice != nil {
 		query = query.Where("price_amount >= ?", *filter.MinPrice)
 	}
@@ -108,62 +329,32 @@ func (r *ProductRepository) FindAll(ctx context.Context, filter product.ProductF
 	}
 	if filter.SearchTerm != "" {
 		query = query.Where(
-			"name ILIKE ? OR description ILIKE ?",
+			"(name ILIKE ? OR description ILIKE ?)",
 			"%"+filter.SearchTerm+"%",
 			"%"+filter.SearchTerm+"%",
 		)
 	}
-
-	// Apply pagination
-	if filter.PageSize > 0 {
-		offset := filter.PageSize * filter.PageNumber
-		query = query.Offset(offset).Limit(filter.PageSize)
-	}
-
-	if err := query.Find(&models).Error; err != nil {
-		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
-	}
-
-	// Convert to read models
-	readModels := make([]product.ProductReadModel, len(models))
-	for i, model := range models {
-		readModels[i] = product.ProductReadModel{
-			ID:          model.ID,
-			Name:        model.Name,
-			Description: model.Description,
-			PriceAmount: model.PriceAmount,
-			Currency:    model.Currency,
-			StockLevel:  model.StockLevel,
-			StockUnit:   model.StockUnit,
-			Status:      model.Status,
-			Version:     model.Version,
-		}
-	}
-
-	return readModels, nil
+	return query
 }
 
-func (r *ProductRepository) FindByStatus(ctx context.Context, status product.ProductStatus) ([]product.ProductReadModel, error) {
-	var models []ProductModel
-	if err := r.db.WithContext(ctx).Where("status = ?", status).Find(&models).Error; err != nil {
-		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
-	}
-
-	// Convert to read models
+// toReadModels converts GORM models to read models
+func toReadModels(models []ProductModel) []product.ProductReadModel {
 	readModels := make([]product.ProductReadModel, len(models))
 	for i, model := range models {
 		readModels[i] = product.ProductReadModel{
-			ID:          model.ID,
-			Name:        model.Name,
-			Description: model.Description,
-			PriceAmount: model.PriceAmount,
-			Currency:    model.Currency,
-			StockLevel:  model.StockLevel,
-			StockUnit:   model.StockUnit,
-			Status:      model.Status,
-			Version:     model.Version,
+			ID:             model.ID,
+			TenantID:       model.TenantID,
+			Name:           model.Name,
+			Description:    model.Description,
+			PriceAmount:    model.PriceAmount,
+			Currency:       model.Currency,
+			StockLevel:     model.StockLevel,
+			StockUnit:      model.StockUnit,
+			ReservedStock:  model.ReservedStock,
+			AvailableStock: model.StockLevel - model.ReservedStock,
+			Status:         model.Status,
+			Version:        model.Version,
 		}
 	}
-
-	return readModels, nil
+	return readModels
 }
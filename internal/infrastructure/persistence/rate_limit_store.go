@@ -0,0 +1,78 @@
+package persistence
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/ratelimit"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RateLimitBucketModel is a token bucket shared by all replicas
+type RateLimitBucketModel struct {
+	Key       string    `gorm:"primaryKey;size:255"`
+	Tokens    float64   `gorm:"not null"`
+	UpdatedAt time.Time `gorm:"not null;index"`
+}
+
+// TableName overrides the table name
+func (RateLimitBucketModel) TableName() string {
+	return "rate_limit_buckets"
+}
+
+// pruneEvery is how many takes pass between deletions of idle buckets
+const pruneEvery = 1000
+
+// RateLimitStore keeps token buckets in Postgres so replicas share limits.
+// Each take locks its bucket row for the duration of a short transaction.
+type RateLimitStore struct {
+	db          *gorm.DB
+	idleTimeout time.Duration
+	takes       atomic.Int64
+}
+
+func NewRateLimitStore(db *gorm.DB, idleTimeout time.Duration) *RateLimitStore {
+	return &RateLimitStore{
+		db:          db,
+		idleTimeout: idleTimeout,
+	}
+}
+
+func (s *RateLimitStore) Take(ctx context.Context, key string, limit ratelimit.Limit, now time.Time) (ratelimit.Result, error) {
+	if s.takes.Add(1)%pruneEvery == 0 {
+		s.prune(ctx, now)
+	}
+
+	var result ratelimit.Result
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		full := ratelimit.NewBucket(limit, now)
+		model := RateLimitBucketModel{Key: key, Tokens: full.Tokens, UpdatedAt: full.UpdatedAt}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&model).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&model, "key = ?", key).Error; err != nil {
+			return err
+		}
+
+		bucket := ratelimit.Bucket{Tokens: model.Tokens, UpdatedAt: model.UpdatedAt}
+		result = bucket.Take(limit, now)
+
+		return tx.Model(&model).Updates(map[string]interface{}{
+			"tokens":     bucket.Tokens,
+			"updated_at": bucket.UpdatedAt,
+		}).Error
+	})
+	return result, err
+}
+
+// prune deletes buckets that have been idle long enough to be full again
+func (s *RateLimitStore) prune(ctx context.Context, now time.Time) {
+	if err := s.db.WithContext(ctx).
+		Where("updated_at < ?", now.Add(-s.idleTimeout)).
+		Delete(&RateLimitBucketModel{}).Error; err != nil {
+		zap.L().Warn("Failed to prune rate limit buckets", zap.Error(err))
+	}
+}
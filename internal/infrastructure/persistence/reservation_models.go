@@ -0,0 +1,54 @@
+package persistence
+
+import (
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/product"
+	"github.com/google/uuid"
+)
+
+// ReservationModel is the GORM model for stock reservations
+type ReservationModel struct {
+	ID        uuid.UUID                 `gorm:"type:uuid;primary_key"`
+	TenantID  string                    `gorm:"not null;size:64"`
+	ProductID uuid.UUID                 `gorm:"type:uuid;not null;index"`
+	OrderID   string                    `gorm:"not null;size:255"`
+	Quantity  int                       `gorm:"not null"`
+	Status    product.ReservationStatus `gorm:"not null;size:20"`
+	ExpiresAt time.Time                 `gorm:"not null"`
+	CreatedAt time.Time                 `gorm:"not null"`
+	ClosedAt  *time.Time
+}
+
+// TableName overrides the table name
+func (ReservationModel) TableName() string {
+	return "stock_reservations"
+}
+
+func (m *ReservationModel) ToDomain() *product.Reservation {
+	return &product.Reservation{
+		ID:        m.ID,
+		TenantID:  m.TenantID,
+		ProductID: m.ProductID,
+		OrderID:   m.OrderID,
+		Quantity:  m.Quantity,
+		Status:    m.Status,
+		ExpiresAt: m.ExpiresAt,
+		CreatedAt: m.CreatedAt,
+		ClosedAt:  m.ClosedAt,
+	}
+}
+
+func ReservationFromDomain(r *product.Reservation) *ReservationModel {
+	return &ReservationModel{
+		ID:        r.ID,
+		TenantID:  r.TenantID,
+		ProductID: r.ProductID,
+		OrderID:   r.OrderID,
+		Quantity:  r.Quantity,
+		Status:    r.Status,
+		ExpiresAt: r.ExpiresAt,
+		CreatedAt: r.CreatedAt,
+		ClosedAt:  r.ClosedAt,
+	}
+}
@@ -0,0 +1,309 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/product"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/tenant"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Reservations change the product's reserved stock, so every change runs with
+// the product row locked. Reservations of one product are thereby serialized
+// with each other and with product updates, which also bump the version.
+
+func (r *ProductRepository) Reserve(ctx context.Context, productID uuid.UUID, orderID string, quantity int, ttl time.Duration) (res *product.Reservation, err error) {
+	defer observe("stock_reservations", "Reserve", time.Now(), &err)
+	err = r.withLockedProduct(ctx, productID, func(tx *gorm.DB, p *product.Product) error {
+		var held int64
+		if err := tx.Model(&ReservationModel{}).
+			Where("product_id = ? AND order_id = ? AND status = ?", productID, orderID, product.ReservationActive).
+			Count(&held).Error; err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		if held > 0 {
+			return fiber.NewError(fiber.StatusConflict, product.ErrReservationDuplicate.Error())
+		}
+		// Stock of reservations the sweeper has yet to expire is available
+		if err := expireDue(tx, p); err != nil {
+			return err
+		}
+
+		res, err = p.Reserve(orderID, quantity, ttl)
+		if err != nil {
+			return stockError(err)
+		}
+		if err := tx.Create(ReservationFromDomain(res)).Error; err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (r *ProductRepository) ConfirmReservation(ctx context.Context, id uuid.UUID) (_ *product.Reservation, err error) {
+	defer observe("stock_reservations", "ConfirmReservation", time.Now(), &err)
+	return r.closeReservation(ctx, id, (*product.Product).ConfirmReservation)
+}
+
+func (r *ProductRepository) ReleaseReservation(ctx context.Context, id uuid.UUID) (_ *product.Reservation, err error) {
+	defer observe("stock_reservations", "ReleaseReservation", time.Now(), &err)
+	return r.closeReservation(ctx, id, (*product.Product).ReleaseReservation)
+}
+
+func (r *ProductRepository) GetReservation(ctx context.Context, id uuid.UUID) (_ *product.Reservation, err error) {
+	defer observe("stock_reservations", "GetReservation", time.Now(), &err)
+	var model ReservationModel
+	err = r.scoped(ctx, func(db *gorm.DB, tenantID string) error {
+		return findReservation(db, tenantID, id, &model)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return model.ToDomain(), nil
+}
+
+// ExpireReservations expires each due reservation in a transaction of its
+// own, acting for the reservation's tenant. Reservations confirmed or
+// released in the meantime, e.g. by another replica, are skipped, and so are
+// those failing to expire; their errors are joined.
+func (r *ProductRepository) ExpireReservations(ctx context.Context, limit int) (_ []product.Reservation, err error) {
+	defer observe("stock_reservations", "ExpireReservations", time.Now(), &err)
+	var due []ReservationModel
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND expires_at <= ?", product.ReservationActive, time.Now().UTC()).
+		Order("expires_at").
+		Limit(limit).
+		Find(&due).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	var expired []product.Reservation
+	var errs []error
+	for _, model := range due {
+		tenantCtx := tenant.WithTenant(ctx, &tenant.Tenant{ID: model.TenantID})
+		res, err := r.closeReservation(tenantCtx, model.ID, func(p *product.Product, res *product.Reservation) error {
+			if res.Status != product.ReservationActive {
+				return nil
+			}
+			return p.ExpireReservation(res)
+		})
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) && fiberErr.Code == fiber.StatusNotFound {
+			// The product was deleted before deletes released its reservations
+			res, err = expireOrphan(r.db.WithContext(ctx), &model)
+		}
+		if err != nil {
+			// One failing reservation must not hold up the others
+			errs = append(errs, fmt.Errorf("reservation %s: %w", model.ID, err))
+			continue
+		}
+		if res != nil && res.Status == product.ReservationExpired {
+			expired = append(expired, *res)
+		}
+	}
+	return expired, errors.Join(errs...)
+}
+
+// expireOrphan expires a reservation of a product that no longer exists. It
+// returns nil if the reservation was closed in the meantime.
+func expireOrphan(db *gorm.DB, model *ReservationModel) (*product.Reservation, error) {
+	now := time.Now().UTC()
+	result := db.Model(&ReservationModel{}).
+		Where("id = ? AND status = ?", model.ID, product.ReservationActive).
+		Updates(map[string]interface{}{
+			"status":    product.ReservationExpired,
+			"closed_at": now,
+		})
+	if result.Error != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, result.Error.Error())
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	res := model.ToDomain()
+	res.Status = product.ReservationExpired
+	res.ClosedAt = &now
+	return res, nil
+}
+
+// closeReservation applies a confirm, release or expiry to a reservation and its product
+func (r *ProductRepository) closeReservation(ctx context.Context, id uuid.UUID, close func(*product.Product, *product.Reservation) error) (*product.Reservation, error) {
+	var current ReservationModel
+	err := r.scoped(ctx, func(db *gorm.DB, tenantID string) error {
+		return findReservation(db, tenantID, id, &current)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var res *product.Reservation
+	err = r.withLockedProduct(ctx, current.ProductID, func(tx *gorm.DB, p *product.Product) error {
+		// Reload under the lock; it may have changed since it was read
+		var model ReservationModel
+		if err := findReservation(tx, p.TenantID(), id, &model); err != nil {
+			return err
+		}
+		res = model.ToDomain()
+
+		if err := close(p, res); err != nil {
+			return stockError(err)
+		}
+		if res.Status == model.Status {
+			return nil
+		}
+		if err := tx.Model(&model).Updates(map[string]interface{}{
+			"status":    res.Status,
+			"closed_at": res.ClosedAt,
+		}).Error; err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// withLockedProduct runs fn in a transaction holding the product's row lock
+// and then stores the product's stock, events and movements if fn changed it
+func (r *ProductRepository) withLockedProduct(ctx context.Context, productID uuid.UUID, fn func(tx *gorm.DB, p *product.Product) error) error {
+	return r.scoped(ctx, func(db *gorm.DB, tenantID string) error {
+		return db.Transaction(func(tx *gorm.DB) error {
+			model, p, err := lockProduct(tx, tenantID, productID)
+			if err != nil {
+				return err
+			}
+			if err := fn(tx, p); err != nil {
+				return err
+			}
+			if p.Version() == model.Version {
+				return nil
+			}
+
+			if err := storeStock(tx, model, p); err != nil {
+				return err
+			}
+			if err := appendMovements(ctx, tx, tenantID, p.Movements()); err != nil {
+				return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+			}
+			if err := appendEvents(tx, tenantID, p.Events()); err != nil {
+				return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+			}
+			p.ClearEvents()
+			p.ClearMovements()
+			return nil
+		})
+	})
+}
+
+// lockProduct loads a product holding its row lock until the transaction ends
+func lockProduct(tx *gorm.DB, tenantID string, id uuid.UUID) (*ProductModel, *product.Product, error) {
+	var model ProductModel
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("tenant_id = ?", tenantID).
+		First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil, fiber.NewError(fiber.StatusNotFound, product.ErrNotFound.Error())
+		}
+		return nil, nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	p, err := model.ToDomain()
+	if err != nil {
+		return nil, nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return &model, p, nil
+}
+
+// storeStock writes the stock and version of a locked product
+func storeStock(tx *gorm.DB, model *ProductModel, p *product.Product) error {
+	// A map, as struct updates would skip a reserved stock of zero
+	if err := tx.Model(model).Updates(map[string]interface{}{
+		"stock_level":    p.Stock(),
+		"reserved_stock": p.Reserved(),
+		"version":        p.Version(),
+	}).Error; err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return nil
+}
+
+// releaseReservations releases all active reservations of a locked product
+func releaseReservations(tx *gorm.DB, p *product.Product) error {
+	var active []ReservationModel
+	if err := tx.Where("product_id = ? AND status = ?", p.ID(), product.ReservationActive).
+		Find(&active).Error; err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	for _, model := range active {
+		res := model.ToDomain()
+		if err := p.ReleaseReservation(res); err != nil {
+			return stockError(err)
+		}
+		if err := tx.Model(&model).Updates(map[string]interface{}{
+			"status":    res.Status,
+			"closed_at": res.ClosedAt,
+		}).Error; err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+	}
+	return nil
+}
+
+// expireDue expires the product's reservations whose TTL has passed
+func expireDue(tx *gorm.DB, p *product.Product) error {
+	var due []ReservationModel
+	if err := tx.Where("product_id = ? AND status = ? AND expires_at <= ?", p.ID(), product.ReservationActive, time.Now().UTC()).
+		Find(&due).Error; err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	for _, model := range due {
+		res := model.ToDomain()
+		if err := p.ExpireReservation(res); err != nil {
+			return stockError(err)
+		}
+		if err := tx.Model(&model).Updates(map[string]interface{}{
+			"status":    res.Status,
+			"closed_at": res.ClosedAt,
+		}).Error; err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+	}
+	return nil
+}
+
+func findReservation(db *gorm.DB, tenantID string, id uuid.UUID, model *ReservationModel) error {
+	if err := db.Where("tenant_id = ?", tenantID).First(model, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return fiber.NewError(fiber.StatusNotFound, product.ErrReservationNotFound.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return nil
+}
+
+// stockError maps the stock rule a change broke onto a status
+func stockError(err error) error {
+	switch {
+	case errors.Is(err, product.ErrInsufficientStock),
+		errors.Is(err, product.ErrNotReservable),
+		errors.Is(err, product.ErrReservationClosed),
+		errors.Is(err, product.ErrReservationExpired),
+		errors.Is(err, product.ErrIdempotencyKeyReused):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	}
+	return fiber.NewError(fiber.StatusBadRequest, err.Error())
+}
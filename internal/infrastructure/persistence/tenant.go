@@ -0,0 +1,18 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/tenant"
+	"github.com/gofiber/fiber/v2"
+)
+
+// currentTenant returns the tenant that scoped queries must filter on. Running
+// such a query without a tenant is a programming error, so it fails closed.
+func currentTenant(ctx context.Context) (string, error) {
+	id, err := tenant.ID(ctx)
+	if err != nil {
+		return "", fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return id, nil
+}
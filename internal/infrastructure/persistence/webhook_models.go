@@ -0,0 +1,135 @@
+package persistence
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/webhook"
+	"github.com/google/uuid"
+)
+
+// WebhookSubscriptionModel is the GORM model for webhook subscriptions
+type WebhookSubscriptionModel struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key"`
+	TenantID    string    `gorm:"not null;size:64;default:'default';index"`
+	URL         string    `gorm:"not null"`
+	Description string
+	EventTypes  string `gorm:"type:jsonb;not null;default:'[]'"`
+	Secret      string `gorm:"not null"`
+	Active      bool   `gorm:"not null;default:true"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// TableName overrides the table name
+func (WebhookSubscriptionModel) TableName() string {
+	return "webhook_subscriptions"
+}
+
+func (m *WebhookSubscriptionModel) ToDomain() (*webhook.Subscription, error) {
+	eventTypes := []string{}
+	if err := json.Unmarshal([]byte(m.EventTypes), &eventTypes); err != nil {
+		return nil, err
+	}
+
+	return &webhook.Subscription{
+		ID:          m.ID,
+		TenantID:    m.TenantID,
+		URL:         m.URL,
+		Description: m.Description,
+		EventTypes:  eventTypes,
+		Secret:      m.Secret,
+		Active:      m.Active,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+	}, nil
+}
+
+func SubscriptionFromDomain(s *webhook.Subscription) (*WebhookSubscriptionModel, error) {
+	eventTypes, err := json.Marshal(s.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WebhookSubscriptionModel{
+		ID:          s.ID,
+		TenantID:    s.TenantID,
+		URL:         s.URL,
+		Description: s.Description,
+		EventTypes:  string(eventTypes),
+		Secret:      s.Secret,
+		Active:      s.Active,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+	}, nil
+}
+
+// WebhookDeliveryModel is the GORM model for webhook deliveries
+type WebhookDeliveryModel struct {
+	ID             uuid.UUID              `gorm:"type:uuid;primary_key"`
+	TenantID       string                 `gorm:"not null;size:64;default:'default'"`
+	SubscriptionID uuid.UUID              `gorm:"type:uuid;not null;index"`
+	EventID        uuid.UUID              `gorm:"type:uuid;not null"`
+	EventType      string                 `gorm:"not null;size:50"`
+	Payload        string                 `gorm:"type:jsonb;not null"`
+	Status         webhook.DeliveryStatus `gorm:"not null;size:20;index:idx_webhook_deliveries_due,priority:1"`
+	Attempts       int                    `gorm:"not null;default:0"`
+	NextAttemptAt  time.Time              `gorm:"not null;index:idx_webhook_deliveries_due,priority:2"`
+	LastError      string
+	LastStatusCode int
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+}
+
+// TableName overrides the table name
+func (WebhookDeliveryModel) TableName() string {
+	return "webhook_deliveries"
+}
+
+func (m *WebhookDeliveryModel) ToDomain() *webhook.Delivery {
+	return &webhook.Delivery{
+		ID:             m.ID,
+		TenantID:       m.TenantID,
+		SubscriptionID: m.SubscriptionID,
+		EventID:        m.EventID,
+		EventType:      m.EventType,
+		Payload:        json.RawMessage(m.Payload),
+		Status:         m.Status,
+		Attempts:       m.Attempts,
+		NextAttemptAt:  m.NextAttemptAt,
+		LastError:      m.LastError,
+		LastStatusCode: m.LastStatusCode,
+		CreatedAt:      m.CreatedAt,
+		DeliveredAt:    m.DeliveredAt,
+	}
+}
+
+func DeliveryFromDomain(d *webhook.Delivery) *WebhookDeliveryModel {
+	return &WebhookDeliveryModel{
+		ID:             d.ID,
+		TenantID:       d.TenantID,
+		SubscriptionID: d.SubscriptionID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		Payload:        string(d.Payload),
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		NextAttemptAt:  d.NextAttemptAt,
+		LastError:      d.LastError,
+		LastStatusCode: d.LastStatusCode,
+		CreatedAt:      d.CreatedAt,
+		DeliveredAt:    d.DeliveredAt,
+	}
+}
+
+// EventConsumerOffsetModel stores how far a consumer has read the event log
+type EventConsumerOffsetModel struct {
+	Consumer  string `gorm:"primaryKey;size:100"`
+	Position  int64  `gorm:"not null;default:0"`
+	UpdatedAt time.Time
+}
+
+// TableName overrides the table name
+func (EventConsumerOffsetModel) TableName() string {
+	return "event_consumer_offsets"
+}
@@ -0,0 +1,324 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/webhook"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// webhookConsumer is the event log consumer name used for webhook fan-out
+const webhookConsumer = "webhooks"
+
+type WebhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) *WebhookRepository {
+	return &WebhookRepository{
+		db: db,
+	}
+}
+
+func (r *WebhookRepository) SaveSubscription(ctx context.Context, s *webhook.Subscription) (err error) {
+	defer observe("webhooks", "SaveSubscription", time.Now(), &err)
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+	s.TenantID = tenantID
+
+	model, err := SubscriptionFromDomain(s)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	if err := r.db.WithContext(ctx).Create(model).Error; err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return nil
+}
+
+func (r *WebhookRepository) UpdateSubscription(ctx context.Context, s *webhook.Subscription) (err error) {
+	defer observe("webhooks", "UpdateSubscription", time.Now(), &err)
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+	model, err := SubscriptionFromDomain(s)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	result := r.db.WithContext(ctx).Model(&WebhookSubscriptionModel{}).
+		Where("id = ? AND tenant_id = ?", s.ID, tenantID).
+		Updates(map[string]interface{}{
+			"url":         model.URL,
+			"description": model.Description,
+			"event_types": model.EventTypes,
+			"active":      model.Active,
+			"updated_at":  model.UpdatedAt,
+		})
+	if result.Error != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, result.Error.Error())
+	}
+
+	if result.RowsAffected == 0 {
+		return fiber.NewError(fiber.StatusNotFound, webhook.ErrSubscriptionNotFound.Error())
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, id uuid.UUID) (err error) {
+	defer observe("webhooks", "DeleteSubscription", time.Now(), &err)
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&WebhookSubscriptionModel{}, "id = ? AND tenant_id = ?", id, tenantID)
+		if result.Error != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, result.Error.Error())
+		}
+
+		if result.RowsAffected == 0 {
+			return fiber.NewError(fiber.StatusNotFound, webhook.ErrSubscriptionNotFound.Error())
+		}
+
+		if err := tx.Delete(&WebhookDeliveryModel{}, "subscription_id = ?", id).Error; err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		return nil
+	})
+}
+
+func (r *WebhookRepository) GetSubscription(ctx context.Context, id uuid.UUID) (_ *webhook.Subscription, err error) {
+	defer observe("webhooks", "GetSubscription", time.Now(), &err)
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var model WebhookSubscriptionModel
+	if err := r.db.WithContext(ctx).First(&model, "id = ? AND tenant_id = ?", id, tenantID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fiber.NewError(fiber.StatusNotFound, webhook.ErrSubscriptionNotFound.Error())
+		}
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return model.ToDomain()
+}
+
+func (r *WebhookRepository) ListSubscriptions(ctx context.Context) (_ []webhook.Subscription, err error) {
+	defer observe("webhooks", "ListSubscriptions", time.Now(), &err)
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var models []WebhookSubscriptionModel
+	if err := r.db.WithContext(ctx).Where("tenant_id = ?", tenantID).Order("created_at").Find(&models).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	subscriptions := make([]webhook.Subscription, len(models))
+	for i := range models {
+		s, err := models[i].ToDomain()
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		subscriptions[i] = *s
+	}
+
+	return subscriptions, nil
+}
+
+func (r *WebhookRepository) GetDelivery(ctx context.Context, id uuid.UUID) (_ *webhook.Delivery, err error) {
+	defer observe("webhooks", "GetDelivery", time.Now(), &err)
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var model WebhookDeliveryModel
+	if err := r.db.WithContext(ctx).First(&model, "id = ? AND tenant_id = ?", id, tenantID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fiber.NewError(fiber.StatusNotFound, webhook.ErrDeliveryNotFound.Error())
+		}
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return model.ToDomain(), nil
+}
+
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, filter webhook.DeliveryFilter) (_ []webhook.Delivery, err error) {
+	defer observe("webhooks", "ListDeliveries", time.Now(), &err)
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var models []WebhookDeliveryModel
+	query := r.db.WithContext(ctx).Where("tenant_id = ? AND subscription_id = ?", tenantID, filter.SubscriptionID)
+
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+
+	// Apply pagination
+	if filter.PageSize > 0 {
+		offset := filter.PageSize * filter.PageNumber
+		query = query.Offset(offset).Limit(filter.PageSize)
+	}
+
+	if err := query.Order("created_at DESC").Find(&models).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	deliveries := make([]webhook.Delivery, len(models))
+	for i := range models {
+		deliveries[i] = *models[i].ToDomain()
+	}
+
+	return deliveries, nil
+}
+
+func (r *WebhookRepository) UpdateDelivery(ctx context.Context, d *webhook.Delivery) (err error) {
+	defer observe("webhooks", "UpdateDelivery", time.Now(), &err)
+	tenantID, err := currentTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	model := DeliveryFromDomain(d)
+	result := r.db.WithContext(ctx).Model(&WebhookDeliveryModel{}).
+		Where("id = ? AND tenant_id = ?", d.ID, tenantID).
+		Updates(map[string]interface{}{
+			"status":           model.Status,
+			"attempts":         model.Attempts,
+			"next_attempt_at":  model.NextAttemptAt,
+			"last_error":       model.LastError,
+			"last_status_code": model.LastStatusCode,
+			"delivered_at":     model.DeliveredAt,
+		})
+	if result.Error != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, result.Error.Error())
+	}
+
+	if result.RowsAffected == 0 {
+		return fiber.NewError(fiber.StatusNotFound, webhook.ErrDeliveryNotFound.Error())
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) FanOut(ctx context.Context, limit int) (_ int, err error) {
+	defer observe("webhooks", "FanOut", time.Now(), &err)
+	consumed := 0
+
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock the offset row so concurrent replicas fan out each event once
+		offset := EventConsumerOffsetModel{Consumer: webhookConsumer}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&offset).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&offset, "consumer = ?", webhookConsumer).Error; err != nil {
+			return err
+		}
+
+		var events []ProductEventModel
+		if err := tx.Where("position > ?", offset.Position).
+			Order("position").
+			Limit(limit).
+			Find(&events).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		var subscriptionModels []WebhookSubscriptionModel
+		if err := tx.Where("active = ?", true).Find(&subscriptionModels).Error; err != nil {
+			return err
+		}
+
+		subscriptions := make([]*webhook.Subscription, 0, len(subscriptionModels))
+		for i := range subscriptionModels {
+			s, err := subscriptionModels[i].ToDomain()
+			if err != nil {
+				return err
+			}
+			subscriptions = append(subscriptions, s)
+		}
+
+		deliveries := make([]*WebhookDeliveryModel, 0)
+		for i := range events {
+			event := events[i].ToDomain()
+			for _, s := range subscriptions {
+				// Subscribers only ever see their own tenant's events
+				if s.TenantID != event.TenantID || !s.Matches(event.Type) {
+					continue
+				}
+				d, err := webhook.NewDelivery(s.ID, event)
+				if err != nil {
+					return err
+				}
+				deliveries = append(deliveries, DeliveryFromDomain(d))
+			}
+		}
+
+		if len(deliveries) > 0 {
+			if err := tx.CreateInBatches(deliveries, 500).Error; err != nil {
+				return err
+			}
+		}
+
+		consumed = len(events)
+		return tx.Model(&offset).Updates(map[string]interface{}{
+			"position":   events[len(events)-1].Position,
+			"updated_at": time.Now().UTC(),
+		}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return consumed, nil
+}
+
+func (r *WebhookRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) (_ []webhook.Delivery, err error) {
+	defer observe("webhooks", "ClaimDue", time.Now(), &err)
+	now := time.Now().UTC()
+
+	var models []WebhookDeliveryModel
+	err = r.db.WithContext(ctx).Raw(`
+		UPDATE webhook_deliveries
+		SET next_attempt_at = ?
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = ? AND next_attempt_at <= ?
+			ORDER BY next_attempt_at
+			FOR UPDATE SKIP LOCKED
+			LIMIT ?
+		)
+		RETURNING *`,
+		now.Add(lease), webhook.DeliveryPending, now, limit,
+	).Scan(&models).Error
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]webhook.Delivery, len(models))
+	for i := range models {
+		deliveries[i] = *models[i].ToDomain()
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,104 @@
+package authz
+
+import (
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/commands"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/queries"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/stream"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/interfaces/graphql"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/auth"
+)
+
+const (
+	ProductsRead  auth.Permission = "products:read"
+	ProductsWrite auth.Permission = "products:write"
+	// ProductsAdmin covers destructive operations and webhook management
+	ProductsAdmin auth.Permission = "products:admin"
+	// PlatformAdmin manages settings shared by all tenants. Only tokens can
+	// grant it; API keys belong to a tenant.
+	PlatformAdmin auth.Permission = "platform:admin"
+)
+
+// Scopes lists the permissions an API key may be granted
+func Scopes() []string {
+	return []string{string(ProductsRead), string(ProductsWrite), string(ProductsAdmin)}
+}
+
+// NewPolicy returns the permission required by every command and query.
+// Admins can also write, and writers can also read.
+func NewPolicy(permissionClaims []string) *auth.Policy {
+	policy := auth.NewPolicy(permissionClaims)
+	policy.Imply(ProductsAdmin, ProductsWrite)
+	policy.Imply(ProductsWrite, ProductsRead)
+
+	// Products
+	auth.Require[commands.CreateProductCommand](policy, ProductsWrite)
+	auth.Require[commands.UpdateProductCommand](policy, ProductsWrite)
+	auth.RequireFunc(policy, func(cmd *commands.ChangeProductStatusCommand) auth.Permission {
+		return statusChangePermission(cmd.Action)
+	})
+	auth.Require[commands.DeleteProductCommand](policy, ProductsAdmin)
+	auth.Require[queries.GetProductQuery](policy, ProductsRead)
+	auth.Require[queries.ListProductsQuery](policy, ProductsRead)
+	auth.Require[stream.Filter](policy, ProductsRead)
+	auth.Require[graphql.Request](policy, ProductsRead)
+	// The audit trail names users and their addresses
+	auth.Require[queries.ListProductAuditQuery](policy, ProductsAdmin)
+
+	// Stock reservations
+	auth.Require[commands.ReserveStockCommand](policy, ProductsWrite)
+	auth.Require[commands.ConfirmReservationCommand](policy, ProductsWrite)
+	auth.Require[commands.ReleaseReservationCommand](policy, ProductsWrite)
+	auth.Require[queries.GetReservationQuery](policy, ProductsRead)
+
+	// Stock ledger
+	auth.Require[commands.MoveStockCommand](policy, ProductsWrite)
+	auth.Require[queries.GetStockQuery](policy, ProductsRead)
+	auth.Require[queries.ListStockMovementsQuery](policy, ProductsRead)
+
+	// Bulk jobs
+	auth.Require[commands.BulkChangePriceCommand](policy, ProductsWrite)
+	auth.RequireFunc(policy, func(cmd *commands.BulkChangeStatusCommand) auth.Permission {
+		return statusChangePermission(cmd.Action)
+	})
+	auth.Require[commands.BulkImportProductsCommand](policy, ProductsWrite)
+	auth.Require[queries.GetJobQuery](policy, ProductsRead)
+	auth.Require[commands.CancelJobCommand](policy, ProductsWrite)
+
+	// Webhooks expose every product event to an external URL
+	auth.Require[commands.CreateWebhookSubscriptionCommand](policy, ProductsAdmin)
+	auth.Require[commands.UpdateWebhookSubscriptionCommand](policy, ProductsAdmin)
+	auth.Require[commands.DeleteWebhookSubscriptionCommand](policy, ProductsAdmin)
+	auth.Require[commands.RedeliverWebhookCommand](policy, ProductsAdmin)
+	auth.Require[queries.GetWebhookSubscriptionQuery](policy, ProductsAdmin)
+	auth.Require[queries.ListWebhookSubscriptionsQuery](policy, ProductsAdmin)
+	auth.Require[queries.ListWebhookDeliveriesQuery](policy, ProductsAdmin)
+
+	// API keys can be issued with any scope, so managing them needs admin
+	auth.Require[commands.CreateAPIKeyCommand](policy, ProductsAdmin)
+	auth.Require[commands.RevokeAPIKeyCommand](policy, ProductsAdmin)
+	auth.Require[queries.GetAPIKeyQuery](policy, ProductsAdmin)
+	auth.Require[queries.ListAPIKeysQuery](policy, ProductsAdmin)
+
+	// Runtime settings reveal rate limits and targeted features
+	auth.Require[queries.GetRuntimeConfigQuery](policy, ProductsAdmin)
+	// Health checks name the downstreams and database errors of every tenant
+	auth.Require[queries.GetHealthQuery](policy, PlatformAdmin)
+	// The demo downstream is shared by every tenant
+	auth.Require[queries.CallDemoQuery](policy, PlatformAdmin)
+
+	// Feature flags are shared by every tenant and their targeting names tenants
+	auth.Require[commands.UpdateFeatureFlagCommand](policy, PlatformAdmin)
+	auth.Require[commands.DeleteFeatureFlagCommand](policy, PlatformAdmin)
+	auth.Require[queries.GetFeatureFlagQuery](policy, PlatformAdmin)
+	auth.Require[queries.ListFeatureFlagsQuery](policy, PlatformAdmin)
+
+	return policy
+}
+
+// statusChangePermission makes discontinuing, which cannot be undone, an admin operation
+func statusChangePermission(action string) auth.Permission {
+	if action == "discontinue" {
+		return ProductsAdmin
+	}
+	return ProductsWrite
+}
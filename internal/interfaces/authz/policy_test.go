@@ -0,0 +1,98 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/commands"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/queries"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/auth"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestPolicy(t *testing.T) {
+	policy := NewPolicy([]string{"scope", "roles"})
+
+	tests := []struct {
+		name    string
+		claims  map[string]interface{}
+		req     any
+		status  int
+		message string
+	}{
+		{"reader lists products", scope("products:read"), &queries.ListProductsQuery{}, 0, ""},
+		{"reader cannot create", scope("products:read"), &commands.CreateProductCommand{}, fiber.StatusForbidden, "missing permission products:write"},
+		{"writer creates", scope("products:write"), &commands.CreateProductCommand{}, 0, ""},
+		{"writer reads", scope("products:write"), &queries.GetProductQuery{}, 0, ""},
+		{"writer activates", scope("products:write"), &commands.ChangeProductStatusCommand{Action: "activate"}, 0, ""},
+		{"writer cannot discontinue", scope("products:write"), &commands.ChangeProductStatusCommand{Action: "discontinue"}, fiber.StatusForbidden, "missing permission products:admin"},
+		{"writer cannot delete", scope("products:write"), &commands.DeleteProductCommand{}, fiber.StatusForbidden, "missing permission products:admin"},
+		{"reader cannot reserve stock", scope("products:read"), &commands.ReserveStockCommand{}, fiber.StatusForbidden, "missing permission products:write"},
+		{"writer confirms reservations", scope("products:write"), &commands.ConfirmReservationCommand{}, 0, ""},
+		{"reader cannot move stock", scope("products:read"), &commands.MoveStockCommand{}, fiber.StatusForbidden, "missing permission products:write"},
+		{"reader lists stock movements", scope("products:read"), &queries.ListStockMovementsQuery{}, 0, ""},
+		{"writer cannot bulk discontinue", scope("products:write"), &commands.BulkChangeStatusCommand{Action: "discontinue"}, fiber.StatusForbidden, "missing permission products:admin"},
+		{"admin role deletes", map[string]interface{}{"roles": []interface{}{"products:admin"}}, &commands.DeleteProductCommand{}, 0, ""},
+		{"admin reads", scope("products:admin"), &queries.ListProductsQuery{}, 0, ""},
+		{"reader cannot manage webhooks", scope("products:read"), &queries.ListWebhookSubscriptionsQuery{}, fiber.StatusForbidden, "missing permission products:admin"},
+		{"several scopes", scope("openid products:read products:write"), &commands.UpdateProductCommand{}, 0, ""},
+		{"no permissions", map[string]interface{}{}, &queries.GetProductQuery{}, fiber.StatusForbidden, "missing permission products:read"},
+		{"reader cannot read the audit trail", scope("products:read"), &queries.ListProductAuditQuery{}, fiber.StatusForbidden, "missing permission products:admin"},
+		{"tenant admin cannot read health checks", scope("products:admin"), &queries.GetHealthQuery{}, fiber.StatusForbidden, "missing permission platform:admin"},
+		{"tenant admin cannot call the demo downstream", scope("products:admin"), &queries.CallDemoQuery{}, fiber.StatusForbidden, "missing permission platform:admin"},
+		{"writer cannot read runtime settings", scope("products:write"), &queries.GetRuntimeConfigQuery{}, fiber.StatusForbidden, "missing permission products:admin"},
+		{"writer cannot toggle feature flags", scope("products:write"), &commands.UpdateFeatureFlagCommand{}, fiber.StatusForbidden, "missing permission platform:admin"},
+		{"tenant admin cannot toggle feature flags", scope("products:admin"), &commands.UpdateFeatureFlagCommand{}, fiber.StatusForbidden, "missing permission platform:admin"},
+		{"platform admin lists feature flags", scope("platform:admin"), &queries.ListFeatureFlagsQuery{}, 0, ""},
+		{"writer cannot issue api keys", scope("products:write"), &commands.CreateAPIKeyCommand{}, fiber.StatusForbidden, "missing permission products:admin"},
+		{"unknown request type", scope("products:admin"), &struct{}{}, fiber.StatusForbidden, "no authorization policy for this operation"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := auth.WithClaims(context.Background(), &auth.Claims{Raw: tt.claims})
+			err := policy.Authorize(ctx, tt.req)
+
+			if tt.status == 0 {
+				if err != nil {
+					t.Fatalf("expected request to be allowed, got %v", err)
+				}
+				return
+			}
+
+			var fiberErr *fiber.Error
+			if !errors.As(err, &fiberErr) {
+				t.Fatalf("expected *fiber.Error, got %v", err)
+			}
+			if fiberErr.Code != tt.status || fiberErr.Message != tt.message {
+				t.Fatalf("expected %d %q, got %d %q", tt.status, tt.message, fiberErr.Code, fiberErr.Message)
+			}
+		})
+	}
+}
+
+func TestPolicyGrantsAPIKeyScopes(t *testing.T) {
+	policy := NewPolicy([]string{"scope"})
+	ctx := auth.WithClaims(context.Background(), &auth.Claims{Permissions: []string{"products:write"}})
+
+	if err := policy.Authorize(ctx, &queries.GetProductQuery{}); err != nil {
+		t.Fatalf("expected implied read to be allowed, got %v", err)
+	}
+	if err := policy.Authorize(ctx, &commands.DeleteProductCommand{}); err == nil {
+		t.Fatal("expected delete to be denied")
+	}
+}
+
+func TestPolicyWithoutClaims(t *testing.T) {
+	err := NewPolicy([]string{"scope"}).Authorize(context.Background(), &queries.GetProductQuery{})
+
+	var fiberErr *fiber.Error
+	if !errors.As(err, &fiberErr) || fiberErr.Code != fiber.StatusUnauthorized {
+		t.Fatalf("expected 401, got %v", err)
+	}
+}
+
+func scope(value string) map[string]interface{} {
+	return map[string]interface{}{"scope": value}
+}
@@ -0,0 +1,124 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// listArguments names, per list returning field, the argument that bounds how
+// many items it resolves. Children of those fields cost that many times more.
+var listArguments = map[string]string{
+	"products":      "first",
+	"productsByIds": "ids",
+}
+
+// complexity scores an already validated operation. Every field costs one,
+// multiplied by the page sizes of the list fields above it. Introspection
+// fields are free so that tooling keeps working.
+type complexity struct {
+	fragments       map[string]*ast.FragmentDefinition
+	variables       map[string]interface{}
+	defaultPageSize int
+}
+
+func analyze(doc *ast.Document, operationName string, variables map[string]interface{}, defaultPageSize int) (cost, depth int, err error) {
+	c := &complexity{
+		fragments:       map[string]*ast.FragmentDefinition{},
+		variables:       variables,
+		defaultPageSize: defaultPageSize,
+	}
+
+	var operation *ast.OperationDefinition
+	for _, definition := range doc.Definitions {
+		switch def := definition.(type) {
+		case *ast.FragmentDefinition:
+			c.fragments[def.Name.Value] = def
+		case *ast.OperationDefinition:
+			if operationName == "" || (def.Name != nil && def.Name.Value == operationName) {
+				if operation != nil && operationName == "" {
+					return 0, 0, fmt.Errorf("operationName is required when the document has several operations")
+				}
+				operation = def
+			}
+		}
+	}
+	if operation == nil {
+		return 0, 0, fmt.Errorf("unknown operation %q", operationName)
+	}
+
+	cost, depth = c.selectionSet(operation.SelectionSet, 1, 0)
+	return cost, depth, nil
+}
+
+func (c *complexity) selectionSet(set *ast.SelectionSet, multiplier, depth int) (cost, maxDepth int) {
+	if set == nil {
+		return 0, depth
+	}
+
+	maxDepth = depth
+	for _, selection := range set.Selections {
+		var childCost, childDepth int
+		switch sel := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(sel.Name.Value, "__") {
+				continue
+			}
+			childCost, childDepth = c.selectionSet(sel.SelectionSet, multiplier*c.listSize(sel), depth+1)
+			childCost += multiplier
+			if childDepth < depth+1 {
+				childDepth = depth + 1
+			}
+		case *ast.InlineFragment:
+			childCost, childDepth = c.selectionSet(sel.SelectionSet, multiplier, depth)
+		case *ast.FragmentSpread:
+			if fragment, ok := c.fragments[sel.Name.Value]; ok {
+				childCost, childDepth = c.selectionSet(fragment.SelectionSet, multiplier, depth)
+			}
+		}
+
+		cost += childCost
+		if childDepth > maxDepth {
+			maxDepth = childDepth
+		}
+	}
+	return cost, maxDepth
+}
+
+// listSize returns how many items a field can resolve, one for non-list fields
+func (c *complexity) listSize(field *ast.Field) int {
+	argName, ok := listArguments[field.Name.Value]
+	if !ok {
+		return 1
+	}
+
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != argName {
+			continue
+		}
+		switch value := arg.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(value.Value); err == nil && n > 0 {
+				return n
+			}
+		case *ast.ListValue:
+			return max(len(value.Values), 1)
+		case *ast.Variable:
+			switch v := c.variables[value.Name.Value].(type) {
+			case float64:
+				return max(int(v), 1)
+			case int:
+				return max(v, 1)
+			case []interface{}:
+				return max(len(v), 1)
+			}
+		}
+	}
+
+	if argName == "first" {
+		return c.defaultPageSize
+	}
+	return 1
+}
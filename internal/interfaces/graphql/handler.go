@@ -0,0 +1,139 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/product"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/handler"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/logger"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/tenant"
+	"github.com/gofiber/fiber/v2"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.uber.org/zap"
+)
+
+// Request is a GraphQL over HTTP request body
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Handler serves read-only GraphQL queries over the product read model
+type Handler struct {
+	schema graphql.Schema
+	cfg    config.GraphQLConfig
+}
+
+func NewHandler(repo product.ReadOnlyRepository, cfg config.GraphQLConfig) (*Handler, error) {
+	schema, err := newSchema(repo, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("build graphql schema: %w", err)
+	}
+	return &Handler{schema: schema, cfg: cfg}, nil
+}
+
+func (h *Handler) Handle(c *fiber.Ctx) error {
+	req, err := parseRequest(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if req.Query == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "query is required")
+	}
+	if err := handler.Authorize(c.UserContext(), req); err != nil {
+		return err
+	}
+
+	ctx, span := otel.GetTracerProvider().Tracer("").Start(c.UserContext(), "graphql.execute")
+	defer span.End()
+	if req.OperationName != "" {
+		span.SetAttributes(attribute.String("graphql.operation.name", req.OperationName))
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, "parse failed")
+		return c.Status(fiber.StatusBadRequest).JSON(&graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+	}
+
+	if validation := graphql.ValidateDocument(&h.schema, doc, nil); !validation.IsValid {
+		span.SetStatus(codes.Error, "validation failed")
+		return c.Status(fiber.StatusBadRequest).JSON(&graphql.Result{Errors: validation.Errors})
+	}
+
+	limits := limitsFor(ctx, h.cfg)
+	cost, depth, err := analyze(doc, req.OperationName, req.Variables, limits.DefaultPageSize)
+	if err == nil && depth > limits.MaxDepth {
+		err = fmt.Errorf("query depth %d exceeds the limit of %d", depth, limits.MaxDepth)
+	}
+	if err == nil && cost > limits.MaxComplexity {
+		err = fmt.Errorf("query complexity %d exceeds the limit of %d", cost, limits.MaxComplexity)
+	}
+	span.SetAttributes(attribute.Int("graphql.complexity", cost), attribute.Int("graphql.depth", depth))
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return c.Status(fiber.StatusBadRequest).JSON(&graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+	}
+
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        h.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       ctx,
+	})
+	if result.HasErrors() {
+		span.SetStatus(codes.Error, "execution failed")
+		zap.L().Warn("GraphQL query returned errors",
+			append(logger.GetTraceFields(ctx), zap.Any("errors", result.Errors))...)
+	}
+
+	return c.JSON(result)
+}
+
+func parseRequest(c *fiber.Ctx) (*Request, error) {
+	req := &Request{}
+
+	if c.Method() == fiber.MethodGet {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		if raw := c.Query("variables"); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &req.Variables); err != nil {
+				return nil, errors.New("variables must be a JSON object")
+			}
+		}
+		return req, nil
+	}
+
+	if strings.HasPrefix(c.Get(fiber.HeaderContentType), "application/graphql") {
+		req.Query = string(c.Body())
+		return req, nil
+	}
+
+	if err := json.Unmarshal(c.Body(), req); err != nil {
+		return nil, errors.New("invalid GraphQL request body")
+	}
+	return req, nil
+}
+
+// limitsFor returns the GraphQL limits of the request's tenant, falling back to cfg
+func limitsFor(ctx context.Context, cfg config.GraphQLConfig) config.GraphQLConfig {
+	if t, ok := tenant.FromContext(ctx); ok && t.Config != nil {
+		return t.Config.GraphQL
+	}
+	return cfg
+}
@@ -0,0 +1,353 @@
+package graphql
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/product"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/graphql-go/graphql"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const cursorPrefix = "product:"
+
+// connection is the resolved value of a ProductConnection
+type connection struct {
+	edges       []edge
+	hasNextPage bool
+	filter      product.ProductFilter
+}
+
+type edge struct {
+	cursor string
+	node   product.ProductReadModel
+}
+
+// schemaBuilder builds the read-only product schema on top of ReadOnlyRepository
+type schemaBuilder struct {
+	repo product.ReadOnlyRepository
+	cfg  config.GraphQLConfig
+}
+
+func newSchema(repo product.ReadOnlyRepository, cfg config.GraphQLConfig) (graphql.Schema, error) {
+	b := &schemaBuilder{repo: repo, cfg: cfg}
+
+	statusEnum := graphql.NewEnum(graphql.EnumConfig{
+		Name: "ProductStatus",
+		Values: graphql.EnumValueConfigMap{
+			string(product.StatusDraft):        &graphql.EnumValueConfig{Value: product.StatusDraft},
+			string(product.StatusActive):       &graphql.EnumValueConfig{Value: product.StatusActive},
+			string(product.StatusInactive):     &graphql.EnumValueConfig{Value: product.StatusInactive},
+			string(product.StatusDiscontinued): &graphql.EnumValueConfig{Value: product.StatusDiscontinued},
+		},
+	})
+
+	productType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Product",
+		Fields: graphql.Fields{
+			"id":          b.scalar("Product", "id", graphql.NewNonNull(graphql.ID), func(p product.ProductReadModel) interface{} { return p.ID.String() }),
+			"name":        b.scalar("Product", "name", graphql.NewNonNull(graphql.String), func(p product.ProductReadModel) interface{} { return p.Name }),
+			"description": b.scalar("Product", "description", graphql.String, func(p product.ProductReadModel) interface{} { return p.Description }),
+			"priceAmount": b.scalar("Product", "priceAmount", graphql.NewNonNull(graphql.Float), func(p product.ProductReadModel) interface{} { return p.PriceAmount }),
+			"currency":    b.scalar("Product", "currency", graphql.NewNonNull(graphql.String), func(p product.ProductReadModel) interface{} { return p.Currency }),
+			"stockLevel":  b.scalar("Product", "stockLevel", graphql.NewNonNull(graphql.Int), func(p product.ProductReadModel) interface{} { return p.StockLevel }),
+			"stockUnit":   b.scalar("Product", "stockUnit", graphql.NewNonNull(graphql.String), func(p product.ProductReadModel) interface{} { return p.StockUnit }),
+			"status":      b.scalar("Product", "status", graphql.NewNonNull(statusEnum), func(p product.ProductReadModel) interface{} { return p.Status }),
+			"version":     b.scalar("Product", "version", graphql.NewNonNull(graphql.Int), func(p product.ProductReadModel) interface{} { return p.Version }),
+		},
+	})
+
+	edgeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ProductEdge",
+		Fields: graphql.Fields{
+			"cursor": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.String),
+				Resolve: b.trace("ProductEdge", "cursor", true, func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(edge).cursor, nil }),
+			},
+			"node": &graphql.Field{
+				Type:    graphql.NewNonNull(productType),
+				Resolve: b.trace("ProductEdge", "node", true, func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(edge).node, nil }),
+			},
+		},
+	})
+
+	pageInfoType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PageInfo",
+		Fields: graphql.Fields{
+			"hasNextPage": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Resolve: b.trace("PageInfo", "hasNextPage", true, func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*connection).hasNextPage, nil
+				}),
+			},
+			"endCursor": &graphql.Field{
+				Type: graphql.String,
+				Resolve: b.trace("PageInfo", "endCursor", true, func(p graphql.ResolveParams) (interface{}, error) {
+					conn := p.Source.(*connection)
+					if len(conn.edges) == 0 {
+						return nil, nil
+					}
+					return conn.edges[len(conn.edges)-1].cursor, nil
+				}),
+			},
+		},
+	})
+
+	connectionType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ProductConnection",
+		Fields: graphql.Fields{
+			"edges": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(edgeType))),
+				Resolve: b.trace("ProductConnection", "edges", true, func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*connection).edges, nil
+				}),
+			},
+			"pageInfo": &graphql.Field{
+				Type: graphql.NewNonNull(pageInfoType),
+				Resolve: b.trace("ProductConnection", "pageInfo", true, func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source, nil
+				}),
+			},
+			"totalCount": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.Int),
+				Resolve: b.trace("ProductConnection", "totalCount", false, b.resolveTotalCount),
+			},
+		},
+	})
+
+	filterInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "ProductFilter",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"minPrice":   &graphql.InputObjectFieldConfig{Type: graphql.Float},
+			"maxPrice":   &graphql.InputObjectFieldConfig{Type: graphql.Float},
+			"status":     &graphql.InputObjectFieldConfig{Type: statusEnum},
+			"stockLevel": &graphql.InputObjectFieldConfig{Type: graphql.Int, Description: "Minimum stock level"},
+			"search":     &graphql.InputObjectFieldConfig{Type: graphql.String},
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"product": &graphql.Field{
+				Type: productType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: b.trace("Query", "product", false, b.resolveProduct),
+			},
+			"productsByIds": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(productType)),
+				Args: graphql.FieldConfigArgument{
+					"ids": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.ID)))},
+				},
+				Description: "Fetches several products at once. Unknown IDs resolve to null.",
+				Resolve:     b.trace("Query", "productsByIds", false, b.resolveProductsByIDs),
+			},
+			"products": &graphql.Field{
+				Type: graphql.NewNonNull(connectionType),
+				Args: graphql.FieldConfigArgument{
+					"first":  &graphql.ArgumentConfig{Type: graphql.Int},
+					"after":  &graphql.ArgumentConfig{Type: graphql.String},
+					"filter": &graphql.ArgumentConfig{Type: filterInput},
+				},
+				Resolve: b.trace("Query", "products", false, b.resolveProducts),
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+func (b *schemaBuilder) resolveProduct(p graphql.ResolveParams) (interface{}, error) {
+	id, err := uuid.Parse(p.Args["id"].(string))
+	if err != nil {
+		return nil, errors.New("invalid product id")
+	}
+
+	model, err := b.repo.FindByID(p.Context, id)
+	if err != nil {
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) && fiberErr.Code == fiber.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return *model, nil
+}
+
+func (b *schemaBuilder) resolveProductsByIDs(p graphql.ResolveParams) (interface{}, error) {
+	rawIDs := p.Args["ids"].([]interface{})
+	limits := limitsFor(p.Context, b.cfg)
+	if len(rawIDs) > limits.MaxPageSize {
+		return nil, fmt.Errorf("at most %d ids can be requested at once", limits.MaxPageSize)
+	}
+
+	ids := make([]uuid.UUID, len(rawIDs))
+	for i, raw := range rawIDs {
+		id, err := uuid.Parse(raw.(string))
+		if err != nil {
+			return nil, fmt.Errorf("invalid product id %q", raw)
+		}
+		ids[i] = id
+	}
+
+	models, err := b.repo.FindByIDs(p.Context, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uuid.UUID]product.ProductReadModel, len(models))
+	for _, model := range models {
+		byID[model.ID] = model
+	}
+
+	// Preserve the requested order
+	results := make([]interface{}, len(ids))
+	for i, id := range ids {
+		if model, ok := byID[id]; ok {
+			results[i] = model
+		}
+	}
+	return results, nil
+}
+
+func (b *schemaBuilder) resolveProducts(p graphql.ResolveParams) (interface{}, error) {
+	limits := limitsFor(p.Context, b.cfg)
+	first := limits.DefaultPageSize
+	if raw, ok := p.Args["first"].(int); ok {
+		first = raw
+	}
+	if first < 1 || first > limits.MaxPageSize {
+		return nil, fmt.Errorf("first must be between 1 and %d", limits.MaxPageSize)
+	}
+
+	after := uuid.Nil
+	if raw, ok := p.Args["after"].(string); ok && raw != "" {
+		var err error
+		if after, err = decodeCursor(raw); err != nil {
+			return nil, err
+		}
+	}
+
+	filter := toFilter(p.Args["filter"])
+
+	// Fetch one extra row to know whether there is a next page
+	models, err := b.repo.FindAfter(p.Context, filter, after, first+1)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := &connection{filter: filter, hasNextPage: len(models) > first}
+	if conn.hasNextPage {
+		models = models[:first]
+	}
+
+	conn.edges = make([]edge, len(models))
+	for i, model := range models {
+		conn.edges[i] = edge{cursor: encodeCursor(model.ID), node: model}
+	}
+	return conn, nil
+}
+
+func (b *schemaBuilder) resolveTotalCount(p graphql.ResolveParams) (interface{}, error) {
+	count, err := b.repo.Count(p.Context, p.Source.(*connection).filter)
+	if err != nil {
+		return nil, err
+	}
+	return int(count), nil
+}
+
+// scalar defines a product field read straight from the read model
+func (b *schemaBuilder) scalar(typeName, fieldName string, fieldType graphql.Output, get func(product.ProductReadModel) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Type: fieldType,
+		Resolve: b.trace(typeName, fieldName, true, func(p graphql.ResolveParams) (interface{}, error) {
+			return get(p.Source.(product.ProductReadModel)), nil
+		}),
+	}
+}
+
+// trace wraps a resolver in its own span. Trivial resolvers only read
+// already loaded data and are traced when TraceAllFields is enabled.
+func (b *schemaBuilder) trace(typeName, fieldName string, trivial bool, resolve graphql.FieldResolveFn) graphql.FieldResolveFn {
+	if trivial && !b.cfg.TraceAllFields {
+		return resolve
+	}
+
+	spanName := "graphql.resolve " + typeName + "." + fieldName
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		tracer := otel.GetTracerProvider().Tracer("")
+		ctx, span := tracer.Start(p.Context, spanName)
+		span.SetAttributes(
+			attribute.String("graphql.field.name", fieldName),
+			attribute.String("graphql.field.path", responsePath(p.Info.Path)),
+		)
+		defer span.End()
+
+		p.Context = ctx
+		result, err := resolve(p)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return result, err
+	}
+}
+
+func toFilter(raw interface{}) product.ProductFilter {
+	var filter product.ProductFilter
+	args, ok := raw.(map[string]interface{})
+	if !ok {
+		return filter
+	}
+
+	if v, ok := args["minPrice"].(float64); ok {
+		filter.MinPrice = &v
+	}
+	if v, ok := args["maxPrice"].(float64); ok {
+		filter.MaxPrice = &v
+	}
+	if v, ok := args["status"].(product.ProductStatus); ok {
+		filter.Status = &v
+	}
+	if v, ok := args["stockLevel"].(int); ok {
+		filter.StockLevel = &v
+	}
+	if v, ok := args["search"].(string); ok {
+		filter.SearchTerm = v
+	}
+	return filter
+}
+
+func encodeCursor(id uuid.UUID) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + id.String()))
+}
+
+func decodeCursor(cursor string) (uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(raw), cursorPrefix) {
+		return uuid.Nil, errors.New("invalid cursor")
+	}
+
+	id, err := uuid.Parse(strings.TrimPrefix(string(raw), cursorPrefix))
+	if err != nil {
+		return uuid.Nil, errors.New("invalid cursor")
+	}
+	return id, nil
+}
+
+func responsePath(path *graphql.ResponsePath) string {
+	var parts []string
+	for ; path != nil; path = path.Prev {
+		parts = append([]string{fmt.Sprint(path.Key)}, parts...)
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// toStatus maps the HTTP flavoured errors returned by command and query
+// handlers to gRPC status errors
+func toStatus(err error) error {
+	var fiberErr *fiber.Error
+	if !errors.As(err, &fiberErr) {
+		return status.Error(codes.Internal, "Internal Server Error")
+	}
+
+	return status.Error(codeFor(fiberErr.Code), fiberErr.Message)
+}
+
+func codeFor(httpStatus int) codes.Code {
+	switch httpStatus {
+	case fiber.StatusBadRequest, fiber.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case fiber.StatusUnauthorized:
+		return codes.Unauthenticated
+	case fiber.StatusForbidden:
+		return codes.PermissionDenied
+	case fiber.StatusNotFound:
+		return codes.NotFound
+	case fiber.StatusConflict:
+		return codes.Aborted
+	case fiber.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case fiber.StatusServiceUnavailable, fiber.StatusBadGateway:
+		return codes.Unavailable
+	case fiber.StatusGatewayTimeout, fiber.StatusRequestTimeout:
+		return codes.DeadlineExceeded
+	}
+	return codes.Internal
+}
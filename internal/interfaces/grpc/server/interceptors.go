@@ -0,0 +1,199 @@
+package server
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/auth"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/handler"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/logger"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/ratelimit"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/tenant"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// AuthConfig mirrors the authentication of the HTTP API
+type AuthConfig struct {
+	Verifier *auth.Verifier
+	// Keys authenticates API keys sent as "x-api-key" metadata
+	Keys auth.KeyAuthenticator
+	// Disabled skips authentication, like auth.disabled does for HTTP
+	Disabled bool
+	// Tenants resolves the caller's tenant from the token or the tenant header metadata
+	Tenants *tenant.Resolver
+}
+
+func unaryAuthInterceptor(cfg AuthConfig) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func streamAuthInterceptor(cfg AuthConfig) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), cfg)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticate verifies the API key or the bearer token from the
+// "authorization" metadata and resolves the tenant the call acts for
+func authenticate(ctx context.Context, cfg AuthConfig) (context.Context, error) {
+	ctx = handler.WithClientIP(ctx, clientIP(ctx))
+	if !cfg.Disabled {
+		var err error
+		if ctx, err = verify(ctx, cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	t, err := cfg.Tenants.Resolve(ctx, first(md.Get(cfg.Tenants.Header())))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return tenant.WithTenant(ctx, t), nil
+}
+
+func verify(ctx context.Context, cfg AuthConfig) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	claims, err := auth.Authenticate(ctx, cfg.Verifier, cfg.Keys,
+		first(md.Get("authorization")), first(md.Get(auth.APIKeyHeader)))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return auth.WithClaims(ctx, claims), nil
+}
+
+// clientIP returns the host of the peer address of the call
+func clientIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// RateLimitConfig shares the rate limits and buckets of the HTTP API
+type RateLimitConfig struct {
+	// Store is nil when calls are not limited
+	Store   ratelimit.Store
+	Runtime *config.Runtime
+}
+
+func unaryRateLimitInterceptor(cfg RateLimitConfig) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := allow(ctx, cfg, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func streamRateLimitInterceptor(cfg RateLimitConfig) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := allow(ss.Context(), cfg, info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// allow limits the call like an HTTP POST to its full method name, so rules
+// can target gRPC methods by path. It must run after authenticate.
+func allow(ctx context.Context, cfg RateLimitConfig, method string) error {
+	if cfg.Store == nil {
+		return nil
+	}
+	allowed, retryAfter := ratelimit.Allow(ctx, cfg.Store, cfg.Runtime, http.MethodPost, method, handler.ClientIP(ctx))
+	if allowed {
+		return nil
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(int(math.Ceil(retryAfter.Seconds())))))
+	return status.Error(codes.ResourceExhausted, "Too Many Requests")
+}
+
+func unaryLoggingInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	res, err := handler(ctx, req)
+	logCall(ctx, info.FullMethod, start, err)
+	return res, err
+}
+
+func streamLoggingInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	logCall(ss.Context(), info.FullMethod, start, err)
+	return err
+}
+
+func logCall(ctx context.Context, method string, start time.Time, err error) {
+	fields := append(logger.GetTraceFields(ctx),
+		zap.String("method", method),
+		zap.String("code", status.Code(err).String()),
+		zap.Duration("latency", time.Since(start)),
+	)
+	if err != nil && status.Code(err) == codes.Internal {
+		zap.L().Error("gRPC call failed", append(fields, zap.Error(err))...)
+		return
+	}
+	zap.L().Info("gRPC call", fields...)
+}
+
+func unaryRecoveryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (res interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			zap.L().Error("Recovered from panic in gRPC handler", append(logger.GetTraceFields(ctx), zap.Any("panic", r))...)
+			err = status.Error(codes.Internal, "Internal Server Error")
+		}
+	}()
+	return handler(ctx, req)
+}
+
+func streamRecoveryInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			zap.L().Error("Recovered from panic in gRPC handler", append(logger.GetTraceFields(ss.Context()), zap.Any("panic", r))...)
+			err = status.Error(codes.Internal, "Internal Server Error")
+		}
+	}()
+	return handler(srv, ss)
+}
+
+// wrappedStream overrides the context of a server stream
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (w *wrappedStream) Context() context.Context {
+	return w.ctx
+}
@@ -0,0 +1,229 @@
+package server
+
+import (
+	"context"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/commands"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/queries"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/product"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/interfaces/grpc/gen/productv1"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/handler"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// exportPageSize is the page size used to walk the catalog for ExportProducts
+const exportPageSize = 500
+
+// ProductService implements productv1.ProductServiceServer on top of the
+// same command and query handlers, and authorization policy, used by the HTTP routes
+type ProductService struct {
+	productv1.UnimplementedProductServiceServer
+
+	createHandler handler.HandlerInterface[commands.CreateProductCommand, product.Product]
+	updateHandler handler.HandlerInterface[commands.UpdateProductCommand, product.Product]
+	statusHandler handler.HandlerInterface[commands.ChangeProductStatusCommand, commands.ChangeProductStatusResponse]
+	deleteHandler handler.HandlerInterface[commands.DeleteProductCommand, product.Product]
+	getHandler    handler.HandlerInterface[queries.GetProductQuery, product.ProductReadModel]
+	listHandler   handler.HandlerInterface[queries.ListProductsQuery, queries.ListProductsResponse]
+}
+
+func NewProductService(writeRepo product.Repository, readRepo product.ReadOnlyRepository) *ProductService {
+	return &ProductService{
+		createHandler: handler.Measured(handler.Authorized(handler.Audited(commands.NewCreateProductHandler(writeRepo)))),
+		updateHandler: handler.Measured(handler.Authorized(handler.Audited(commands.NewUpdateProductHandler(writeRepo)))),
+		statusHandler: handler.Measured(handler.Authorized(handler.Audited(commands.NewChangeProductStatusHandler(writeRepo)))),
+		deleteHandler: handler.Measured(handler.Authorized(handler.Audited(commands.NewDeleteProductHandler(writeRepo)))),
+		getHandler:    handler.Measured(handler.Authorized(queries.NewGetProductHandler(readRepo))),
+		listHandler:   handler.Measured(handler.Authorized(queries.NewListProductsHandler(readRepo))),
+	}
+}
+
+func (s *ProductService) CreateProduct(ctx context.Context, req *productv1.CreateProductRequest) (*productv1.Product, error) {
+	p, err := s.createHandler.Handle(ctx, &commands.CreateProductCommand{
+		Name:        req.GetName(),
+		Description: req.GetDescription(),
+		Price:       req.GetPrice(),
+		Currency:    req.GetCurrency(),
+		StockLevel:  int(req.GetStockLevel()),
+		StockUnit:   req.GetStockUnit(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return fromDomain(p), nil
+}
+
+func (s *ProductService) UpdateProduct(ctx context.Context, req *productv1.UpdateProductRequest) (*productv1.Product, error) {
+	id, err := parseID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := &commands.UpdateProductCommand{
+		ID:          id,
+		Name:        req.GetName(),
+		Description: req.GetDescription(),
+		Price:       req.GetPrice(),
+		Currency:    req.GetCurrency(),
+		StockUnit:   req.GetStockUnit(),
+		Version:     int(req.GetVersion()),
+	}
+	if req.StockLevel != nil {
+		stockLevel := int(req.GetStockLevel())
+		cmd.StockLevel = &stockLevel
+	}
+
+	p, err := s.updateHandler.Handle(ctx, cmd)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return fromDomain(p), nil
+}
+
+func (s *ProductService) ChangeProductStatus(ctx context.Context, req *productv1.ChangeProductStatusRequest) (*productv1.ChangeProductStatusResponse, error) {
+	id, err := parseID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.statusHandler.Handle(ctx, &commands.ChangeProductStatusCommand{
+		ID:      id,
+		Action:  req.GetAction(),
+		Version: int(req.GetVersion()),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &productv1.ChangeProductStatusResponse{
+		Id:     res.ID.String(),
+		Status: string(res.Status),
+	}, nil
+}
+
+func (s *ProductService) DeleteProduct(ctx context.Context, req *productv1.DeleteProductRequest) (*productv1.Product, error) {
+	id, err := parseID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := s.deleteHandler.Handle(ctx, &commands.DeleteProductCommand{ID: id})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return fromDomain(p), nil
+}
+
+func (s *ProductService) GetProduct(ctx context.Context, req *productv1.GetProductRequest) (*productv1.Product, error) {
+	id, err := parseID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := s.getHandler.Handle(ctx, &queries.GetProductQuery{ID: id})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return fromReadModel(p), nil
+}
+
+func (s *ProductService) ListProducts(ctx context.Context, req *productv1.ListProductsRequest) (*productv1.ListProductsResponse, error) {
+	res, err := s.listHandler.Handle(ctx, toListQuery(req))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	products := make([]*productv1.Product, len(res.Products))
+	for i := range res.Products {
+		products[i] = fromReadModel(&res.Products[i])
+	}
+	return &productv1.ListProductsResponse{
+		Products: products,
+		Total:    int32(res.Total),
+	}, nil
+}
+
+func (s *ProductService) ExportProducts(req *productv1.ListProductsRequest, stream grpc.ServerStreamingServer[productv1.Product]) error {
+	ctx := stream.Context()
+	query := toListQuery(req)
+	query.PageSize = exportPageSize
+
+	for page := 0; ; page++ {
+		if err := ctx.Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+
+		query.PageNumber = page
+		res, err := s.listHandler.Handle(ctx, query)
+		if err != nil {
+			return toStatus(err)
+		}
+
+		for i := range res.Products {
+			if err := stream.Send(fromReadModel(&res.Products[i])); err != nil {
+				return err
+			}
+		}
+
+		if len(res.Products) < exportPageSize {
+			return nil
+		}
+	}
+}
+
+func toListQuery(req *productv1.ListProductsRequest) *queries.ListProductsQuery {
+	query := &queries.ListProductsQuery{
+		MinPrice:   req.MinPrice,
+		MaxPrice:   req.MaxPrice,
+		SearchTerm: req.GetSearch(),
+		PageSize:   int(req.GetPageSize()),
+		PageNumber: int(req.GetPage()),
+	}
+	if req.Status != nil {
+		status := product.ProductStatus(req.GetStatus())
+		query.Status = &status
+	}
+	if req.StockLevel != nil {
+		stockLevel := int(req.GetStockLevel())
+		query.StockLevel = &stockLevel
+	}
+	return query
+}
+
+func parseID(raw string) (uuid.UUID, error) {
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return uuid.Nil, status.Error(codes.InvalidArgument, "invalid product id")
+	}
+	return id, nil
+}
+
+func fromDomain(p *product.Product) *productv1.Product {
+	return &productv1.Product{
+		Id:          p.ID().String(),
+		Name:        p.Name(),
+		Description: p.Description(),
+		PriceAmount: p.Price(),
+		Currency:    p.Currency(),
+		StockLevel:  int32(p.Stock()),
+		StockUnit:   p.StockUnit(),
+		Status:      string(p.Status()),
+		Version:     int32(p.Version()),
+	}
+}
+
+func fromReadModel(p *product.ProductReadModel) *productv1.Product {
+	return &productv1.Product{
+		Id:          p.ID.String(),
+		Name:        p.Name,
+		Description: p.Description,
+		PriceAmount: p.PriceAmount,
+		Currency:    p.Currency,
+		StockLevel:  int32(p.StockLevel),
+		StockUnit:   p.StockUnit,
+		Status:      string(p.Status),
+		Version:     int32(p.Version),
+	}
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/interfaces/grpc/gen/productv1"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// New creates the gRPC server with tracing, logging, recovery, JWT auth,
+// tenant resolution and rate limiting
+func New(productService *ProductService, auth AuthConfig, limits RateLimitConfig) *grpc.Server {
+	srv := grpc.NewServer(
+		// Tracing first so every other interceptor runs inside the server span
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(
+			unaryRecoveryInterceptor,
+			unaryLoggingInterceptor,
+			unaryAuthInterceptor(auth),
+			unaryRateLimitInterceptor(limits),
+		),
+		grpc.ChainStreamInterceptor(
+			streamRecoveryInterceptor,
+			streamLoggingInterceptor,
+			streamAuthInterceptor(auth),
+			streamRateLimitInterceptor(limits),
+		),
+	)
+
+	productv1.RegisterProductServiceServer(srv, productService)
+	reflection.Register(srv)
+	return srv
+}
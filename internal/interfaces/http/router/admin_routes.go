@@ -0,0 +1,39 @@
+package router
+
+import (
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/client"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/commands"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/features"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/queries"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/feature"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/handler"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/health"
+	"github.com/gofiber/fiber/v2"
+)
+
+// DemoDownstreamFlag is the feature flag exposing the demo downstream route
+const DemoDownstreamFlag = "demo-downstream"
+
+func SetupAdminRoutes(app *fiber.App, runtime *config.Runtime, flags *features.Service, checks *health.Registry, demo *client.Demo) {
+	admin := app.Group("/api/v1/admin")
+
+	// Command handlers
+	updateFlagHandler := commands.NewUpdateFeatureFlagHandler(flags)
+	deleteFlagHandler := commands.NewDeleteFeatureFlagHandler(flags)
+	// Query handlers
+	runtimeConfigHandler := queries.NewGetRuntimeConfigHandler(runtime)
+	getFlagHandler := queries.NewGetFeatureFlagHandler(flags)
+	listFlagsHandler := queries.NewListFeatureFlagsHandler(flags)
+	healthHandler := queries.NewGetHealthHandler(checks)
+	demoHandler := queries.NewCallDemoHandler(demo)
+
+	// Routes
+	admin.Get("/config", handler.Handler(runtimeConfigHandler))
+	admin.Get("/health", handler.Handler(healthHandler))
+	admin.Get("/downstreams/demo/:scenario", feature.Require(DemoDownstreamFlag), handler.Handler(demoHandler))
+	admin.Get("/features", handler.Handler(listFlagsHandler))
+	admin.Get("/features/:key", handler.Handler(getFlagHandler))
+	admin.Put("/features/:key", handler.Handler(updateFlagHandler))
+	admin.Delete("/features/:key", handler.Handler(deleteFlagHandler))
+}
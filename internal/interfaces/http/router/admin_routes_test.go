@@ -0,0 +1,42 @@
+package router
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/feature"
+	"github.com/gofiber/fiber/v2"
+)
+
+type flags map[string]bool
+
+func (f flags) Enabled(_ context.Context, key string) bool { return f[key] }
+
+func TestDemoDownstreamRouteIsFlagged(t *testing.T) {
+	t.Cleanup(func() { feature.SetEvaluator(nil) })
+	app := fiber.New()
+	SetupAdminRoutes(app, nil, nil, nil, nil)
+
+	tests := []struct {
+		name   string
+		flags  flags
+		status int
+	}{
+		{"hidden while off", flags{}, fiber.StatusNotFound},
+		// An unknown scenario is rejected before the downstream is called
+		{"served while on", flags{DemoDownstreamFlag: true}, fiber.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feature.SetEvaluator(tt.flags)
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/api/v1/admin/downstreams/demo/unknown", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.status {
+				t.Fatalf("got status %d, want %d", resp.StatusCode, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package router
+
+import (
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/commands"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/queries"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/apikey"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/handler"
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetupAPIKeyRoutes registers API key management. scopes lists the
+// permissions keys may be granted.
+func SetupAPIKeyRoutes(app *fiber.App, apiKeyRepo apikey.Repository, cfg config.APIKeysConfig, scopes []string) {
+	apiKeys := app.Group("/api/v1/api-keys")
+
+	// Command handlers
+	createHandler := commands.NewCreateAPIKeyHandler(apiKeyRepo, cfg, scopes)
+	revokeHandler := commands.NewRevokeAPIKeyHandler(apiKeyRepo)
+	// Query handlers
+	getHandler := queries.NewGetAPIKeyHandler(apiKeyRepo)
+	listHandler := queries.NewListAPIKeysHandler(apiKeyRepo)
+
+	// Routes
+	apiKeys.Post("/", handler.Handler(createHandler))
+	apiKeys.Get("/", handler.Handler(listHandler))
+	apiKeys.Get("/:id", handler.Handler(getHandler))
+	apiKeys.Delete("/:id", handler.Handler(revokeHandler))
+}
@@ -0,0 +1,18 @@
+package router
+
+import (
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/queries"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/audit"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/handler"
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupAuditRoutes(app *fiber.App, auditRepo audit.Repository) {
+	v1 := app.Group("/api/v1")
+
+	// Query handlers
+	productAuditHandler := queries.NewListProductAuditHandler(auditRepo)
+
+	// Routes
+	v1.Get("/products/:id/audit", handler.Handler(productAuditHandler))
+}
@@ -0,0 +1,54 @@
+package router
+
+import (
+	"encoding/json"
+	"mime"
+	"path"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/openapi"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Public paths of the API documentation
+const (
+	OpenAPIPath    = "/openapi.json"
+	DocsPath       = "/docs"
+	DocsAssetsPath = DocsPath + "/assets"
+)
+
+// SetupDocsRoutes serves the generated OpenAPI document and a docs UI for it
+func SetupDocsRoutes(app *fiber.App) error {
+	doc, err := OpenAPI()
+	if err != nil {
+		return err
+	}
+
+	spec, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	page, err := openapi.DocsPage(doc.Info.Title, OpenAPIPath, DocsAssetsPath)
+	if err != nil {
+		return err
+	}
+
+	app.Get(OpenAPIPath, func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		return c.Send(spec)
+	})
+	app.Get(DocsPath, func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+		return c.Send(page)
+	})
+	app.Get(DocsAssetsPath+"/:name", func(c *fiber.Ctx) error {
+		asset, ok := openapi.DocsAssets[c.Params("name")]
+		if !ok {
+			return fiber.ErrNotFound
+		}
+		c.Set(fiber.HeaderContentType, mime.TypeByExtension(path.Ext(c.Params("name"))))
+		c.Set(fiber.HeaderCacheControl, "public, max-age=86400")
+		return c.Send(asset)
+	})
+	return nil
+}
@@ -0,0 +1,19 @@
+package router
+
+import (
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/product"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/interfaces/graphql"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupGraphQLRoutes(app *fiber.App, readRepo product.ReadOnlyRepository, cfg config.GraphQLConfig) error {
+	graphqlHandler, err := graphql.NewHandler(readRepo, cfg)
+	if err != nil {
+		return err
+	}
+
+	app.Get("/api/v1/graphql", graphqlHandler.Handle)
+	app.Post("/api/v1/graphql", graphqlHandler.Handle)
+	return nil
+}
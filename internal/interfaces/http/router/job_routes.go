@@ -0,0 +1,30 @@
+package router
+
+import (
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/commands"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/queries"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/job"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/handler"
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupJobRoutes(app *fiber.App, jobRepo job.Repository) {
+	v1 := app.Group("/api/v1")
+	bulk := v1.Group("/products/bulk")
+	jobs := v1.Group("/jobs")
+
+	// Command handlers
+	bulkPriceHandler := commands.NewBulkChangePriceHandler(jobRepo)
+	bulkStatusHandler := commands.NewBulkChangeStatusHandler(jobRepo)
+	bulkImportHandler := commands.NewBulkImportProductsHandler(jobRepo)
+	cancelHandler := commands.NewCancelJobHandler(jobRepo)
+	// Query handlers
+	getHandler := queries.NewGetJobHandler(jobRepo)
+
+	// Routes
+	bulk.Post("/price", handler.Handler(bulkPriceHandler))
+	bulk.Post("/status", handler.Handler(bulkStatusHandler))
+	bulk.Post("/import", handler.Handler(bulkImportHandler))
+	jobs.Get("/:id", handler.Handler(getHandler))
+	jobs.Post("/:id/cancel", handler.Handler(cancelHandler))
+}
@@ -0,0 +1,225 @@
+package router
+
+import (
+	"reflect"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/commands"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/queries"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/apikey"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/featureflag"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/job"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/product"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/webhook"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/interfaces/graphql"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/health"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/openapi"
+	"github.com/gofiber/fiber/v2"
+	graphqlgo "github.com/graphql-go/graphql"
+)
+
+// streamProductsParams documents the parameters parsed by streamProducts
+type streamProductsParams struct {
+	ProductID   string                 `query:"product_id"`
+	Status      *product.ProductStatus `query:"status"`
+	LastEventID string                 `query:"last_event_id"`
+	LastEvent   string                 `reqHeader:"Last-Event-ID"`
+}
+
+// graphQLQueryParams documents the query string form of a GraphQL request
+type graphQLQueryParams struct {
+	Query         string `query:"query"`
+	OperationName string `query:"operationName"`
+	Variables     string `query:"variables"`
+}
+
+// apiRoutes documents every route under /api. TestOpenAPIMatchesRoutes fails
+// when this table and the registered routes drift apart.
+func apiRoutes() []openapi.Route {
+	return []openapi.Route{
+		// Products
+		openapi.Handler[commands.CreateProductCommand, product.ProductReadModel](openapi.Route{
+			Method: fiber.MethodPost, Path: "/api/v1/products", Summary: "Create a product", Tag: "products",
+		}),
+		openapi.Handler[queries.ListProductsQuery, queries.ListProductsResponse](openapi.Route{
+			Method: fiber.MethodGet, Path: "/api/v1/products", Summary: "List products", Tag: "products",
+		}),
+		openapi.Handler[queries.GetProductQuery, product.ProductReadModel](openapi.Route{
+			Method: fiber.MethodGet, Path: "/api/v1/products/:id", Summary: "Get a product", Tag: "products",
+			Errors: []int{fiber.StatusNotFound},
+		}),
+		openapi.Handler[commands.UpdateProductCommand, product.ProductReadModel](openapi.Route{
+			Method: fiber.MethodPut, Path: "/api/v1/products/:id", Summary: "Update a product", Tag: "products",
+			Errors: []int{fiber.StatusNotFound, fiber.StatusConflict},
+		}),
+		openapi.Handler[commands.ChangeProductStatusCommand, commands.ChangeProductStatusResponse](openapi.Route{
+			Method: fiber.MethodPut, Path: "/api/v1/products/:id/status", Summary: "Activate, deactivate or discontinue a product", Tag: "products",
+			Errors: []int{fiber.StatusNotFound, fiber.StatusConflict},
+		}),
+		openapi.Handler[commands.DeleteProductCommand, product.ProductReadModel](openapi.Route{
+			Method: fiber.MethodDelete, Path: "/api/v1/products/:id", Summary: "Delete a product", Tag: "products",
+			Errors: []int{fiber.StatusNotFound},
+		}),
+		openapi.Handler[queries.ListProductAuditQuery, queries.ListProductAuditResponse](openapi.Route{
+			Method: fiber.MethodGet, Path: "/api/v1/products/:id/audit", Summary: "List who changed a product and what changed", Tag: "products",
+		}),
+		{
+			Method: fiber.MethodGet, Path: "/api/v1/products/stream", Summary: "Stream product changes as Server-Sent Events", Tag: "products",
+			OperationID: "streamProducts", ContentType: "text/event-stream",
+			Errors:  []int{fiber.StatusServiceUnavailable},
+			Request: reflect.TypeFor[streamProductsParams](),
+		},
+
+		// Stock reservations
+		openapi.Handler[commands.ReserveStockCommand, commands.ReserveStockResponse](openapi.Route{
+			Method: fiber.MethodPost, Path: "/api/v1/products/:id/reservations", Summary: "Reserve stock of a product for an order", Tag: "reservations",
+			Errors: []int{fiber.StatusNotFound, fiber.StatusConflict},
+		}),
+		openapi.Handler[queries.GetReservationQuery, product.Reservation](openapi.Route{
+			Method: fiber.MethodGet, Path: "/api/v1/reservations/:id", Summary: "Get a stock reservation", Tag: "reservations",
+			Errors: []int{fiber.StatusNotFound},
+		}),
+		openapi.Handler[commands.ConfirmReservationCommand, product.Reservation](openapi.Route{
+			Method: fiber.MethodPost, Path: "/api/v1/reservations/:id/confirm", Summary: "Confirm a reservation, taking its units off the stock", Tag: "reservations",
+			Errors: []int{fiber.StatusNotFound, fiber.StatusConflict},
+		}),
+		openapi.Handler[commands.ReleaseReservationCommand, product.Reservation](openapi.Route{
+			Method: fiber.MethodPost, Path: "/api/v1/reservations/:id/release", Summary: "Release a reservation, returning its units to the available stock", Tag: "reservations",
+			Errors: []int{fiber.StatusNotFound, fiber.StatusConflict},
+		}),
+
+		// Stock ledger
+		openapi.Handler[queries.GetStockQuery, queries.GetStockResponse](openapi.Route{
+			Method: fiber.MethodGet, Path: "/api/v1/products/:id/stock", Summary: "Get the stock of a product reconciled with its ledger", Tag: "stock",
+			Errors: []int{fiber.StatusNotFound},
+		}),
+		openapi.Handler[commands.MoveStockCommand, commands.MoveStockResponse](openapi.Route{
+			Method: fiber.MethodPost, Path: "/api/v1/products/:id/stock/movements", Summary: "Receive, sell, return, write off or adjust stock of a product", Tag: "stock",
+			Errors: []int{fiber.StatusNotFound, fiber.StatusConflict},
+		}),
+		openapi.Handler[queries.ListStockMovementsQuery, queries.ListStockMovementsResponse](openapi.Route{
+			Method: fiber.MethodGet, Path: "/api/v1/products/:id/stock/movements", Summary: "List the stock movements of a product", Tag: "stock",
+		}),
+
+		// Bulk jobs
+		openapi.Handler[commands.BulkChangePriceCommand, commands.SubmitJobResponse](openapi.Route{
+			Method: fiber.MethodPost, Path: "/api/v1/products/bulk/price", Summary: "Change prices of matching products in the background", Tag: "jobs",
+		}),
+		openapi.Handler[commands.BulkChangeStatusCommand, commands.SubmitJobResponse](openapi.Route{
+			Method: fiber.MethodPost, Path: "/api/v1/products/bulk/status", Summary: "Change status of matching products in the background", Tag: "jobs",
+		}),
+		openapi.Handler[commands.BulkImportProductsCommand, commands.SubmitJobResponse](openapi.Route{
+			Method: fiber.MethodPost, Path: "/api/v1/products/bulk/import", Summary: "Import products in the background", Tag: "jobs",
+		}),
+		openapi.Handler[queries.GetJobQuery, job.Job](openapi.Route{
+			Method: fiber.MethodGet, Path: "/api/v1/jobs/:id", Summary: "Get job progress", Tag: "jobs",
+			Errors: []int{fiber.StatusNotFound},
+		}),
+		openapi.Handler[commands.CancelJobCommand, job.Job](openapi.Route{
+			Method: fiber.MethodPost, Path: "/api/v1/jobs/:id/cancel", Summary: "Request cancellation of a job", Tag: "jobs",
+			Errors: []int{fiber.StatusNotFound, fiber.StatusConflict},
+		}),
+
+		// Webhooks
+		openapi.Handler[commands.CreateWebhookSubscriptionCommand, commands.CreateWebhookSubscriptionResponse](openapi.Route{
+			Method: fiber.MethodPost, Path: "/api/v1/webhooks", Summary: "Subscribe to product events", Tag: "webhooks",
+		}),
+		openapi.Handler[queries.ListWebhookSubscriptionsQuery, queries.ListWebhookSubscriptionsResponse](openapi.Route{
+			Method: fiber.MethodGet, Path: "/api/v1/webhooks", Summary: "List webhook subscriptions", Tag: "webhooks",
+			OperationID: "listWebhookSubscriptions",
+		}),
+		openapi.Handler[queries.GetWebhookSubscriptionQuery, webhook.Subscription](openapi.Route{
+			Method: fiber.MethodGet, Path: "/api/v1/webhooks/:id", Summary: "Get a webhook subscription", Tag: "webhooks",
+			Errors: []int{fiber.StatusNotFound},
+		}),
+		openapi.Handler[commands.UpdateWebhookSubscriptionCommand, webhook.Subscription](openapi.Route{
+			Method: fiber.MethodPut, Path: "/api/v1/webhooks/:id", Summary: "Update a webhook subscription", Tag: "webhooks",
+			Errors: []int{fiber.StatusNotFound},
+		}),
+		openapi.Handler[commands.DeleteWebhookSubscriptionCommand, webhook.Subscription](openapi.Route{
+			Method: fiber.MethodDelete, Path: "/api/v1/webhooks/:id", Summary: "Delete a webhook subscription", Tag: "webhooks",
+			Errors: []int{fiber.StatusNotFound},
+		}),
+		openapi.Handler[queries.ListWebhookDeliveriesQuery, queries.ListWebhookDeliveriesResponse](openapi.Route{
+			Method: fiber.MethodGet, Path: "/api/v1/webhooks/:id/deliveries", Summary: "List deliveries of a subscription", Tag: "webhooks",
+			Errors: []int{fiber.StatusNotFound},
+		}),
+		openapi.Handler[commands.RedeliverWebhookCommand, webhook.Delivery](openapi.Route{
+			Method: fiber.MethodPost, Path: "/api/v1/webhooks/deliveries/:id/redeliver", Summary: "Schedule a delivery again", Tag: "webhooks",
+			Errors: []int{fiber.StatusNotFound},
+		}),
+
+		// API keys
+		openapi.Handler[commands.CreateAPIKeyCommand, commands.CreateAPIKeyResponse](openapi.Route{
+			Method: fiber.MethodPost, Path: "/api/v1/api-keys", Summary: "Issue an API key", Tag: "api-keys",
+		}),
+		openapi.Handler[queries.ListAPIKeysQuery, queries.ListAPIKeysResponse](openapi.Route{
+			Method: fiber.MethodGet, Path: "/api/v1/api-keys", Summary: "List API keys", Tag: "api-keys",
+			OperationID: "listAPIKeys",
+		}),
+		openapi.Handler[queries.GetAPIKeyQuery, apikey.APIKey](openapi.Route{
+			Method: fiber.MethodGet, Path: "/api/v1/api-keys/:id", Summary: "Get an API key", Tag: "api-keys",
+			Errors: []int{fiber.StatusNotFound},
+		}),
+		openapi.Handler[commands.RevokeAPIKeyCommand, apikey.APIKey](openapi.Route{
+			Method: fiber.MethodDelete, Path: "/api/v1/api-keys/:id", Summary: "Revoke an API key", Tag: "api-keys",
+			Errors: []int{fiber.StatusNotFound, fiber.StatusConflict},
+		}),
+
+		// GraphQL
+		openapi.Handler[graphql.Request, graphqlgo.Result](openapi.Route{
+			Method: fiber.MethodPost, Path: "/api/v1/graphql", Summary: "Run a GraphQL query against the product read model", Tag: "graphql",
+			OperationID: "graphqlPost",
+		}),
+		{
+			Method: fiber.MethodGet, Path: "/api/v1/graphql", Summary: "Run a GraphQL query passed in the query string", Tag: "graphql",
+			OperationID: "graphqlGet",
+			Request:     reflect.TypeFor[graphQLQueryParams](),
+			Response:    reflect.TypeFor[graphqlgo.Result](),
+		},
+
+		// Admin
+		openapi.Handler[queries.GetRuntimeConfigQuery, queries.GetRuntimeConfigResponse](openapi.Route{
+			Method: fiber.MethodGet, Path: "/api/v1/admin/config", Summary: "Show the active hot reloadable settings", Tag: "admin",
+		}),
+		openapi.Handler[queries.GetHealthQuery, health.Report](openapi.Route{
+			Method: fiber.MethodGet, Path: "/api/v1/admin/health", Summary: "Show the result of each readiness check", Tag: "admin",
+		}),
+		openapi.Handler[queries.CallDemoQuery, queries.CallDemoResponse](openapi.Route{
+			Method: fiber.MethodGet, Path: "/api/v1/admin/downstreams/demo/:scenario", Summary: "Call the demo downstream to exercise its resilience policy", Tag: "admin",
+			Errors: []int{fiber.StatusNotFound, fiber.StatusBadGateway, fiber.StatusGatewayTimeout},
+		}),
+		openapi.Handler[queries.ListFeatureFlagsQuery, queries.ListFeatureFlagsResponse](openapi.Route{
+			Method: fiber.MethodGet, Path: "/api/v1/admin/features", Summary: "List feature flags", Tag: "admin",
+			OperationID: "listFeatureFlags",
+		}),
+		openapi.Handler[queries.GetFeatureFlagQuery, queries.GetFeatureFlagResponse](openapi.Route{
+			Method: fiber.MethodGet, Path: "/api/v1/admin/features/:key", Summary: "Get a feature flag and whether it is on for the caller", Tag: "admin",
+			Errors: []int{fiber.StatusNotFound},
+		}),
+		openapi.Handler[commands.UpdateFeatureFlagCommand, featureflag.Flag](openapi.Route{
+			Method: fiber.MethodPut, Path: "/api/v1/admin/features/:key", Summary: "Create or change a feature flag", Tag: "admin",
+		}),
+		openapi.Handler[commands.DeleteFeatureFlagCommand, featureflag.Flag](openapi.Route{
+			Method: fiber.MethodDelete, Path: "/api/v1/admin/features/:key", Summary: "Revert a feature flag to its config file definition", Tag: "admin",
+			Errors: []int{fiber.StatusNotFound},
+		}),
+	}
+}
+
+// OpenAPI generates the specification of the HTTP API
+func OpenAPI() (*openapi.Document, error) {
+	generator := openapi.NewGenerator(openapi.Info{
+		Title:   "Product API",
+		Version: "1.0.0",
+	})
+
+	generator.Enum(product.StatusDraft, product.StatusActive, product.StatusInactive, product.StatusDiscontinued)
+	generator.Enum(job.StatusPending, job.StatusRunning, job.StatusSucceeded, job.StatusFailed, job.StatusCancelled)
+	generator.Enum(job.TypeBulkPriceChange, job.TypeBulkStatusChange, job.TypeBulkImport)
+	generator.Enum(webhook.DeliveryPending, webhook.DeliverySucceeded, webhook.DeliveryDead)
+
+	if err := generator.Add(apiRoutes()...); err != nil {
+		return nil, err
+	}
+	return generator.Document(), nil
+}
@@ -0,0 +1,173 @@
+package router
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/stream"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/product"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/handler"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/logger"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/tenant"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// SetupProductStreamRoutes registers the Server-Sent Events stream of product
+// changes. It must be registered before SetupProductRoutes so that
+// /products/stream is not captured by /products/:id.
+func SetupProductStreamRoutes(app *fiber.App, hub *stream.Hub, eventLog product.EventLog, cfg config.StreamConfig) {
+	app.Get("/api/v1/products/stream", streamProducts(hub, eventLog, cfg))
+}
+
+func streamProducts(hub *stream.Hub, eventLog product.EventLog, cfg config.StreamConfig) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		filter, err := parseStreamFilter(c)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		if err := handler.Authorize(c.UserContext(), &filter); err != nil {
+			return err
+		}
+		if filter.TenantID, err = tenant.ID(c.UserContext()); err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		// Browsers send Last-Event-ID on reconnect; the query parameter
+		// allows resuming a fresh EventSource
+		lastEventID := c.Get("Last-Event-ID", c.Query("last_event_id"))
+		resumeFrom := int64(-1)
+		if lastEventID != "" {
+			if resumeFrom, err = strconv.ParseInt(lastEventID, 10, 64); err != nil || resumeFrom < 0 {
+				return fiber.NewError(fiber.StatusBadRequest, "invalid Last-Event-ID")
+			}
+		}
+
+		subscription, head, err := hub.Subscribe()
+		if err != nil {
+			return fiber.NewError(fiber.StatusServiceUnavailable, err.Error())
+		}
+
+		c.Set(fiber.HeaderContentType, "text/event-stream")
+		c.Set(fiber.HeaderCacheControl, "no-cache")
+		c.Set(fiber.HeaderConnection, "keep-alive")
+		c.Set("X-Accel-Buffering", "no")
+
+		// The stream outlives the handler and its request scoped context
+		logFields := logger.GetTraceFields(c.UserContext())
+		conn := c.Context().Conn()
+		heartbeat := cfg.HeartbeatInterval
+
+		c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			defer subscription.Close()
+
+			sent := resumeFrom
+			flush := func() error {
+				// Each write gets a fresh deadline; the server write timeout
+				// would otherwise cut long lived streams
+				if conn != nil {
+					_ = conn.SetWriteDeadline(time.Now().Add(2 * heartbeat))
+				}
+				return w.Flush()
+			}
+			send := func(event product.StoredEvent) error {
+				sent = event.Position
+				if !filter.Matches(event) {
+					return nil
+				}
+				data, err := json.Marshal(event)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Position, event.Type, data)
+				return flush()
+			}
+
+			fmt.Fprintf(w, "retry: %d\n\n", heartbeat.Milliseconds())
+			if err := flush(); err != nil {
+				return
+			}
+
+			// Replay what the client missed, up to where live events start
+			for resumeFrom >= 0 && sent < head {
+				events, err := eventLog.ReadAfter(context.Background(), sent, cfg.BatchSize)
+				if err != nil {
+					zap.L().Error("Failed to replay product events", append(logFields, zap.Error(err))...)
+					return
+				}
+				if len(events) == 0 {
+					break
+				}
+				for _, event := range events {
+					if event.Position > head {
+						break
+					}
+					if err := send(event); err != nil {
+						return
+					}
+				}
+				if events[len(events)-1].Position >= head {
+					break
+				}
+			}
+
+			ticker := time.NewTicker(heartbeat)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case event, ok := <-subscription.Events:
+					if !ok {
+						return
+					}
+					if event.Position <= sent {
+						continue
+					}
+					if err := send(event); err != nil {
+						return
+					}
+				case <-ticker.C:
+					fmt.Fprint(w, ": heartbeat\n\n")
+					if err := flush(); err != nil {
+						return
+					}
+				}
+			}
+		})
+
+		return nil
+	}
+}
+
+func parseStreamFilter(c *fiber.Ctx) (stream.Filter, error) {
+	var filter stream.Filter
+
+	if raw := c.Query("product_id"); raw != "" {
+		for _, part := range strings.Split(raw, ",") {
+			id, err := uuid.Parse(strings.TrimSpace(part))
+			if err != nil {
+				return filter, fmt.Errorf("invalid product_id %q", part)
+			}
+			filter.ProductIDs = append(filter.ProductIDs, id)
+		}
+	}
+
+	if raw := c.Query("status"); raw != "" {
+		status := product.ProductStatus(strings.ToUpper(raw))
+		switch status {
+		case product.StatusDraft, product.StatusActive, product.StatusInactive, product.StatusDiscontinued:
+			filter.Status = &status
+		default:
+			return filter, fmt.Errorf("invalid status %q", raw)
+		}
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,28 @@
+package router
+
+import (
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/commands"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/queries"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/product"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/handler"
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupReservationRoutes(app *fiber.App, repo product.ReservationRepository, cfg config.InventoryConfig) {
+	v1 := app.Group("/api/v1")
+	reservations := v1.Group("/reservations")
+
+	// Command handlers
+	reserveHandler := commands.NewReserveStockHandler(repo, cfg)
+	confirmHandler := commands.NewConfirmReservationHandler(repo)
+	releaseHandler := commands.NewReleaseReservationHandler(repo)
+	// Query handlers
+	getHandler := queries.NewGetReservationHandler(repo)
+
+	// Routes
+	v1.Post("/products/:id/reservations", handler.Handler(reserveHandler))
+	reservations.Get("/:id", handler.Handler(getHandler))
+	reservations.Post("/:id/confirm", handler.Handler(confirmHandler))
+	reservations.Post("/:id/release", handler.Handler(releaseHandler))
+}
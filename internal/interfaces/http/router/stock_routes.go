@@ -0,0 +1,24 @@
+package router
+
+import (
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/commands"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/queries"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/product"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/handler"
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupStockRoutes(app *fiber.App, readRepo product.ReadOnlyRepository, ledger product.MovementRepository) {
+	stock := app.Group("/api/v1/products/:id/stock")
+
+	// Command handlers
+	moveHandler := commands.NewMoveStockHandler(ledger)
+	// Query handlers
+	getHandler := queries.NewGetStockHandler(readRepo, ledger)
+	listHandler := queries.NewListStockMovementsHandler(ledger)
+
+	// Routes
+	stock.Get("/", handler.Handler(getHandler))
+	stock.Post("/movements", handler.Handler(moveHandler))
+	stock.Get("/movements", handler.Handler(listHandler))
+}
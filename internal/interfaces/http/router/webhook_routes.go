@@ -0,0 +1,32 @@
+package router
+
+import (
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/commands"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/queries"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/domain/webhook"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/handler"
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupWebhookRoutes(app *fiber.App, webhookRepo webhook.Repository) {
+	webhooks := app.Group("/api/v1/webhooks")
+
+	// Command handlers
+	createHandler := commands.NewCreateWebhookSubscriptionHandler(webhookRepo)
+	updateHandler := commands.NewUpdateWebhookSubscriptionHandler(webhookRepo)
+	deleteHandler := commands.NewDeleteWebhookSubscriptionHandler(webhookRepo)
+	redeliverHandler := commands.NewRedeliverWebhookHandler(webhookRepo)
+	// Query handlers
+	getHandler := queries.NewGetWebhookSubscriptionHandler(webhookRepo)
+	listHandler := queries.NewListWebhookSubscriptionsHandler(webhookRepo)
+	deliveriesHandler := queries.NewListWebhookDeliveriesHandler(webhookRepo)
+
+	// Routes
+	webhooks.Post("/", handler.Handler(createHandler))
+	webhooks.Get("/", handler.Handler(listHandler))
+	webhooks.Post("/deliveries/:id/redeliver", handler.Handler(redeliverHandler))
+	webhooks.Get("/:id", handler.Handler(getHandler))
+	webhooks.Put("/:id", handler.Handler(updateHandler))
+	webhooks.Delete("/:id", handler.Handler(deleteHandler))
+	webhooks.Get("/:id/deliveries", handler.Handler(deliveriesHandler))
+}
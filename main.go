@@ -3,16 +3,34 @@ package main
 import (
 	"context"
 	"fmt"
+	"net"
 	"os"
-	"os/signal"
-	"syscall"
+	"strings"
 	"time"
 
 	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/client"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/apikeys"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/auditing"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/features"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/inventory"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/jobs"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/stream"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/application/webhooks"
 	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/infrastructure/persistence"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/interfaces/authz"
+	grpcserver "github.com/ceylanomer/golang-cqrs-ddd-poc/internal/interfaces/grpc/server"
 	"github.com/ceylanomer/golang-cqrs-ddd-poc/internal/interfaces/http/router"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/auth"
+	circuitbreaker "github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/circuitbraker"
 	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/feature"
 	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/errorhandler"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/handler"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/health"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/lifecycle"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/metrics"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/ratelimit"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/tenant"
 	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/logger"
 	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/tracer"
 	"github.com/gofiber/contrib/fiberzap/v2"
@@ -20,16 +38,22 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/adaptor"
 	recover "github.com/gofiber/fiber/v2/middleware/recover"
-	jwtware "github.com/gofiber/jwt/v2"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/plugin/opentelemetry/tracing"
 	"go.opentelemetry.io/otel/trace"
+	"gopkg.in/yaml.v3"
 )
 
 func main() {
+	// Subcommands run instead of the service
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:]))
+	}
+
 	// Initialize logger
 	logger.Init()
 	log := logger.GetLogger()
@@ -41,16 +65,51 @@ func main() {
 		zap.L().Fatal("Failed to load configuration", zap.Error(err))
 	}
 
-	// Initialize clients
-	transport := client.NewTransport()
-	noRetryClient := client.NewHttpClient(transport)
-	retryableClient := client.NewRetryableClient(transport)
+	// Log level, sampling, rate limits, features and circuit breaker
+	// thresholds follow changes to the config file
+	runtime := config.NewRuntime(cfg)
+	if err := logger.SetLevel(cfg.Log.Level); err != nil {
+		zap.L().Fatal("Failed to set log level", zap.Error(err))
+	}
+	circuitbreaker.SetDefaults(cfg.CircuitBreaker)
+	runtime.Subscribe(func(old, next *config.Config) {
+		if err := logger.SetLevel(next.Log.Level); err != nil {
+			zap.L().Error("Failed to change log level", zap.Error(err))
+		}
+		tracer.UpdateSampler(next.Tracing.Sampler)
+		circuitbreaker.SetDefaults(next.CircuitBreaker)
+	})
+	runtime.Watch()
+
+	// Components start in the order they are registered and stop in reverse
+	components := lifecycle.NewManager(cfg.Lifecycle)
+
+	// Initialize clients, each protected by its resilience policy
+	policies := cfg.Resilience.Policies
+	retryableClient := client.NewRetryableClient("webhooks", policies[cfg.Webhooks.Policy], cfg.Webhooks.AllowPrivateTargets)
+	demo := cfg.Downstreams["demo"]
+	demoClient, err := client.New("demo", demo, policies[demo.Policy])
+	if err != nil {
+		zap.L().Fatal("Failed to configure downstream", zap.Error(err))
+	}
 
 	// Initialize tracer
-	tp := tracer.InitTracer(cfg.Jaeger)
+	tp, err := tracer.InitTracer(cfg.Tracing)
+	if err != nil {
+		zap.L().Fatal("Failed to initialize tracing", zap.Error(err))
+	}
+	// Flush buffered spans once nothing records new ones
+	components.Register(lifecycle.Component{
+		Name:        "tracing",
+		Stop:        tp.Shutdown,
+		StopTimeout: cfg.Tracing.ShutdownTimeout,
+	})
 
 	// Create custom GORM logger with Zap
-	gormLogger := persistence.NewGormZapLogger(log)
+	gormLogger, err := persistence.NewGormZapLogger(log, cfg.Database)
+	if err != nil {
+		zap.L().Fatal("Failed to configure database logging", zap.Error(err))
+	}
 
 	// Initialize database connection with GORM
 	db, err := gorm.Open(postgres.Open(cfg.Database.GetDSN()), &gorm.Config{
@@ -66,20 +125,109 @@ func main() {
 	if err != nil {
 		zap.L().Fatal("Failed to register GORM tracing", zap.Error(err))
 	}
+	components.Register(lifecycle.Component{
+		Name: "database",
+		Stop: func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.Close()
+		},
+	})
 
 	// Auto migrate the schema
-	if err := db.AutoMigrate(&persistence.ProductModel{}); err != nil {
+	if err := db.AutoMigrate(
+		&persistence.ProductModel{},
+		&persistence.JobModel{},
+		&persistence.ProductEventModel{},
+		&persistence.EventConsumerOffsetModel{},
+		&persistence.WebhookSubscriptionModel{},
+		&persistence.WebhookDeliveryModel{},
+		&persistence.APIKeyModel{},
+		&persistence.RateLimitBucketModel{},
+		&persistence.AuditEntryModel{},
+		&persistence.FeatureFlagModel{},
+		&persistence.ReservationModel{},
+		&persistence.StockMovementModel{},
+	); err != nil {
 		zap.L().Fatal("Failed to migrate database schema", zap.Error(err))
 	}
 
 	// Initialize repositories
-	productRepo := persistence.NewProductRepository(db)
+	productRepo := persistence.NewProductRepository(db, cfg.Tenancy.RowLevelSecurity)
+	jobRepo := persistence.NewJobRepository(db)
+	webhookRepo := persistence.NewWebhookRepository(db)
+	eventLog := persistence.NewEventLogRepository(db)
+	apiKeyRepo := persistence.NewAPIKeyRepository(db)
+	auditRepo := persistence.NewAuditRepository(db)
+	featureFlagRepo := persistence.NewFeatureFlagRepository(db)
+
+	// Feature flags are evaluated for the caller in handlers and routes
+	featureFlags := features.NewService(featureFlagRepo, runtime)
+	feature.SetEvaluator(featureFlags)
+
+	// Business metrics are computed on scrape
+	prometheus.MustRegister(persistence.NewProductStatusCollector(db, 5*time.Second))
+
+	// Background job workers finish their current batch on stop; unfinished
+	// jobs resume on the next start
+	jobRunner := jobs.NewRunner(jobRepo, jobs.NewExecutors(productRepo, productRepo, auditing.NewLog(auditRepo)), cfg.Jobs)
+	components.Register(lifecycle.Component{
+		Name: "jobs",
+		Start: func(ctx context.Context) error {
+			jobRunner.Start(context.Background())
+			return nil
+		},
+		Stop: jobRunner.Stop,
+	})
+
+	// Expired stock reservations return their units to the available stock
+	reservationSweeper := inventory.NewSweeper(productRepo, cfg.Inventory)
+	components.Register(lifecycle.Component{
+		Name: "reservations",
+		Start: func(ctx context.Context) error {
+			reservationSweeper.Start(context.Background())
+			return nil
+		},
+		Stop: reservationSweeper.Stop,
+	})
+
+	// Webhook delivery
+	webhookDispatcher := webhooks.NewDispatcher(webhookRepo, &retryableClient, cfg.Webhooks)
+	components.Register(lifecycle.Component{
+		Name: "webhooks",
+		Start: func(ctx context.Context) error {
+			webhookDispatcher.Start(context.Background())
+			return nil
+		},
+		Stop: webhookDispatcher.Stop,
+	})
+
+	// Tail the event log for product change streams. Open streams are ended
+	// while draining, otherwise they keep the HTTP server from shutting down.
+	streamHub := stream.NewHub(eventLog, cfg.Stream)
+	components.Register(lifecycle.Component{
+		Name: "stream",
+		Start: func(ctx context.Context) error {
+			return streamHub.Start(context.WithoutCancel(ctx))
+		},
+		Drain: func(ctx context.Context) error {
+			streamHub.Close()
+			return nil
+		},
+	})
+
+	// Readiness covers the dependencies needed to serve requests
+	healthChecks := health.NewRegistry(cfg.Health.Timeout, cfg.Health.CacheTTL)
+	healthChecks.Register(persistence.HealthChecks(db, cfg.Health)...)
+	healthChecks.Register(circuitbreaker.HealthCheck(), tracer.HealthCheck())
 
 	// Initialize Fiber app
 	app := fiber.New(fiber.Config{
-		ReadTimeout:  time.Duration(cfg.Server.ReadTimeout) * time.Second,
-		WriteTimeout: time.Duration(cfg.Server.WriteTimeout) * time.Second,
-		IdleTimeout:  time.Duration(cfg.Server.IdleTimeout) * time.Second,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
 		ErrorHandler: errorhandler.Handle,
 	})
 
@@ -88,12 +236,16 @@ func main() {
 	// Add OpenTelemetry middleware first to create the parent span
 	app.Use(otelfiber.Middleware(otelfiber.WithNext(func(c *fiber.Ctx) bool {
 		// Skip tracing for metrics endpoint
-		return c.Path() == "/metrics" || c.Path() == "/health"
+		return c.Path() == "/metrics" || c.Path() == "/health" || c.Path() == "/livez" || c.Path() == "/readyz"
 	})))
+	// Record request rate, errors and duration per route
+	app.Use(metrics.Middleware(func(c *fiber.Ctx) bool {
+		return c.Path() == "/metrics" || c.Path() == "/health" || c.Path() == "/livez" || c.Path() == "/readyz"
+	}))
 	// Then add logging middleware
 	app.Use(fiberzap.New(fiberzap.Config{
 		Logger: log,
-		SkipURIs: []string{"/metrics", "/health"},
+		SkipURIs: []string{"/metrics", "/health", "/livez", "/readyz"},
 		FieldsFunc: func(c *fiber.Ctx) []zap.Field {
 			// Get span and trace ID from context
 			spanCtx := trace.SpanContextFromContext(c.UserContext())
@@ -113,58 +265,180 @@ func main() {
 		},
 	}))
 
-	app.Use(jwtware.New(jwtware.Config{
-		SigningKey: []byte("secret"),
-		Filter: func(c *fiber.Ctx) bool {
-			return c.Path() == "/health" || c.Path() == "/metrics" || os.Getenv("ENV") == "local"
-		},
-		ErrorHandler: func(c *fiber.Ctx, err error) error {
-			return fiber.NewError(fiber.StatusUnauthorized, "Unauthorized")
-		},
-	}))
+	// Endpoints served without authentication or a tenant
+	public := func(c *fiber.Ctx) bool {
+		switch c.Path() {
+		case "/health", "/livez", "/readyz", "/metrics", router.OpenAPIPath, router.DocsPath:
+			return true
+		}
+		return strings.HasPrefix(c.Path(), router.DocsAssetsPath+"/")
+	}
+
+	// Each IP address is rate limited before authentication, so that guessing
+	// credentials is limited too
+	idleTimeout := cfg.RateLimit.IdleTimeout
+	var rateLimits ratelimit.Store = ratelimit.NewMemoryStore(idleTimeout)
+	if cfg.RateLimit.Store == "postgres" {
+		rateLimits = persistence.NewRateLimitStore(db, idleTimeout)
+	}
+	app.Use(ratelimit.IPMiddleware(rateLimits, runtime, public))
+
+	// JWT and API key authentication are shared by the HTTP and gRPC APIs
+	var verifier *auth.Verifier
+	apiKeys := apikeys.NewAuthenticator(apiKeyRepo, cfg.APIKeys)
+	if cfg.Auth.Disabled {
+		zap.L().Warn("Authentication is disabled")
+	} else {
+		verifier, err = auth.NewVerifier(cfg.Auth)
+		if err != nil {
+			zap.L().Fatal("Failed to configure authentication", zap.Error(err))
+		}
+		handler.SetAuthorizer(authz.NewPolicy(cfg.Auth.PermissionClaims))
+		app.Use(auth.Middleware(verifier, apiKeys, public))
+	}
+
+	// Successful commands of both APIs leave an audit entry
+	handler.SetAuditor(auditing.NewCommandLog(auditRepo, productRepo, jobRepo, webhookRepo, apiKeyRepo, featureFlags, productRepo))
+
+	// Every product, job and webhook query is scoped to the caller's tenant
+	tenants := tenant.NewResolver(runtime)
+	app.Use(tenant.Middleware(tenants, public))
+	if cfg.Tenancy.RowLevelSecurity {
+		zap.L().Info("Tenant isolation is also enforced by row level security")
+	}
+
+	// Rate limits apply per client once the caller and tenant are known. The
+	// middleware is always installed so that limits can be enabled by a reload.
+	app.Use(ratelimit.Middleware(rateLimits, runtime, public))
 
 	// Setup routes
 	app.Get("/metrics", adaptor.HTTPHandler(promhttp.Handler()))
-	app.Get("/health", func(c *fiber.Ctx) error {
-		return c.Status(fiber.StatusOK).JSON(fiber.Map{"status": "ok"})
+	app.Get("/health", health.LiveHandler())
+	app.Get("/livez", health.LiveHandler())
+	app.Get("/readyz", health.ReadyHandler(healthChecks))
+	if err := router.SetupDocsRoutes(app); err != nil {
+		zap.L().Fatal("Failed to generate OpenAPI document", zap.Error(err))
+	}
+	router.SetupProductStreamRoutes(app, streamHub, eventLog, cfg.Stream)
+	router.SetupProductRoutes(app, productRepo, productRepo)
+	router.SetupReservationRoutes(app, productRepo, cfg.Inventory)
+	router.SetupStockRoutes(app, productRepo, productRepo)
+	router.SetupJobRoutes(app, jobRepo)
+	router.SetupWebhookRoutes(app, webhookRepo)
+	router.SetupAPIKeyRoutes(app, apiKeyRepo, cfg.APIKeys, authz.Scopes())
+	router.SetupAuditRoutes(app, auditRepo)
+	router.SetupAdminRoutes(app, runtime, featureFlags, healthChecks, client.NewDemo(demoClient))
+	if err := router.SetupGraphQLRoutes(app, productRepo, cfg.GraphQL); err != nil {
+		zap.L().Fatal("Failed to set up GraphQL routes", zap.Error(err))
+	}
+
+	// Serve HTTP
+	components.Register(lifecycle.Component{
+		Name: "http",
+		Start: func(ctx context.Context) error {
+			listener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.Server.Port))
+			if err != nil {
+				return err
+			}
+			go func() {
+				if err := app.Listener(listener); err != nil {
+					components.Fail("http", err)
+				}
+			}()
+			zap.L().Info("Server started", zap.Int("port", cfg.Server.Port))
+			return nil
+		},
+		Stop: app.ShutdownWithContext,
 	})
-	router.SetupProductRoutes(app, productRepo, productRepo, noRetryClient, retryableClient)
 
-	// Graceful shutdown channel
-	shutdownChan := make(chan os.Signal, 1)
-	signal.Notify(shutdownChan, syscall.SIGINT, syscall.SIGTERM)
+	// Serve gRPC
+	grpcServer := grpcserver.New(
+		grpcserver.NewProductService(productRepo, productRepo),
+		grpcserver.AuthConfig{Verifier: verifier, Keys: apiKeys, Disabled: cfg.Auth.Disabled, Tenants: tenants},
+		grpcserver.RateLimitConfig{Store: rateLimits, Runtime: runtime},
+	)
+	components.Register(lifecycle.Component{
+		Name: "grpc",
+		Start: func(ctx context.Context) error {
+			listener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPC.Port))
+			if err != nil {
+				return err
+			}
+			go func() {
+				if err := grpcServer.Serve(listener); err != nil {
+					components.Fail("grpc", err)
+				}
+			}()
+			zap.L().Info("gRPC server started", zap.Int("port", cfg.GRPC.Port))
+			return nil
+		},
+		// Let in-flight RPCs finish, then force close whatever is left
+		Stop: func(ctx context.Context) error {
+			stopped := make(chan struct{})
+			go func() {
+				grpcServer.GracefulStop()
+				close(stopped)
+			}()
+			select {
+			case <-stopped:
+				return nil
+			case <-ctx.Done():
+				grpcServer.Stop()
+				return ctx.Err()
+			}
+		},
+	})
 
-	// Start server
-	go func() {
-		serverAddr := fmt.Sprintf(":%d", cfg.Server.Port)
-		if err := app.Listen(serverAddr); err != nil {
-			zap.L().Fatal("Error starting server", zap.Error(err))
-		}
-	}()
+	// Fail readiness first and keep serving while load balancers take us out
+	// of rotation. Registered last, so it drains first.
+	drainDelay := cfg.Health.DrainDelay
+	components.Register(lifecycle.Component{
+		Name: "health",
+		Drain: func(ctx context.Context) error {
+			healthChecks.Drain()
+			select {
+			case <-time.After(drainDelay):
+			case <-ctx.Done():
+			}
+			return nil
+		},
+		StopTimeout: drainDelay + time.Second,
+	})
 
-	zap.L().Info("Server started", zap.Int("port", cfg.Server.Port))
+	if err := components.Start(); err != nil {
+		components.Shutdown()
+		zap.L().Fatal("Failed to start", zap.Error(err))
+	}
 
-	// Wait for shutdown signal
-	<-shutdownChan
+	failure := components.Wait()
 	zap.L().Info("Shutting down server...")
+	if err := components.Shutdown(); err != nil || failure != nil {
+		log.Sync()
+		os.Exit(1)
+	}
+	zap.L().Info("Server gracefully stopped")
+}
 
-	// Graceful shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	if err := app.ShutdownWithContext(ctx); err != nil {
-		zap.L().Fatal("Server forced to shutdown", zap.Error(err))
+// runCommand runs a subcommand and returns its exit code. "config print"
+// writes the effective configuration as YAML with secrets redacted, followed
+// by any validation errors.
+func runCommand(args []string) int {
+	if len(args) != 2 || args[0] != "config" || args[1] != "print" {
+		fmt.Fprintf(os.Stderr, "usage: %s [config print]\n", os.Args[0])
+		return 2
 	}
 
-	// Close database connection
-	sqlDB, err := db.DB()
+	_, loadErr := config.LoadConfig()
+	out, err := yaml.Marshal(config.RedactedSettings())
 	if err != nil {
-		zap.L().Error("Error getting underlying *sql.DB", zap.Error(err))
-	} else {
-		if err := sqlDB.Close(); err != nil {
-			zap.L().Error("Error closing database connection", zap.Error(err))
-		}
+		fmt.Fprintln(os.Stderr, err)
+		return 1
 	}
+	os.Stdout.Write(out)
 
-	zap.L().Info("Server gracefully stopped")
+	if loadErr != nil {
+		fmt.Fprintln(os.Stderr, loadErr)
+		return 1
+	}
+	return 0
 }
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"context"
+	"time"
+)
+
+// Claims describe the authenticated caller: the verified claims of a bearer
+// token, or the grants of an API key
+type Claims struct {
+	Subject   string
+	Issuer    string
+	Audience  []string
+	ExpiresAt time.Time
+	// Raw holds every claim of the token, including custom ones
+	Raw map[string]interface{}
+	// Permissions are granted outside of Raw, such as API key scopes
+	Permissions []string
+	// Tenant binds the caller to a tenant outside of Raw, as API keys are
+	Tenant string
+	// RateLimitTier is the tier of an API key, empty for tokens
+	RateLimitTier string
+}
+
+// String returns a custom claim, or "" when it is missing or not a string
+func (c *Claims) String(name string) string {
+	value, _ := c.Raw[name].(string)
+	return value
+}
+
+type claimsKey struct{}
+
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// ClaimsFromContext returns the claims of the authenticated caller, if any
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*Claims)
+	return claims, ok
+}
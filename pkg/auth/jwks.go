@@ -0,0 +1,232 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+var errUnknownKey = errors.New("unknown signing key")
+
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type cachedKey struct {
+	alg string
+	key interface{}
+}
+
+// jwksKeySource resolves signing keys from a JSON Web Key Set. Keys are cached
+// for refreshInterval; a token signed with an unknown key ID triggers an early
+// refetch, at most once per minRefreshInterval, so rotated keys are picked up
+// without a restart. When a refetch fails the previous keys keep being used.
+//
+// Fetches run outside the lock, one at a time. While a stale set is
+// refetched, tokens are verified with the cached keys; only those signed
+// with an unknown key wait for the fetch.
+type jwksKeySource struct {
+	url                string
+	client             *http.Client
+	refreshInterval    time.Duration
+	minRefreshInterval time.Duration
+
+	fetches singleflight.Group
+
+	mu          sync.RWMutex
+	keys        map[string]cachedKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+}
+
+func newJWKSKeySource(url string, refreshInterval, minRefreshInterval time.Duration) *jwksKeySource {
+	return &jwksKeySource{
+		url:                url,
+		client:             &http.Client{Timeout: 10 * time.Second},
+		refreshInterval:    refreshInterval,
+		minRefreshInterval: minRefreshInterval,
+	}
+}
+
+func (s *jwksKeySource) Key(ctx context.Context, token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	s.mu.RLock()
+	keys := s.keys
+	canRefresh := time.Since(s.attemptedAt) > s.minRefreshInterval
+	stale := time.Since(s.fetchedAt) > s.refreshInterval
+	s.mu.RUnlock()
+
+	var refreshed <-chan singleflight.Result
+	if stale && canRefresh {
+		refreshed = s.refresh(ctx)
+	}
+
+	key, ok := lookup(keys, kid)
+	if !ok && refreshed == nil && canRefresh {
+		refreshed = s.refresh(ctx)
+	}
+	if !ok && refreshed != nil {
+		select {
+		case res := <-refreshed:
+			key, ok = lookup(res.Val.(map[string]cachedKey), kid)
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if !ok {
+		return nil, errUnknownKey
+	}
+
+	if key.alg != "" && key.alg != token.Method.Alg() {
+		return nil, fmt.Errorf("key %q is not for %s", kid, token.Method.Alg())
+	}
+	return key.key, nil
+}
+
+// lookup finds a key by ID. Tokens without a key ID are accepted when the set has a single key.
+func lookup(keys map[string]cachedKey, kid string) (cachedKey, bool) {
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, true
+		}
+	}
+	key, ok := keys[kid]
+	return key, ok
+}
+
+// refresh starts a fetch unless one is running, or one was attempted within
+// minRefreshInterval. The result is the keys in use once it is done.
+func (s *jwksKeySource) refresh(ctx context.Context) <-chan singleflight.Result {
+	// Detached, as other callers may be waiting for the same fetch
+	ctx = context.WithoutCancel(ctx)
+	return s.fetches.DoChan(s.url, func() (interface{}, error) {
+		s.mu.Lock()
+		if time.Since(s.attemptedAt) <= s.minRefreshInterval {
+			keys := s.keys
+			s.mu.Unlock()
+			return keys, nil
+		}
+		s.attemptedAt = time.Now()
+		s.mu.Unlock()
+
+		keys, err := s.fetch(ctx)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if err != nil {
+			zap.L().Warn("Failed to refresh JWKS, keeping cached keys", zap.String("url", s.url), zap.Error(err))
+			return s.keys, nil
+		}
+		s.keys = keys
+		s.fetchedAt = s.attemptedAt
+		return keys, nil
+	})
+}
+
+func (s *jwksKeySource) fetch(ctx context.Context) (map[string]cachedKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("decode key set: %w", err)
+	}
+
+	keys := make(map[string]cachedKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			zap.L().Warn("Skipping unusable JWKS key", zap.String("kid", jwk.Kid), zap.Error(err))
+			continue
+		}
+		keys[jwk.Kid] = cachedKey{alg: jwk.Alg, key: key}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("key set has no usable signing keys")
+	}
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("decode key parameter: %w", err)
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// APIKeyHeader carries the API key of machine clients
+const APIKeyHeader = "X-API-Key"
+
+// ErrInvalidKey is returned for every API key that is unknown, revoked,
+// expired or malformed, so callers cannot tell which
+var ErrInvalidKey = errors.New("invalid api key")
+
+// KeyAuthenticator resolves an API key to the caller it identifies
+type KeyAuthenticator interface {
+	AuthenticateKey(ctx context.Context, key string) (*Claims, error)
+}
+
+// Middleware authenticates every request not skipped by skip with either an
+// API key or a bearer token, and stores the caller's claims in the request's
+// user context. keys may be nil to accept bearer tokens only.
+func Middleware(verifier *Verifier, keys KeyAuthenticator, skip func(c *fiber.Ctx) bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if skip != nil && skip(c) {
+			return c.Next()
+		}
+
+		claims, err := Authenticate(c.UserContext(), verifier, keys, c.Get(fiber.HeaderAuthorization), c.Get(APIKeyHeader))
+		if err != nil {
+			return err
+		}
+
+		c.SetUserContext(WithClaims(c.UserContext(), claims))
+		return c.Next()
+	}
+}
+
+// Authenticate checks the API key when one is given and the bearer token of
+// the authorization header otherwise. Rejections are a plain 401.
+func Authenticate(ctx context.Context, verifier *Verifier, keys KeyAuthenticator, authorization, key string) (*Claims, error) {
+	if key != "" && keys != nil {
+		claims, err := keys.AuthenticateKey(ctx, key)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidKey) {
+				zap.L().Error("Failed to authenticate API key", zap.Error(err))
+				return nil, fiber.NewError(fiber.StatusInternalServerError, "Internal Server Error")
+			}
+			zap.L().Debug("Rejected API key", zap.Error(err))
+			return nil, fiber.NewError(fiber.StatusUnauthorized, "Unauthorized")
+		}
+		return claims, nil
+	}
+
+	if !strings.HasPrefix(authorization, "Bearer ") {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	claims, err := verifier.Verify(ctx, strings.TrimPrefix(authorization, "Bearer "))
+	if err != nil {
+		zap.L().Debug("Rejected bearer token", zap.Error(err))
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Unauthorized")
+	}
+	return claims, nil
+}
@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Permission is a scope or role a caller must hold, e.g. "products:write"
+type Permission string
+
+// Policy maps request types to the permission they require. Requests of
+// types without a rule are denied.
+type Policy struct {
+	rules   map[reflect.Type]func(req any) Permission
+	implies map[Permission][]Permission
+	// claims are the token claims granting permissions, as a space separated
+	// string or a list of strings
+	claims []string
+}
+
+func NewPolicy(permissionClaims []string) *Policy {
+	return &Policy{
+		rules:   map[reflect.Type]func(req any) Permission{},
+		implies: map[Permission][]Permission{},
+		claims:  permissionClaims,
+	}
+}
+
+// Require sets the permission needed for requests of type R
+func Require[R any](p *Policy, permission Permission) {
+	RequireFunc(p, func(*R) Permission { return permission })
+}
+
+// RequireFunc sets a rule for requests of type R whose permission depends on the request
+func RequireFunc[R any](p *Policy, rule func(req *R) Permission) {
+	p.rules[reflect.TypeFor[R]()] = func(req any) Permission {
+		return rule(req.(*R))
+	}
+}
+
+// Imply makes holders of granted also hold each of implied
+func (p *Policy) Imply(granted Permission, implied ...Permission) {
+	p.implies[granted] = append(p.implies[granted], implied...)
+}
+
+// Covers reports whether the policy has a rule for requests of type t
+func (p *Policy) Covers(t reflect.Type) bool {
+	_, ok := p.rules[t]
+	return ok
+}
+
+// Required returns the permission a request needs. req must be a pointer to a request.
+func (p *Policy) Required(req any) (Permission, bool) {
+	rule, ok := p.rules[reflect.TypeOf(req).Elem()]
+	if !ok {
+		return "", false
+	}
+	return rule(req), true
+}
+
+// Authorize checks the caller's claims against the rule for req, returning a
+// 403 error naming the missing permission
+func (p *Policy) Authorize(ctx context.Context, req any) error {
+	claims, ok := ClaimsFromContext(ctx)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	required, ok := p.Required(req)
+	if !ok {
+		return fiber.NewError(fiber.StatusForbidden, "no authorization policy for this operation")
+	}
+	if !p.Granted(claims, required) {
+		return fiber.NewError(fiber.StatusForbidden, "missing permission "+string(required))
+	}
+	return nil
+}
+
+// Granted reports whether the claims hold the permission, directly or through implication
+func (p *Policy) Granted(claims *Claims, permission Permission) bool {
+	held := p.Permissions(claims)
+	seen := map[Permission]bool{}
+	for len(held) > 0 {
+		current := held[len(held)-1]
+		held = held[:len(held)-1]
+		if current == permission {
+			return true
+		}
+		if seen[current] {
+			continue
+		}
+		seen[current] = true
+		held = append(held, p.implies[current]...)
+	}
+	return false
+}
+
+// Permissions lists the permissions carried directly by the claims
+func (p *Policy) Permissions(claims *Claims) []Permission {
+	permissions := make([]Permission, 0, len(claims.Permissions))
+	for _, permission := range claims.Permissions {
+		permissions = append(permissions, Permission(permission))
+	}
+	for _, name := range p.claims {
+		switch value := claims.Raw[name].(type) {
+		case string:
+			for _, field := range strings.Fields(value) {
+				permissions = append(permissions, Permission(field))
+			}
+		case []interface{}:
+			for _, item := range value {
+				if s, ok := item.(string); ok {
+					permissions = append(permissions, Permission(s))
+				}
+			}
+		case []string:
+			for _, s := range value {
+				permissions = append(permissions, Permission(s))
+			}
+		}
+	}
+	return permissions
+}
@@ -0,0 +1,201 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// ErrInvalidToken is returned for every token that fails verification. The
+// underlying reason is wrapped for logging but must not be shown to callers.
+var ErrInvalidToken = errors.New("invalid token")
+
+// keySource resolves the key that verifies a parsed, not yet verified token
+type keySource interface {
+	Key(ctx context.Context, token *jwt.Token) (interface{}, error)
+}
+
+type staticKey struct {
+	key interface{}
+}
+
+func (s staticKey) Key(context.Context, *jwt.Token) (interface{}, error) {
+	return s.key, nil
+}
+
+// Verifier validates bearer tokens against the configured keys and claims
+type Verifier struct {
+	keys               keySource
+	algorithms         []string
+	issuer             string
+	audience           []string
+	clockSkew          time.Duration
+	allowMissingExpiry bool
+}
+
+func NewVerifier(cfg config.AuthConfig) (*Verifier, error) {
+	v := &Verifier{
+		algorithms:         cfg.Algorithms,
+		issuer:             cfg.Issuer,
+		audience:           cfg.Audience,
+		clockSkew:          cfg.ClockSkew,
+		allowMissingExpiry: cfg.AllowMissingExpiry,
+	}
+
+	sources := 0
+	for _, configured := range []string{cfg.HMACSecret, cfg.HMACSecretFile, cfg.PublicKeyFile, cfg.JWKSURL} {
+		if configured != "" {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return nil, errors.New("auth: configure exactly one of hmacsecret, hmacsecretfile, publickeyfile or jwksurl")
+	}
+
+	var defaultAlgorithms []string
+	switch {
+	case cfg.HMACSecret != "":
+		v.keys = staticKey{key: []byte(cfg.HMACSecret)}
+		defaultAlgorithms = []string{"HS256"}
+	case cfg.HMACSecretFile != "":
+		secret, err := os.ReadFile(cfg.HMACSecretFile)
+		if err != nil {
+			return nil, fmt.Errorf("auth: read hmac secret: %w", err)
+		}
+		v.keys = staticKey{key: []byte(strings.TrimSpace(string(secret)))}
+		defaultAlgorithms = []string{"HS256"}
+	case cfg.PublicKeyFile != "":
+		pem, err := os.ReadFile(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("auth: read public key: %w", err)
+		}
+		key, algorithm, err := parsePublicKey(pem)
+		if err != nil {
+			return nil, fmt.Errorf("auth: %w", err)
+		}
+		v.keys = staticKey{key: key}
+		defaultAlgorithms = []string{algorithm}
+	case cfg.JWKSURL != "":
+		v.keys = newJWKSKeySource(cfg.JWKSURL,
+			cfg.JWKSRefreshInterval,
+			cfg.JWKSMinRefreshInterval)
+		defaultAlgorithms = []string{"RS256", "ES256"}
+	}
+
+	if len(v.algorithms) == 0 {
+		v.algorithms = defaultAlgorithms
+	}
+	return v, nil
+}
+
+// Verify checks the signature and registered claims of a raw token
+func (v *Verifier) Verify(ctx context.Context, raw string) (*Claims, error) {
+	parser := &jwt.Parser{
+		ValidMethods: v.algorithms,
+		// Time based claims are checked below, with clock skew
+		SkipClaimsValidation: true,
+	}
+
+	mapClaims := jwt.MapClaims{}
+	token, err := parser.ParseWithClaims(raw, mapClaims, func(token *jwt.Token) (interface{}, error) {
+		return v.keys.Key(ctx, token)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if !token.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	claims, err := v.validate(mapClaims)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return claims, nil
+}
+
+func (v *Verifier) validate(mapClaims jwt.MapClaims) (*Claims, error) {
+	now := time.Now()
+	claims := &Claims{Raw: mapClaims}
+
+	if exp, ok, err := numericDate(mapClaims, "exp"); err != nil {
+		return nil, err
+	} else if ok {
+		if now.After(exp.Add(v.clockSkew)) {
+			return nil, errors.New("token is expired")
+		}
+		claims.ExpiresAt = exp
+	} else if !v.allowMissingExpiry {
+		return nil, errors.New("token has no expiry")
+	}
+	if nbf, ok, err := numericDate(mapClaims, "nbf"); err != nil {
+		return nil, err
+	} else if ok && now.Add(v.clockSkew).Before(nbf) {
+		return nil, errors.New("token is not valid yet")
+	}
+	if iat, ok, err := numericDate(mapClaims, "iat"); err != nil {
+		return nil, err
+	} else if ok && now.Add(v.clockSkew).Before(iat) {
+		return nil, errors.New("token used before issued")
+	}
+
+	claims.Subject, _ = mapClaims["sub"].(string)
+	claims.Issuer, _ = mapClaims["iss"].(string)
+	if v.issuer != "" && claims.Issuer != v.issuer {
+		return nil, fmt.Errorf("unexpected issuer %q", claims.Issuer)
+	}
+
+	switch aud := mapClaims["aud"].(type) {
+	case string:
+		claims.Audience = []string{aud}
+	case []interface{}:
+		for _, value := range aud {
+			if s, ok := value.(string); ok {
+				claims.Audience = append(claims.Audience, s)
+			}
+		}
+	}
+	if len(v.audience) > 0 && !slices.ContainsFunc(claims.Audience, func(aud string) bool {
+		return slices.Contains(v.audience, aud)
+	}) {
+		return nil, errors.New("token is not meant for this audience")
+	}
+
+	return claims, nil
+}
+
+func numericDate(claims jwt.MapClaims, name string) (time.Time, bool, error) {
+	switch value := claims[name].(type) {
+	case nil:
+		return time.Time{}, false, nil
+	case float64:
+		return time.Unix(int64(value), 0), true, nil
+	default:
+		return time.Time{}, false, fmt.Errorf("claim %s is not a number", name)
+	}
+}
+
+func parsePublicKey(pem []byte) (interface{}, string, error) {
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(pem); err == nil {
+		return key, "RS256", nil
+	}
+	key, err := jwt.ParseECPublicKeyFromPEM(pem)
+	if err != nil {
+		return nil, "", errors.New("public key must be a PEM encoded RSA or EC key")
+	}
+	switch key.Curve.Params().BitSize {
+	case 384:
+		return key, "ES384", nil
+	case 521:
+		return key, "ES512", nil
+	default:
+		return key, "ES256", nil
+	}
+}
@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const testSecret = "test-secret"
+
+func sign(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	raw, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestVerifyExpiry(t *testing.T) {
+	tests := []struct {
+		name               string
+		claims             jwt.MapClaims
+		allowMissingExpiry bool
+		wantErr            bool
+	}{
+		{"valid", jwt.MapClaims{"sub": "user-1", "exp": float64(time.Now().Add(time.Hour).Unix())}, false, false},
+		{"expired", jwt.MapClaims{"sub": "user-1", "exp": float64(time.Now().Add(-time.Hour).Unix())}, false, true},
+		{"no expiry", jwt.MapClaims{"sub": "user-1"}, false, true},
+		{"no expiry allowed", jwt.MapClaims{"sub": "user-1"}, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier, err := NewVerifier(config.AuthConfig{HMACSecret: testSecret, AllowMissingExpiry: tt.allowMissingExpiry})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			claims, err := verifier.Verify(context.Background(), sign(t, tt.claims))
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Fatalf("err = %v, want %v", err, ErrInvalidToken)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if claims.Subject != "user-1" {
+				t.Fatalf("subject %q, want user-1", claims.Subject)
+			}
+		})
+	}
+}
@@ -1,12 +1,51 @@
 package circuitbreaker
 
 import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/health"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/sony/gobreaker"
 	"go.uber.org/zap"
 )
 
+var (
+	state = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "circuit_breaker_state",
+		Help: "State of each circuit breaker: 0 closed, 1 half-open, 2 open.",
+	}, []string{"name"})
+	transitions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "circuit_breaker_transitions_total",
+		Help: "Circuit breaker state changes, by breaker and states.",
+	}, []string{"name", "from", "to"})
+	rejections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "circuit_breaker_rejections_total",
+		Help: "Requests failed fast by an open or half-open circuit breaker.",
+	}, []string{"name"})
+)
+
+var (
+	breakersMu sync.Mutex
+	breakers   = map[string]*gobreaker.CircuitBreaker{}
+
+	// defaults apply to breakers without their own thresholds
+	defaults atomic.Pointer[config.CircuitBreakerConfig]
+)
+
+// SetDefaults changes the trip thresholds of breakers that set none, taking
+// effect on their next failure
+func SetDefaults(cfg config.CircuitBreakerConfig) {
+	defaults.Store(&cfg)
+}
+
 type CircuitBreakerConfig struct {
 	// Name is the identifier for this circuit breaker instance
 	Name string
@@ -20,29 +59,103 @@ type CircuitBreakerConfig struct {
 	// Timeout is the period of the open state, after which the state of the CircuitBreaker becomes half-open
 	Timeout time.Duration
 
-	// RequestsVolumeThreshold is the minimum number of requests needed before the CircuitBreaker can start evaluating failures
+	// RequestsVolumeThreshold is the minimum number of requests needed before the CircuitBreaker can start evaluating failures.
+	// Zero uses the threshold set by SetDefaults.
 	RequestsVolumeThreshold uint32
 
-	// FailureThreshold is the failure rate threshold in percentage (0.0 - 1.0). When the failure rate exceeds this value, the CircuitBreaker trips
+	// FailureThreshold is the failure rate threshold in percentage (0.0 - 1.0). When the failure rate exceeds this value, the CircuitBreaker trips.
+	// Zero uses the threshold set by SetDefaults.
 	FailureThreshold float64
+
+	// IsSuccessful decides whether an error counts as a failure. Nil counts every error.
+	IsSuccessful func(err error) bool
 }
 
 // NewCircuitBreaker creates a new circuit breaker with the given name
 func NewCircuitBreaker(config CircuitBreakerConfig) *gobreaker.CircuitBreaker {
-	return gobreaker.NewCircuitBreaker(gobreaker.Settings{
+	cb := gobreaker.NewCircuitBreaker(gobreaker.Settings{
 		Name:        config.Name,
 		MaxRequests: config.MaxRequests,
 		Interval:    config.Interval,
 		Timeout:     config.Timeout,
 
+		IsSuccessful: config.IsSuccessful,
+
 		ReadyToTrip: func(counts gobreaker.Counts) bool {
+			volumeThreshold, failureThreshold := config.RequestsVolumeThreshold, config.FailureThreshold
+			if d := defaults.Load(); d != nil {
+				if volumeThreshold == 0 {
+					volumeThreshold = d.RequestsVolumeThreshold
+				}
+				if failureThreshold == 0 {
+					failureThreshold = d.FailureThreshold
+				}
+			}
+
+			if counts.Requests == 0 || counts.Requests < volumeThreshold {
+				return false
+			}
 			failureRatio := float64(counts.TotalFailures) / float64(counts.Requests)
-			return counts.Requests >= config.RequestsVolumeThreshold &&
-				failureRatio >= config.FailureThreshold
+			return failureRatio >= failureThreshold
 		},
 
 		OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
 			zap.L().Info("CircuitBreaker state changed", zap.String("name", name), zap.String("from", from.String()), zap.String("to", to.String()))
+			state.WithLabelValues(name).Set(float64(to))
+			transitions.WithLabelValues(name, from.String(), to.String()).Inc()
 		},
 	})
+	state.WithLabelValues(config.Name).Set(float64(cb.State()))
+
+	breakersMu.Lock()
+	breakers[config.Name] = cb
+	breakersMu.Unlock()
+
+	return cb
+}
+
+// unregister forgets a breaker that is no longer used, with its metrics
+func unregister(name string) {
+	breakersMu.Lock()
+	delete(breakers, name)
+	breakersMu.Unlock()
+
+	labels := prometheus.Labels{"name": name}
+	state.DeletePartialMatch(labels)
+	transitions.DeletePartialMatch(labels)
+	rejections.DeletePartialMatch(labels)
+}
+
+// States returns the current state of every circuit breaker by name
+func States() map[string]string {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+
+	states := make(map[string]string, len(breakers))
+	for name, cb := range breakers {
+		states[name] = cb.State().String()
+	}
+	return states
+}
+
+// HealthCheck reports open circuit breakers. It is not critical: an unhealthy
+// downstream must not take every replica out of rotation.
+func HealthCheck() health.Check {
+	return health.Check{
+		Name: "circuit_breakers",
+		Run: func(ctx context.Context) (any, error) {
+			states := States()
+			var open []string
+			for name, state := range states {
+				if state == gobreaker.StateOpen.String() {
+					open = append(open, name)
+				}
+			}
+			if len(open) > 0 {
+				sort.Strings(open)
+				return states, fmt.Errorf("open: %s", strings.Join(open, ", "))
+			}
+			return states, nil
+		},
+	}
 }
@@ -0,0 +1,129 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sony/gobreaker"
+)
+
+// errServerError marks 5xx responses as failures without hiding them from the caller
+var errServerError = errors.New("server error")
+
+// OpenCircuitError is returned without calling the host while its circuit
+// breaker is open, or half-open and already probing
+type OpenCircuitError struct {
+	Breaker string
+	Host    string
+	State   gobreaker.State
+}
+
+func (e *OpenCircuitError) Error() string {
+	return fmt.Sprintf("circuit breaker %s is %s, not calling %s", e.Breaker, e.State, e.Host)
+}
+
+const (
+	// idleTimeout is how long the breaker of a host that is not called is kept
+	idleTimeout = 10 * time.Minute
+	// maxBreakers caps the breakers of a transport; the least recently used
+	// is evicted to make room for another
+	maxBreakers = 256
+)
+
+// Transport is an http.RoundTripper with a circuit breaker per host.
+// Connection errors and 5xx responses count as failures; requests cancelled
+// by the caller count as neither.
+type Transport struct {
+	next     http.RoundTripper
+	settings CircuitBreakerConfig
+
+	mu       sync.Mutex
+	breakers map[string]*hostBreaker
+}
+
+type hostBreaker struct {
+	*gobreaker.CircuitBreaker
+	lastUsed time.Time
+}
+
+// NewTransport wraps next with breakers built from settings. Each is named
+// after settings.Name and the host, e.g. "http:inventory:8080".
+func NewTransport(next http.RoundTripper, settings CircuitBreakerConfig) *Transport {
+	settings.IsSuccessful = func(err error) bool {
+		return err == nil || errors.Is(err, context.Canceled)
+	}
+	return &Transport{
+		next:     next,
+		settings: settings,
+		breakers: map[string]*hostBreaker{},
+	}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cb := t.breaker(req.URL.Host)
+
+	var resp *http.Response
+	_, err := cb.Execute(func() (interface{}, error) {
+		var err error
+		resp, err = t.next.RoundTrip(req)
+		if err == nil && resp.StatusCode >= http.StatusInternalServerError {
+			return nil, errServerError
+		}
+		return nil, err
+	})
+
+	switch {
+	case errors.Is(err, gobreaker.ErrOpenState), errors.Is(err, gobreaker.ErrTooManyRequests):
+		rejections.WithLabelValues(cb.Name()).Inc()
+		return nil, &OpenCircuitError{Breaker: cb.Name(), Host: req.URL.Host, State: cb.State()}
+	case errors.Is(err, errServerError):
+		return resp, nil
+	case err != nil:
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (t *Transport) breaker(host string) *gobreaker.CircuitBreaker {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	cb, ok := t.breakers[host]
+	if !ok {
+		t.evict(now)
+		settings := t.settings
+		settings.Name = t.settings.Name + ":" + host
+		cb = &hostBreaker{CircuitBreaker: NewCircuitBreaker(settings)}
+		t.breakers[host] = cb
+	}
+	cb.lastUsed = now
+	return cb.CircuitBreaker
+}
+
+// evict drops idle breakers, and the least recently used one when there is
+// no room for another
+func (t *Transport) evict(now time.Time) {
+	var oldest string
+	for host, cb := range t.breakers {
+		if now.Sub(cb.lastUsed) > idleTimeout {
+			t.remove(host)
+			continue
+		}
+		if oldest == "" || cb.lastUsed.Before(t.breakers[oldest].lastUsed) {
+			oldest = host
+		}
+	}
+	if len(t.breakers) >= maxBreakers {
+		t.remove(oldest)
+	}
+}
+
+func (t *Transport) remove(host string) {
+	unregister(t.breakers[host].Name())
+	delete(t.breakers, host)
+}
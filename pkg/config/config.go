@@ -2,15 +2,50 @@ package config
 
 import (
 	"fmt"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
+	Log      LogConfig
 	Database DatabaseConfig
 	Server   ServerConfig
+	GRPC     GRPCConfig
 	Jaeger   JaegerConfig
+	Tracing  TracingConfig
+	Health   HealthConfig
+	Jobs     JobsConfig
+	Webhooks WebhooksConfig
+	Stream   StreamConfig
+	GraphQL  GraphQLConfig
+	Auth     AuthConfig
+	Tenancy  TenancyConfig
+	APIKeys  APIKeysConfig
+	// Inventory holds stock reservation settings
+	Inventory InventoryConfig
+	// RateLimit may be overridden per tenant
+	RateLimit RateLimitConfig
+	// Lifecycle bounds starting and stopping the components of the service
+	Lifecycle LifecycleConfig
+	// Features defines feature flags by key
+	Features     map[string]FeatureFlagConfig
+	FeatureFlags FeatureFlagsConfig
+	// CircuitBreaker holds the trip thresholds of breakers that set none
+	CircuitBreaker CircuitBreakerConfig
+	// Downstreams are the services called over HTTP, by name
+	Downstreams map[string]DownstreamConfig
+	// Resilience holds the named policies of outbound HTTP calls
+	Resilience ResilienceConfig
+
+	tenants map[string]*Config
+}
+
+type LogConfig struct {
+	// Level is debug, info, warn or error
+	Level string
 }
 
 type DatabaseConfig struct {
@@ -20,19 +55,334 @@ type DatabaseConfig struct {
 	Password string
 	DBName   string
 	SSLMode  string
+	// LogLevel of SQL logging: silent, error, warn (errors and slow queries) or info (every query)
+	LogLevel string
+	// SlowThreshold marks queries as slow
+	SlowThreshold time.Duration
+	// RedactParams masks bound values in logged SQL
+	RedactParams bool
 }
 
 type ServerConfig struct {
 	Port         int
-	ReadTimeout  int
-	WriteTimeout int
-	IdleTimeout  int
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+}
+
+type GRPCConfig struct {
+	Port int
+}
+
+type JobsConfig struct {
+	Workers      int
+	PollInterval time.Duration
+	BatchSize    int
+	LeaseTimeout time.Duration
+	MaxAttempts  int
+}
+
+type InventoryConfig struct {
+	// ReservationTTL is how long reservations hold stock unless a request asks otherwise
+	ReservationTTL    time.Duration
+	MaxReservationTTL time.Duration
+	// SweepInterval is how often expired reservations are looked for
+	SweepInterval  time.Duration
+	SweepBatchSize int
+}
+
+type WebhooksConfig struct {
+	Concurrency  int
+	PollInterval time.Duration
+	BatchSize    int
+	Timeout      time.Duration // per delivery attempt
+	LeaseTimeout time.Duration
+	MaxAttempts  int // attempts before a delivery is dead-lettered
+	BackoffBase  time.Duration
+	BackoffMax   time.Duration
+	// Policy is the resilience policy of calls to subscribers, less its
+	// circuit breaker. Deliveries are already retried with backoff, so the
+	// default webhooks policy does not retry within an attempt.
+	Policy string
+	// AllowPrivateTargets lets deliveries connect to loopback and private
+	// addresses; for local development only, as subscriptions are tenant supplied
+	AllowPrivateTargets bool
+}
+
+type StreamConfig struct {
+	PollInterval      time.Duration
+	HeartbeatInterval time.Duration
+	BufferSize        int // events buffered per client before it is disconnected
+	BatchSize         int
 }
 
+type GraphQLConfig struct {
+	MaxComplexity   int
+	MaxDepth        int
+	DefaultPageSize int
+	MaxPageSize     int
+	// TraceAllFields also creates spans for plain struct fields, not only for
+	// resolvers. Disable it to cut the spans of large responses.
+	TraceAllFields bool
+}
+
+// AuthConfig configures JWT verification. Exactly one key source must be set
+// unless Disabled: an HMAC secret (inline, e.g. from AUTH_HMACSECRET, or from a
+// file), a PEM encoded RSA or EC public key file, or a JWKS URL.
+type AuthConfig struct {
+	Disabled       bool
+	HMACSecret     string
+	HMACSecretFile string
+	PublicKeyFile  string
+	JWKSURL        string
+	// JWKSRefreshInterval is how long fetched keys are cached
+	JWKSRefreshInterval time.Duration
+	// JWKSMinRefreshInterval limits refetches triggered by unknown key IDs
+	JWKSMinRefreshInterval time.Duration
+	// Algorithms accepted in token headers. Defaults depend on the key source.
+	Algorithms []string
+	Issuer     string
+	// Audience lists accepted audiences; a token must carry at least one of them
+	Audience  []string
+	ClockSkew time.Duration
+	// AllowMissingExpiry accepts tokens without exp, which never expire.
+	// Only for issuers that cannot set it.
+	AllowMissingExpiry bool
+	// PermissionClaims name the claims holding granted scopes and roles
+	PermissionClaims []string
+}
+
+// APIKeysConfig configures API keys for machine clients
+type APIKeysConfig struct {
+	// Tiers lists the rate limit tiers a key can be issued with
+	Tiers       []string
+	DefaultTier string
+	// LastUsedInterval limits how often a key's last use is written
+	LastUsedInterval time.Duration
+}
+
+// RateLimitConfig budgets requests per client: the JWT subject or API key,
+// or the IP address when the caller is not authenticated
+type RateLimitConfig struct {
+	Enabled bool
+	// Store is "memory" for limits per replica or "postgres" to share them
+	Store string
+	// PerIP limits each IP address before authentication, so that guessing
+	// credentials is limited too. Tenant overrides of it have no effect.
+	PerIP RateLimitRule
+	// Default applies to requests no rule matches
+	Default RateLimitRule
+	// Rules are matched in order; each has its own budget per client
+	Rules []RateLimitRule
+	// Tiers multiply the budgets of API keys with that rate limit tier
+	Tiers map[string]float64
+	// IdleTimeout is how long an unused bucket is kept
+	IdleTimeout time.Duration
+}
+
+type RateLimitRule struct {
+	Name string
+	// Methods matched, any when empty
+	Methods []string
+	// Path matched exactly, or as a prefix when it ends with "*"
+	Path string
+	// Rate is in requests per second. Rules without a rate or burst are not limited.
+	Rate  float64
+	Burst int
+}
+
+// TenancyConfig controls how requests are mapped to tenants. The tenant comes
+// from the JWT claim, or is the default for tokens without it; the header must
+// match it. Only with auth disabled does the header pick the tenant.
+type TenancyConfig struct {
+	Claim  string
+	Header string
+	// Default is the tenant of requests that name none. Empty rejects them.
+	Default string
+	// RowLevelSecurity also enforces isolation through Postgres row level
+	// security. The database user must not own the tables or bypass RLS.
+	RowLevelSecurity bool
+	// Tenants lists known tenants besides Default, with overrides of this
+	// configuration such as graphql.maxpagesize. When empty and auth is
+	// disabled, any well formed tenant ID is accepted.
+	Tenants map[string]map[string]interface{}
+}
+
+// JaegerConfig is kept for deployments that still set jaeger.url.
+//
+// Deprecated: use TracingConfig.Endpoint.
 type JaegerConfig struct {
 	URL string `yaml:"url"`
 }
 
+type HealthConfig struct {
+	// Timeout of each readiness check
+	Timeout time.Duration
+	// CacheTTL is how long a check result is reused
+	CacheTTL time.Duration
+	// DrainDelay is how long readiness fails before the server shuts down,
+	// giving load balancers time to stop routing to it
+	DrainDelay time.Duration
+	// PoolSaturation is the share of connections in use, between 0 and 1,
+	// from which the database pool is reported as saturated
+	PoolSaturation float64
+	// OutboxLag is the number of unconsumed events from which webhook
+	// fan-out is reported as lagging
+	OutboxLag int64
+}
+
+type LifecycleConfig struct {
+	// StartTimeout bounds starting each component
+	StartTimeout time.Duration
+	// ShutdownTimeout bounds draining and stopping all components
+	ShutdownTimeout time.Duration
+	// StopTimeout is how long each component gets to drain and to stop
+	StopTimeout time.Duration
+	// Timeouts overrides StopTimeout per component name
+	Timeouts map[string]time.Duration
+}
+
+// FeatureFlagConfig defines a flag in the config file; "key: true" is short
+// for a flag enabled for everyone. Changes saved through the admin API take
+// precedence over the definition here.
+type FeatureFlagConfig struct {
+	Description string
+	Enabled     bool
+	// Rollout is the percentage of callers, besides the targeted ones, the
+	// flag is on for. Unset means everyone.
+	Rollout *int
+	// Tenants and Subjects always get the flag while it is enabled
+	Tenants  []string
+	Subjects []string
+}
+
+type FeatureFlagsConfig struct {
+	// RefreshInterval is how long flags saved through the admin API are
+	// cached, and so how long a change takes to reach other replicas
+	RefreshInterval time.Duration
+}
+
+type DownstreamConfig struct {
+	BaseURL string
+	// Policy names the resilience policy of calls to the downstream
+	Policy string
+}
+
+type ResilienceConfig struct {
+	Policies map[string]ResiliencePolicyConfig
+}
+
+// ResiliencePolicyConfig describes how outbound calls are protected. Calls
+// pass the bulkhead, then retries, then hedging and then the circuit breaker.
+type ResiliencePolicyConfig struct {
+	// Timeout bounds each call, retries included; calls may set a shorter one
+	Timeout time.Duration
+	// AttemptTimeout bounds each attempt; zero leaves only Timeout
+	AttemptTimeout        time.Duration
+	ConnectTimeout        time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+
+	Retry          RetryPolicyConfig
+	Bulkhead       BulkheadConfig
+	CircuitBreaker BreakerPolicyConfig
+	Hedging        HedgingConfig
+}
+
+// RetryPolicyConfig retries connection errors and retryable statuses. Unsafe
+// methods, such as POST, are only retried with an Idempotency-Key header.
+type RetryPolicyConfig struct {
+	MaxRetries int
+	// Backoff is constant, linear or exponential, with jitter, between WaitMin and WaitMax
+	Backoff string
+	WaitMin time.Duration
+	WaitMax time.Duration
+	// RetryableStatuses are the response statuses worth retrying
+	RetryableStatuses []int
+	Budget            RetryBudgetConfig
+}
+
+// RetryBudgetConfig caps retries so they cannot multiply the load on a
+// struggling downstream: each call earns Ratio retries, plus MinPerSecond.
+// A zero Ratio disables the budget.
+type RetryBudgetConfig struct {
+	Ratio        float64
+	MinPerSecond float64
+}
+
+// BulkheadConfig caps concurrent calls; zero MaxConcurrent is unlimited
+type BulkheadConfig struct {
+	MaxConcurrent int
+	// MaxWait is how long a call waits for a slot before it is rejected
+	MaxWait time.Duration
+}
+
+// BreakerPolicyConfig configures a circuit breaker per host. Zero thresholds
+// use those of the circuitbreaker section.
+type BreakerPolicyConfig struct {
+	Enabled bool
+	// MaxRequests are let through while half-open
+	MaxRequests uint32
+	// Interval clears the counts while closed; zero never does
+	Interval time.Duration
+	// Timeout is how long the breaker stays open
+	Timeout                 time.Duration
+	RequestsVolumeThreshold uint32
+	FailureThreshold        float64
+}
+
+// HedgingConfig sends up to MaxHedges extra copies of a slow request, one
+// each Delay, and uses the first response. Only requests that may be retried
+// are hedged; zero MaxHedges disables hedging.
+type HedgingConfig struct {
+	MaxHedges int
+	Delay     time.Duration
+}
+
+type CircuitBreakerConfig struct {
+	// RequestsVolumeThreshold is the number of requests in an interval before
+	// failures are evaluated
+	RequestsVolumeThreshold uint32
+	// FailureThreshold is the failure ratio, between 0 and 1, that trips a breaker
+	FailureThreshold float64
+}
+
+type TracingConfig struct {
+	ServiceName string
+	// ServiceVersion defaults to the module version of the binary
+	ServiceVersion string
+	Environment    string
+	// Exporter is otlphttp, otlpgrpc, stdout, file or none
+	Exporter string
+	// Endpoint is the host:port of the OTLP collector; empty uses the
+	// exporter's default or the OTEL_EXPORTER_OTLP_* environment variables
+	Endpoint string
+	Insecure bool
+	Headers  map[string]string
+	// File receives spans as JSON when Exporter is file
+	File    string
+	Sampler SamplerConfig
+	// ShutdownTimeout bounds flushing buffered spans on exit
+	ShutdownTimeout time.Duration
+}
+
+type SamplerConfig struct {
+	// Ratio of traces sampled when no rule matches, between 0 and 1
+	Ratio float64
+	// ParentBased follows the sampling decision of the caller, so that rules
+	// and the ratio only apply to traces started here
+	ParentBased bool
+	// Rules are matched in order against the span name, which is the request
+	// path for HTTP and the full method for gRPC
+	Rules []SamplingRule
+}
+
+type SamplingRule struct {
+	// Route matched exactly, or as a prefix when it ends with "*"
+	Route string
+	Ratio float64
+}
+
 // LoadConfig reads configuration from file or environment variables.
 func LoadConfig() (*Config, error) {
 	viper.SetConfigName("config") // config file name without extension
@@ -54,18 +404,90 @@ func LoadConfig() (*Config, error) {
 			return nil, fmt.Errorf("error reading config file: %w", err)
 		}
 		// Config file not found; ignore error if desired
-		fmt.Println("No config file found. Using environment variables and defaults.")
+		fmt.Fprintln(os.Stderr, "No config file found. Using environment variables and defaults.")
+	}
+
+	config, err := decode()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+
+	return config, nil
+}
+
+// decode builds the configuration from the settings viper has read
+func decode() (*Config, error) {
+	if err := loadSecretFiles(); err != nil {
+		return nil, fmt.Errorf("error reading secret files: %w", err)
 	}
 
 	var config Config
-	if err := viper.Unmarshal(&config); err != nil {
+	if err := viper.Unmarshal(&config, decodeHook()); err != nil {
 		return nil, fmt.Errorf("unable to decode config into struct: %w", err)
 	}
 
+	if config.Tracing.Endpoint == "" {
+		config.Tracing.Endpoint = config.Jaeger.URL
+	}
+
+	if err := config.loadTenants(); err != nil {
+		return nil, err
+	}
 	return &config, nil
 }
 
+// loadTenants builds each tenant's configuration from the global settings
+// with the tenant's overrides merged on top
+func (c *Config) loadTenants() error {
+	c.tenants = make(map[string]*Config, len(c.Tenancy.Tenants))
+	for id, overrides := range c.Tenancy.Tenants {
+		v := viper.New()
+		if err := v.MergeConfigMap(viper.AllSettings()); err != nil {
+			return fmt.Errorf("tenant %s: %w", id, err)
+		}
+		if err := v.MergeConfigMap(overrides); err != nil {
+			return fmt.Errorf("tenant %s: %w", id, err)
+		}
+
+		var tenantConfig Config
+		if err := v.Unmarshal(&tenantConfig, decodeHook()); err != nil {
+			return fmt.Errorf("tenant %s: unable to decode overrides: %w", id, err)
+		}
+		c.tenants[id] = &tenantConfig
+	}
+	return nil
+}
+
+// ForTenant returns the configuration of a tenant, which is c itself for
+// tenants without overrides
+func (c *Config) ForTenant(id string) *Config {
+	if tenantConfig, ok := c.tenants[id]; ok {
+		return tenantConfig
+	}
+	return c
+}
+
+// KnownTenant reports whether id may be used: the default tenant, a listed
+// tenant, or any tenant when none are listed and auth is disabled
+func (c *Config) KnownTenant(id string) bool {
+	if id == c.Tenancy.Default {
+		return true
+	}
+	if len(c.Tenancy.Tenants) == 0 {
+		return c.Auth.Disabled
+	}
+	_, ok := c.Tenancy.Tenants[id]
+	return ok
+}
+
 func setDefaults() {
+	// Log defaults
+	viper.SetDefault("log.level", "info")
+
 	// Database defaults
 	viper.SetDefault("database.host", "localhost")
 	viper.SetDefault("database.port", 5432)
@@ -73,12 +495,158 @@ func setDefaults() {
 	viper.SetDefault("database.password", "postgres")
 	viper.SetDefault("database.dbname", "postgres")
 	viper.SetDefault("database.sslmode", "disable")
+	viper.SetDefault("database.loglevel", "warn")
+	viper.SetDefault("database.slowthreshold", "200ms")
+	viper.SetDefault("database.redactparams", true)
 
 	// Server defaults
 	viper.SetDefault("server.port", 8080)
-	viper.SetDefault("server.readtimeout", 15)  // seconds
-	viper.SetDefault("server.writetimeout", 15) // seconds
-	viper.SetDefault("server.idletimeout", 60)  // seconds
+	viper.SetDefault("server.readtimeout", "15s")
+	viper.SetDefault("server.writetimeout", "15s")
+	viper.SetDefault("server.idletimeout", "60s")
+
+	// gRPC defaults
+	viper.SetDefault("grpc.port", 50051)
+
+	// Background job defaults
+	viper.SetDefault("jobs.workers", 2)
+	viper.SetDefault("jobs.pollinterval", "1s")
+	viper.SetDefault("jobs.batchsize", 100)
+	viper.SetDefault("jobs.leasetimeout", "30s")
+	viper.SetDefault("jobs.maxattempts", 3)
+
+	// Stock reservation defaults
+	viper.SetDefault("inventory.reservationttl", "15m")
+	viper.SetDefault("inventory.maxreservationttl", "24h")
+	viper.SetDefault("inventory.sweepinterval", "30s")
+	viper.SetDefault("inventory.sweepbatchsize", 100)
+
+	// Webhook delivery defaults
+	viper.SetDefault("webhooks.concurrency", 4)
+	viper.SetDefault("webhooks.pollinterval", "2s")
+	viper.SetDefault("webhooks.batchsize", 100)
+	viper.SetDefault("webhooks.timeout", "10s")
+	viper.SetDefault("webhooks.leasetimeout", "60s")
+	viper.SetDefault("webhooks.maxattempts", 8)
+	viper.SetDefault("webhooks.backoffbase", "5s")
+	viper.SetDefault("webhooks.backoffmax", "1h")
+	viper.SetDefault("webhooks.policy", "webhooks")
+	viper.SetDefault("webhooks.allowprivatetargets", false)
+
+	// Product change stream defaults
+	viper.SetDefault("stream.pollinterval", "500ms")
+	viper.SetDefault("stream.heartbeatinterval", "15s")
+	viper.SetDefault("stream.buffersize", 256)
+	viper.SetDefault("stream.batchsize", 500)
+
+	// GraphQL defaults
+	viper.SetDefault("graphql.maxcomplexity", 1000)
+	viper.SetDefault("graphql.maxdepth", 10)
+	viper.SetDefault("graphql.defaultpagesize", 20)
+	viper.SetDefault("graphql.maxpagesize", 100)
+	viper.SetDefault("graphql.traceallfields", true)
+
+	// Auth defaults
+	viper.SetDefault("auth.disabled", false)
+	viper.SetDefault("auth.hmacsecret", "")
+	viper.SetDefault("auth.hmacsecretfile", "")
+	viper.SetDefault("auth.publickeyfile", "")
+	viper.SetDefault("auth.jwksurl", "")
+	viper.SetDefault("auth.jwksrefreshinterval", "1h")
+	viper.SetDefault("auth.jwksminrefreshinterval", "30s")
+	viper.SetDefault("auth.algorithms", []string{})
+	viper.SetDefault("auth.issuer", "")
+	viper.SetDefault("auth.audience", []string{})
+	viper.SetDefault("auth.clockskew", "30s")
+	viper.SetDefault("auth.allowmissingexpiry", false)
+	viper.SetDefault("auth.permissionclaims", []string{"scope", "scp", "permissions", "roles"})
+
+	// API key defaults
+	viper.SetDefault("apikeys.tiers", []string{"standard", "elevated"})
+	viper.SetDefault("apikeys.defaulttier", "standard")
+	viper.SetDefault("apikeys.lastusedinterval", "1m")
+
+	// Rate limit defaults
+	viper.SetDefault("ratelimit.enabled", true)
+	viper.SetDefault("ratelimit.store", "memory")
+	viper.SetDefault("ratelimit.perip", map[string]interface{}{"name": "per-ip", "rate": 50, "burst": 100})
+	viper.SetDefault("ratelimit.default", map[string]interface{}{"name": "default", "rate": 20, "burst": 40})
+	viper.SetDefault("ratelimit.rules", []map[string]interface{}{
+		// Listing is the most expensive read
+		{"name": "list-products", "methods": []string{"GET"}, "path": "/api/v1/products", "rate": 5, "burst": 10},
+		{"name": "writes", "methods": []string{"POST", "PUT", "PATCH", "DELETE"}, "path": "/api/*", "rate": 2, "burst": 10},
+	})
+	viper.SetDefault("ratelimit.tiers", map[string]float64{"standard": 1, "elevated": 5})
+	viper.SetDefault("ratelimit.idletimeout", "10m")
+
+	// Health check defaults
+	viper.SetDefault("health.timeout", "2s")
+	viper.SetDefault("health.cachettl", "2s")
+	viper.SetDefault("health.draindelay", "5s")
+	viper.SetDefault("health.poolsaturation", 0.9)
+	viper.SetDefault("health.outboxlag", 1000)
+
+	// Lifecycle defaults
+	viper.SetDefault("lifecycle.starttimeout", "15s")
+	viper.SetDefault("lifecycle.shutdowntimeout", "60s")
+	viper.SetDefault("lifecycle.stoptimeout", "10s")
+
+	// Downstream defaults
+	viper.SetDefault("downstreams.demo.baseurl", "http://localhost:8081")
+	viper.SetDefault("downstreams.demo.policy", "default")
+
+	// Resilience defaults
+	viper.SetDefault("resilience.policies.default.timeout", "10s")
+	viper.SetDefault("resilience.policies.default.attempttimeout", "0s")
+	viper.SetDefault("resilience.policies.default.connecttimeout", "30s")
+	viper.SetDefault("resilience.policies.default.tlshandshaketimeout", "10s")
+	viper.SetDefault("resilience.policies.default.responseheadertimeout", "10s")
+	viper.SetDefault("resilience.policies.default.retry.maxretries", 3)
+	viper.SetDefault("resilience.policies.default.retry.backoff", "linear")
+	viper.SetDefault("resilience.policies.default.retry.waitmin", "100ms")
+	viper.SetDefault("resilience.policies.default.retry.waitmax", "10s")
+	viper.SetDefault("resilience.policies.default.retry.retryablestatuses", []int{429, 500, 502, 503, 504})
+	viper.SetDefault("resilience.policies.default.retry.budget.ratio", 0.2)
+	viper.SetDefault("resilience.policies.default.retry.budget.minpersecond", 1)
+	viper.SetDefault("resilience.policies.default.bulkhead.maxconcurrent", 0)
+	viper.SetDefault("resilience.policies.default.bulkhead.maxwait", "0s")
+	viper.SetDefault("resilience.policies.default.circuitbreaker.enabled", true)
+	viper.SetDefault("resilience.policies.default.circuitbreaker.maxrequests", 1)
+	viper.SetDefault("resilience.policies.default.circuitbreaker.interval", "60s")
+	viper.SetDefault("resilience.policies.default.circuitbreaker.timeout", "30s")
+	viper.SetDefault("resilience.policies.default.hedging.maxhedges", 0)
+	viper.SetDefault("resilience.policies.default.hedging.delay", "0s")
+	viper.SetDefault("resilience.policies.webhooks.timeout", "10s")
+	viper.SetDefault("resilience.policies.webhooks.connecttimeout", "10s")
+	viper.SetDefault("resilience.policies.webhooks.tlshandshaketimeout", "10s")
+	viper.SetDefault("resilience.policies.webhooks.responseheadertimeout", "10s")
+	viper.SetDefault("resilience.policies.webhooks.retry.maxretries", 0)
+
+	// Feature flag defaults
+	viper.SetDefault("featureflags.refreshinterval", "10s")
+
+	// Circuit breaker defaults
+	viper.SetDefault("circuitbreaker.requestsvolumethreshold", 20)
+	viper.SetDefault("circuitbreaker.failurethreshold", 0.5)
+
+	// Tracing defaults
+	viper.SetDefault("tracing.servicename", "golang-cqrs-ddd-poc")
+	viper.SetDefault("tracing.serviceversion", "")
+	viper.SetDefault("tracing.environment", "development")
+	viper.SetDefault("tracing.exporter", "otlphttp")
+	viper.SetDefault("tracing.endpoint", "")
+	viper.SetDefault("tracing.insecure", true)
+	viper.SetDefault("tracing.file", "traces.json")
+	viper.SetDefault("tracing.sampler.ratio", 1.0)
+	viper.SetDefault("tracing.sampler.parentbased", true)
+	viper.SetDefault("tracing.sampler.rules", []map[string]interface{}{})
+	viper.SetDefault("tracing.shutdowntimeout", "5s")
+
+	// Tenancy defaults
+	viper.SetDefault("tenancy.claim", "tenant_id")
+	viper.SetDefault("tenancy.header", "X-Tenant-ID")
+	viper.SetDefault("tenancy.default", "default")
+	viper.SetDefault("tenancy.rowlevelsecurity", false)
 }
 
 // GetDSN returns the PostgreSQL DSN string
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// Reloadable lists the settings applied without a restart when the config
+// file changes. Changes to any other setting are ignored until then.
+var Reloadable = []string{"log.level", "tracing.sampler", "ratelimit", "features", "circuitbreaker"}
+
+// withReloadable returns a copy of c with the reloadable settings of src
+func (c *Config) withReloadable(src *Config) *Config {
+	merged := *c
+	merged.Log = src.Log
+	merged.Tracing.Sampler = src.Tracing.Sampler
+	merged.RateLimit = src.RateLimit
+	merged.Features = src.Features
+	merged.CircuitBreaker = src.CircuitBreaker
+	return &merged
+}
+
+// RuntimeStatus describes the active configuration
+type RuntimeStatus struct {
+	Config   *Config
+	LoadedAt time.Time
+	// ReloadedAt is zero until a reload is applied
+	ReloadedAt time.Time
+	Reloads    int
+	// LastError is the reason the last reload was rejected, if it was
+	LastError string
+}
+
+// Runtime holds the active configuration and replaces it when the config
+// file changes. Readers always see either the old or the new configuration,
+// never a mix.
+type Runtime struct {
+	current atomic.Pointer[Config]
+
+	mu          sync.Mutex
+	subscribers []func(old, next *Config)
+	loadedAt    time.Time
+	reloadedAt  time.Time
+	reloads     int
+	lastError   string
+}
+
+func NewRuntime(cfg *Config) *Runtime {
+	r := &Runtime{loadedAt: time.Now()}
+	r.current.Store(cfg)
+	return r
+}
+
+// Config returns the active configuration. Callers must not keep it across
+// requests or loop iterations if they want to follow reloads.
+func (r *Runtime) Config() *Config {
+	return r.current.Load()
+}
+
+// Subscribe registers fn to be called after each applied reload
+func (r *Runtime) Subscribe(fn func(old, next *Config)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscribers = append(r.subscribers, fn)
+}
+
+// Status returns the active configuration and its reload history
+func (r *Runtime) Status() RuntimeStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return RuntimeStatus{
+		Config:     r.Config(),
+		LoadedAt:   r.loadedAt,
+		ReloadedAt: r.reloadedAt,
+		Reloads:    r.reloads,
+		LastError:  r.lastError,
+	}
+}
+
+// Watch reloads the configuration whenever the config file changes. It does
+// nothing when the configuration came from defaults and the environment only.
+func (r *Runtime) Watch() {
+	if viper.ConfigFileUsed() == "" {
+		zap.L().Info("No config file to watch, settings are not reloaded")
+		return
+	}
+	viper.OnConfigChange(func(event fsnotify.Event) {
+		// Editors that truncate before writing trigger an event for the empty
+		// file, which would otherwise reload the defaults
+		if info, err := os.Stat(event.Name); err == nil && info.Size() == 0 {
+			return
+		}
+		r.Reload()
+	})
+	viper.WatchConfig()
+	zap.L().Info("Watching config file for changes", zap.String("file", viper.ConfigFileUsed()), zap.Strings("reloadable", Reloadable))
+}
+
+// Reload applies the reloadable settings from the current viper state. The
+// resulting configuration is validated as a whole and rejected if invalid.
+func (r *Runtime) Reload() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fresh, err := decode()
+	if err != nil {
+		r.reject(err)
+		return
+	}
+
+	old := r.Config()
+	next := old.withReloadable(fresh)
+	next.tenants = make(map[string]*Config, len(old.tenants))
+	for id, tenantConfig := range old.tenants {
+		if freshTenant, ok := fresh.tenants[id]; ok {
+			next.tenants[id] = tenantConfig.withReloadable(freshTenant)
+		} else {
+			next.tenants[id] = tenantConfig
+		}
+	}
+	if err := next.Validate(); err != nil {
+		r.reject(err)
+		return
+	}
+	r.lastError = ""
+
+	if !sameSettings(*old, *fresh) {
+		zap.L().Warn("Config file changed settings that are only applied on restart")
+	}
+	if reflect.DeepEqual(old, next) {
+		return
+	}
+
+	r.current.Store(next)
+	r.reloadedAt = time.Now()
+	r.reloads++
+	zap.L().Info("Configuration reloaded", zap.Int("reloads", r.reloads))
+
+	for _, fn := range r.subscribers {
+		fn(old, next)
+	}
+}
+
+func (r *Runtime) reject(err error) {
+	r.lastError = err.Error()
+	zap.L().Error("Rejected configuration reload, keeping the active settings", zap.Error(err))
+}
+
+// sameSettings reports whether a and b only differ in reloadable settings
+func sameSettings(a, b Config) bool {
+	a.tenants, b.tenants = nil, nil
+	return reflect.DeepEqual(a.withReloadable(&b), &b)
+}
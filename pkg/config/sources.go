@@ -0,0 +1,132 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+)
+
+// Redacted replaces secrets in the output of Redacted
+const Redacted = "[redacted]"
+
+// secretKeys are masked when the configuration is printed
+var secretKeys = []string{
+	"database.password",
+	"auth.hmacsecret",
+	// Collector headers usually carry API tokens
+	"tracing.headers",
+}
+
+// decodeHook parses durations such as "15s". Bare numbers are rejected rather
+// than silently read as nanoseconds.
+func decodeHook() viper.DecoderConfigOption {
+	return viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		rejectBareDurations,
+		featureFlagShorthand,
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+	))
+}
+
+func rejectBareDurations(from, to reflect.Type, data interface{}) (interface{}, error) {
+	if to != reflect.TypeOf(time.Duration(0)) {
+		return data, nil
+	}
+	switch from.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if !reflect.ValueOf(data).IsZero() {
+			return nil, fmt.Errorf("duration %v has no unit, use e.g. \"%vs\" or \"%vms\"", data, data, data)
+		}
+	}
+	return data, nil
+}
+
+// featureFlagShorthand reads "key: true" as a flag enabled for everyone
+func featureFlagShorthand(from, to reflect.Type, data interface{}) (interface{}, error) {
+	if to != reflect.TypeOf(FeatureFlagConfig{}) || from.Kind() != reflect.Bool {
+		return data, nil
+	}
+	return map[string]interface{}{"enabled": data}, nil
+}
+
+// loadSecretFiles sets each setting whose environment variable has a _FILE
+// variant, e.g. DATABASE_PASSWORD_FILE, to the contents of the named file.
+// It takes precedence over the config file and the plain variable.
+func loadSecretFiles() error {
+	var errs []error
+	for _, key := range viper.AllKeys() {
+		env := strings.ToUpper(strings.ReplaceAll(key, ".", "_")) + "_FILE"
+		path := os.Getenv(env)
+		if path == "" {
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", env, err))
+			continue
+		}
+		viper.Set(key, strings.TrimRight(string(data), "\r\n"))
+	}
+	return errors.Join(errs...)
+}
+
+// RedactedSettings returns the effective settings with secrets masked, for
+// printing, including those in tenant overrides. Empty secrets are left as is
+// so a missing one stands out.
+func RedactedSettings() map[string]interface{} {
+	settings := viper.AllSettings()
+	scopes := []map[string]interface{}{settings}
+	if tenancy, ok := settings["tenancy"].(map[string]interface{}); ok {
+		if tenants, ok := tenancy["tenants"].(map[string]interface{}); ok {
+			for _, overrides := range tenants {
+				if overrides, ok := overrides.(map[string]interface{}); ok {
+					scopes = append(scopes, overrides)
+				}
+			}
+		}
+	}
+
+	for _, scope := range scopes {
+		for _, key := range secretKeys {
+			redact(scope, strings.Split(key, "."))
+		}
+	}
+	return settings
+}
+
+func redact(settings map[string]interface{}, path []string) {
+	value, ok := settings[path[0]]
+	if !ok {
+		return
+	}
+	if len(path) > 1 {
+		if nested, ok := value.(map[string]interface{}); ok {
+			redact(nested, path[1:])
+		}
+		return
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k := range v {
+			v[k] = Redacted
+		}
+	case map[string]string:
+		for k := range v {
+			v[k] = Redacted
+		}
+	default:
+		if value != nil && fmt.Sprint(value) != "" {
+			settings[path[0]] = Redacted
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestRedactedSettingsMasksTenantSecrets(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(`
+database:
+  password: global-password
+auth:
+  hmacsecret: global-secret
+tenancy:
+  tenants:
+    acme:
+      database:
+        password: acme-password
+      auth:
+        hmacsecret: acme-secret
+    globex:
+      graphql:
+        maxpagesize: 50
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	settings := RedactedSettings()
+	tenants := settings["tenancy"].(map[string]interface{})["tenants"].(map[string]interface{})
+	for name, scope := range map[string]interface{}{"global": settings, "acme": tenants["acme"]} {
+		scope := scope.(map[string]interface{})
+		if got := scope["database"].(map[string]interface{})["password"]; got != Redacted {
+			t.Errorf("%s database.password = %v, want %s", name, got, Redacted)
+		}
+		if got := scope["auth"].(map[string]interface{})["hmacsecret"]; got != Redacted {
+			t.Errorf("%s auth.hmacsecret = %v, want %s", name, got, Redacted)
+		}
+	}
+
+	globex := tenants["globex"].(map[string]interface{})
+	if got := globex["graphql"].(map[string]interface{})["maxpagesize"]; got != 50 {
+		t.Errorf("globex graphql.maxpagesize = %v, want 50", got)
+	}
+	if _, ok := globex["database"]; ok {
+		t.Error("redaction added database settings to globex")
+	}
+}
@@ -0,0 +1,239 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// validator collects every problem so they can all be fixed at once
+type validator struct {
+	errs []error
+}
+
+func (v *validator) check(ok bool, key, problem string) {
+	if !ok {
+		v.errs = append(v.errs, fmt.Errorf("%s: %s", key, problem))
+	}
+}
+
+func (v *validator) port(key string, port int) {
+	v.check(port > 0 && port <= 65535, key, fmt.Sprintf("must be a port between 1 and 65535, got %d", port))
+}
+
+func (v *validator) positive(key string, n int) {
+	v.check(n > 0, key, fmt.Sprintf("must be positive, got %d", n))
+}
+
+func (v *validator) duration(key string, d time.Duration) {
+	v.check(d > 0, key, fmt.Sprintf("must be a positive duration, got %s", d))
+}
+
+func (v *validator) oneOf(key, value string, allowed ...string) {
+	v.check(slices.Contains(allowed, value), key, fmt.Sprintf("must be one of %s, got %q", strings.Join(allowed, ", "), value))
+}
+
+func (v *validator) ratio(key string, r float64) {
+	v.check(r >= 0 && r <= 1, key, fmt.Sprintf("must be between 0 and 1, got %g", r))
+}
+
+func (v *validator) policy(key, name string, policies map[string]ResiliencePolicyConfig) {
+	_, ok := policies[name]
+	v.check(ok, key, fmt.Sprintf("names no policy in resilience.policies, got %q", name))
+}
+
+func (v *validator) resiliencePolicy(key string, p ResiliencePolicyConfig) {
+	v.duration(key+".timeout", p.Timeout)
+	v.check(p.AttemptTimeout >= 0, key+".attempttimeout", "must not be negative")
+	v.duration(key+".connecttimeout", p.ConnectTimeout)
+	v.duration(key+".tlshandshaketimeout", p.TLSHandshakeTimeout)
+	v.duration(key+".responseheadertimeout", p.ResponseHeaderTimeout)
+
+	v.check(p.Retry.MaxRetries >= 0, key+".retry.maxretries", "must not be negative")
+	if p.Retry.MaxRetries > 0 {
+		v.oneOf(key+".retry.backoff", p.Retry.Backoff, "constant", "linear", "exponential")
+		v.duration(key+".retry.waitmin", p.Retry.WaitMin)
+		v.check(p.Retry.WaitMax >= p.Retry.WaitMin, key+".retry.waitmax", "must not be less than retry.waitmin")
+	}
+	for _, status := range p.Retry.RetryableStatuses {
+		v.check(status >= 100 && status <= 599, key+".retry.retryablestatuses", fmt.Sprintf("must be HTTP statuses, got %d", status))
+	}
+	v.check(p.Retry.Budget.Ratio >= 0 && p.Retry.Budget.MinPerSecond >= 0, key+".retry.budget", "ratio and minpersecond must not be negative")
+
+	v.check(p.Bulkhead.MaxConcurrent >= 0, key+".bulkhead.maxconcurrent", "must not be negative")
+	v.check(p.Bulkhead.MaxWait >= 0, key+".bulkhead.maxwait", "must not be negative")
+
+	if p.CircuitBreaker.Enabled {
+		v.check(p.CircuitBreaker.Interval >= 0, key+".circuitbreaker.interval", "must not be negative")
+		v.duration(key+".circuitbreaker.timeout", p.CircuitBreaker.Timeout)
+		v.check(p.CircuitBreaker.FailureThreshold >= 0 && p.CircuitBreaker.FailureThreshold <= 1, key+".circuitbreaker.failurethreshold", fmt.Sprintf("must be between 0 and 1, got %g", p.CircuitBreaker.FailureThreshold))
+	}
+
+	v.check(p.Hedging.MaxHedges >= 0, key+".hedging.maxhedges", "must not be negative")
+	if p.Hedging.MaxHedges > 0 {
+		v.duration(key+".hedging.delay", p.Hedging.Delay)
+	}
+}
+
+// Validate reports every invalid setting at once. Tenant overrides are only
+// checked once the global settings are valid, so a global mistake is not
+// repeated for each tenant.
+func (c *Config) Validate() error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(c.tenants))
+	for id := range c.tenants {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var errs []error
+	for _, id := range ids {
+		if err := c.tenants[id].validate(); err != nil {
+			errs = append(errs, fmt.Errorf("tenancy.tenants.%s: %w", id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Config) validate() error {
+	v := &validator{}
+
+	v.oneOf("log.level", c.Log.Level, "debug", "info", "warn", "error")
+
+	v.check(c.Database.Host != "", "database.host", "is required")
+	v.port("database.port", c.Database.Port)
+	v.check(c.Database.User != "", "database.user", "is required")
+	v.check(c.Database.DBName != "", "database.dbname", "is required")
+	v.oneOf("database.sslmode", c.Database.SSLMode, "disable", "allow", "prefer", "require", "verify-ca", "verify-full")
+	v.oneOf("database.loglevel", c.Database.LogLevel, "silent", "error", "warn", "info")
+	v.duration("database.slowthreshold", c.Database.SlowThreshold)
+
+	v.port("server.port", c.Server.Port)
+	v.check(c.Server.ReadTimeout >= 0, "server.readtimeout", "must not be negative")
+	v.check(c.Server.WriteTimeout >= 0, "server.writetimeout", "must not be negative")
+	v.check(c.Server.IdleTimeout >= 0, "server.idletimeout", "must not be negative")
+	v.port("grpc.port", c.GRPC.Port)
+	v.check(c.GRPC.Port != c.Server.Port, "grpc.port", "must differ from server.port")
+
+	v.positive("jobs.workers", c.Jobs.Workers)
+	v.duration("jobs.pollinterval", c.Jobs.PollInterval)
+	v.positive("jobs.batchsize", c.Jobs.BatchSize)
+	v.duration("jobs.leasetimeout", c.Jobs.LeaseTimeout)
+	v.positive("jobs.maxattempts", c.Jobs.MaxAttempts)
+
+	v.duration("inventory.reservationttl", c.Inventory.ReservationTTL)
+	v.check(c.Inventory.MaxReservationTTL >= c.Inventory.ReservationTTL, "inventory.maxreservationttl", "must not be less than inventory.reservationttl")
+	v.duration("inventory.sweepinterval", c.Inventory.SweepInterval)
+	v.positive("inventory.sweepbatchsize", c.Inventory.SweepBatchSize)
+
+	v.positive("webhooks.concurrency", c.Webhooks.Concurrency)
+	v.duration("webhooks.pollinterval", c.Webhooks.PollInterval)
+	v.positive("webhooks.batchsize", c.Webhooks.BatchSize)
+	v.duration("webhooks.timeout", c.Webhooks.Timeout)
+	v.check(c.Webhooks.LeaseTimeout > c.Webhooks.Timeout, "webhooks.leasetimeout", "must exceed webhooks.timeout, or deliveries are retried while still in flight")
+	v.positive("webhooks.maxattempts", c.Webhooks.MaxAttempts)
+	v.duration("webhooks.backoffbase", c.Webhooks.BackoffBase)
+	v.check(c.Webhooks.BackoffMax >= c.Webhooks.BackoffBase, "webhooks.backoffmax", "must not be less than webhooks.backoffbase")
+
+	v.duration("stream.pollinterval", c.Stream.PollInterval)
+	v.duration("stream.heartbeatinterval", c.Stream.HeartbeatInterval)
+	v.positive("stream.buffersize", c.Stream.BufferSize)
+	v.positive("stream.batchsize", c.Stream.BatchSize)
+
+	v.positive("graphql.maxcomplexity", c.GraphQL.MaxComplexity)
+	v.positive("graphql.maxdepth", c.GraphQL.MaxDepth)
+	v.positive("graphql.defaultpagesize", c.GraphQL.DefaultPageSize)
+	v.check(c.GraphQL.MaxPageSize >= c.GraphQL.DefaultPageSize, "graphql.maxpagesize", "must not be less than graphql.defaultpagesize")
+
+	if !c.Auth.Disabled {
+		sources := 0
+		for _, configured := range []string{c.Auth.HMACSecret, c.Auth.HMACSecretFile, c.Auth.PublicKeyFile, c.Auth.JWKSURL} {
+			if configured != "" {
+				sources++
+			}
+		}
+		v.check(sources == 1, "auth", "configure exactly one of hmacsecret, hmacsecretfile, publickeyfile or jwksurl, or set disabled")
+		if c.Auth.JWKSURL != "" {
+			v.duration("auth.jwksrefreshinterval", c.Auth.JWKSRefreshInterval)
+			v.duration("auth.jwksminrefreshinterval", c.Auth.JWKSMinRefreshInterval)
+		}
+	}
+	v.check(c.Auth.ClockSkew >= 0, "auth.clockskew", "must not be negative")
+
+	v.check(slices.Contains(c.APIKeys.Tiers, c.APIKeys.DefaultTier), "apikeys.defaulttier", fmt.Sprintf("must be one of apikeys.tiers, got %q", c.APIKeys.DefaultTier))
+	v.duration("apikeys.lastusedinterval", c.APIKeys.LastUsedInterval)
+
+	v.oneOf("ratelimit.store", c.RateLimit.Store, "memory", "postgres")
+	for i, rule := range append([]RateLimitRule{c.RateLimit.Default}, c.RateLimit.Rules...) {
+		key := "ratelimit.default"
+		if i > 0 {
+			key = fmt.Sprintf("ratelimit.rules[%d]", i-1)
+		}
+		v.check(rule.Rate >= 0 && rule.Burst >= 0, key, "rate and burst must not be negative")
+	}
+	v.check(c.RateLimit.PerIP.Rate >= 0 && c.RateLimit.PerIP.Burst >= 0, "ratelimit.perip", "rate and burst must not be negative")
+	for tier, multiplier := range c.RateLimit.Tiers {
+		v.check(multiplier > 0, "ratelimit.tiers."+tier, fmt.Sprintf("must be positive, got %g", multiplier))
+	}
+	v.duration("ratelimit.idletimeout", c.RateLimit.IdleTimeout)
+
+	v.check(c.Tenancy.Claim != "", "tenancy.claim", "is required")
+	v.check(c.Tenancy.Header != "", "tenancy.header", "is required")
+	v.check(c.Auth.Disabled || c.Tenancy.Default != "" || len(c.Tenancy.Tenants) > 0, "tenancy.tenants", "must list the known tenants when auth is enabled")
+
+	v.duration("health.timeout", c.Health.Timeout)
+	v.check(c.Health.CacheTTL >= 0, "health.cachettl", "must not be negative")
+	v.check(c.Health.DrainDelay >= 0, "health.draindelay", "must not be negative")
+	v.check(c.Health.PoolSaturation > 0 && c.Health.PoolSaturation <= 1, "health.poolsaturation", fmt.Sprintf("must be above 0 and at most 1, got %g", c.Health.PoolSaturation))
+	v.check(c.Health.OutboxLag > 0, "health.outboxlag", fmt.Sprintf("must be positive, got %d", c.Health.OutboxLag))
+
+	v.duration("lifecycle.starttimeout", c.Lifecycle.StartTimeout)
+	v.duration("lifecycle.shutdowntimeout", c.Lifecycle.ShutdownTimeout)
+	v.duration("lifecycle.stoptimeout", c.Lifecycle.StopTimeout)
+	for component, timeout := range c.Lifecycle.Timeouts {
+		v.duration("lifecycle.timeouts."+component, timeout)
+	}
+
+	for key, flag := range c.Features {
+		if flag.Rollout != nil {
+			v.check(*flag.Rollout >= 0 && *flag.Rollout <= 100, "features."+key+".rollout", fmt.Sprintf("must be a percentage between 0 and 100, got %d", *flag.Rollout))
+		}
+	}
+	v.duration("featureflags.refreshinterval", c.FeatureFlags.RefreshInterval)
+
+	for name, downstream := range c.Downstreams {
+		key := "downstreams." + name
+		u, err := url.Parse(downstream.BaseURL)
+		v.check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "", key+".baseurl", fmt.Sprintf("must be an http or https URL, got %q", downstream.BaseURL))
+		v.policy(key+".policy", downstream.Policy, c.Resilience.Policies)
+	}
+	v.policy("webhooks.policy", c.Webhooks.Policy, c.Resilience.Policies)
+	for name, policy := range c.Resilience.Policies {
+		v.resiliencePolicy("resilience.policies."+name, policy)
+	}
+
+	v.positive("circuitbreaker.requestsvolumethreshold", int(c.CircuitBreaker.RequestsVolumeThreshold))
+	v.check(c.CircuitBreaker.FailureThreshold > 0 && c.CircuitBreaker.FailureThreshold <= 1, "circuitbreaker.failurethreshold", fmt.Sprintf("must be above 0 and at most 1, got %g", c.CircuitBreaker.FailureThreshold))
+
+	v.check(c.Tracing.ServiceName != "", "tracing.servicename", "is required")
+	v.oneOf("tracing.exporter", c.Tracing.Exporter, "otlphttp", "otlpgrpc", "stdout", "file", "none")
+	if c.Tracing.Exporter == "file" {
+		v.check(c.Tracing.File != "", "tracing.file", "is required by the file exporter")
+	}
+	v.ratio("tracing.sampler.ratio", c.Tracing.Sampler.Ratio)
+	for i, rule := range c.Tracing.Sampler.Rules {
+		key := fmt.Sprintf("tracing.sampler.rules[%d]", i)
+		v.check(rule.Route != "", key+".route", "is required")
+		v.ratio(key+".ratio", rule.Ratio)
+	}
+	v.duration("tracing.shutdowntimeout", c.Tracing.ShutdownTimeout)
+
+	return errors.Join(v.errs...)
+}
@@ -0,0 +1,42 @@
+// Package feature evaluates feature flags for the caller of a request, in
+// command handlers as well as routers.
+package feature
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Evaluator decides whether a flag is on for the caller of ctx
+type Evaluator interface {
+	Enabled(ctx context.Context, key string) bool
+}
+
+var evaluator Evaluator
+
+// SetEvaluator installs the evaluator behind Enabled and Require
+func SetEvaluator(e Evaluator) {
+	evaluator = e
+}
+
+// Enabled reports whether the flag is on for the caller of ctx. Unknown flags
+// are off, as is every flag until an evaluator is installed.
+func Enabled(ctx context.Context, key string) bool {
+	if evaluator == nil {
+		return false
+	}
+	return evaluator.Enabled(ctx, key)
+}
+
+// Require hides routes behind a flag: callers it is off for get a 404, as if
+// the route did not exist. It must run after authentication and tenant
+// resolution, so the caller is known.
+func Require(key string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !Enabled(c.UserContext(), key) {
+			return fiber.ErrNotFound
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,56 @@
+package handler
+
+import "context"
+
+// Auditor records successful commands. Begin is called before req is handled
+// and may capture the current state; the returned function, if any, is called
+// with the response once the handler succeeded.
+type Auditor interface {
+	Begin(ctx context.Context, req any) func(ctx context.Context, res any)
+}
+
+var auditor Auditor
+
+// SetAuditor enables auditing of every request run through Handler or Audited
+func SetAuditor(a Auditor) {
+	auditor = a
+}
+
+type audited[R Request, Res Response] struct {
+	next HandlerInterface[R, Res]
+}
+
+// Audited wraps a handler so each successful request is passed to the
+// configured Auditor
+func Audited[R Request, Res Response](handler HandlerInterface[R, Res]) HandlerInterface[R, Res] {
+	if _, ok := handler.(audited[R, Res]); ok {
+		return handler
+	}
+	return audited[R, Res]{next: handler}
+}
+
+func (a audited[R, Res]) Handle(ctx context.Context, req *R) (*Res, error) {
+	if auditor == nil {
+		return a.next.Handle(ctx, req)
+	}
+
+	record := auditor.Begin(ctx, req)
+	res, err := a.next.Handle(ctx, req)
+	if err == nil && record != nil {
+		record(ctx, res)
+	}
+	return res, err
+}
+
+type clientIPKey struct{}
+
+// WithClientIP returns a copy of ctx carrying the address of the caller
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// ClientIP returns the address of the caller, or "" when it is unknown
+func ClientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
@@ -13,18 +13,65 @@ import (
 type Request any
 type Response any
 
+// StatusCoder can be implemented by responses that need a status other than 200 OK
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// Authorizer decides whether the caller in ctx may execute req, a pointer to a
+// command or query. Denials are returned as *fiber.Error.
+type Authorizer interface {
+	Authorize(ctx context.Context, req any) error
+}
+
+var authorizer Authorizer
+
+// SetAuthorizer enables authorization of every request run through Handler,
+// Authorized or Authorize. Without one, all requests are allowed.
+func SetAuthorizer(a Authorizer) {
+	authorizer = a
+}
+
+// Authorize checks req against the configured Authorizer
+func Authorize(ctx context.Context, req any) error {
+	if authorizer == nil {
+		return nil
+	}
+	return authorizer.Authorize(ctx, req)
+}
+
 // Define an interface for handlers
 type HandlerInterface[R Request, Res Response] interface {
 	Handle(ctx context.Context, req *R) (*Res, error)
 }
 
+type authorized[R Request, Res Response] struct {
+	next HandlerInterface[R, Res]
+}
+
+// Authorized wraps a handler so each request is authorized before it is handled
+func Authorized[R Request, Res Response](handler HandlerInterface[R, Res]) HandlerInterface[R, Res] {
+	if _, ok := handler.(authorized[R, Res]); ok {
+		return handler
+	}
+	return authorized[R, Res]{next: handler}
+}
+
+func (a authorized[R, Res]) Handle(ctx context.Context, req *R) (*Res, error) {
+	if err := Authorize(ctx, req); err != nil {
+		return nil, err
+	}
+	return a.next.Handle(ctx, req)
+}
+
 // Update handle function to accept HandlerInterface instead of Handler function
 func Handler[R Request, Res Response](handler HandlerInterface[R, Res]) fiber.Handler {
+	handler = Measured(Authorized(Audited(handler)))
 	return func(c *fiber.Ctx) error {
 		var req R
 
 		// Start a new span for this handler
-		ctx := c.UserContext()
+		ctx := WithClientIP(c.UserContext(), c.IP())
 		tracer := otel.GetTracerProvider().Tracer("")
 		spanName := c.Route().Path
 		ctx, span := tracer.Start(ctx, spanName)
@@ -67,6 +114,10 @@ func Handler[R Request, Res Response](handler HandlerInterface[R, Res]) fiber.Ha
 			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 		}
 
+		if sc, ok := any(res).(StatusCoder); ok {
+			c.Status(sc.StatusCode())
+		}
+
 		return c.JSON(res)
 	}
 }
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	handled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "handler_requests_total",
+		Help: "Commands and queries handled, by type and outcome.",
+	}, []string{"kind", "type", "outcome"})
+	handleDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "handler_request_duration_seconds",
+		Help:    "Duration of command and query handlers, by type.",
+		Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"kind", "type"})
+)
+
+type measured[R Request, Res Response] struct {
+	next HandlerInterface[R, Res]
+	kind string
+	name string
+}
+
+// Measured wraps a handler so the rate, errors and duration of its requests
+// are recorded, labelled with the request type
+func Measured[R Request, Res Response](handler HandlerInterface[R, Res]) HandlerInterface[R, Res] {
+	if _, ok := handler.(measured[R, Res]); ok {
+		return handler
+	}
+	name := reflect.TypeFor[R]().Name()
+	return measured[R, Res]{next: handler, kind: kind(name), name: name}
+}
+
+func (m measured[R, Res]) Handle(ctx context.Context, req *R) (*Res, error) {
+	start := time.Now()
+	res, err := m.next.Handle(ctx, req)
+	handleDuration.WithLabelValues(m.kind, m.name).Observe(time.Since(start).Seconds())
+	handled.WithLabelValues(m.kind, m.name, outcome(err)).Inc()
+	return res, err
+}
+
+func kind(name string) string {
+	switch {
+	case strings.HasSuffix(name, "Command"):
+		return "command"
+	case strings.HasSuffix(name, "Query"):
+		return "query"
+	}
+	return "request"
+}
+
+// outcome tells failures caused by the caller apart from server errors
+func outcome(err error) string {
+	if err == nil {
+		return "success"
+	}
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) && fiberErr.Code < fiber.StatusInternalServerError {
+		return "client_error"
+	}
+	return "server_error"
+}
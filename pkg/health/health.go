@@ -0,0 +1,183 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type Status string
+
+const (
+	StatusOK       Status = "ok"
+	StatusFailing  Status = "failing"
+	StatusDraining Status = "draining"
+)
+
+// Check probes one dependency. Details, if any, are included in the report.
+type Check struct {
+	Name string
+	// Critical checks fail readiness; the others are only reported
+	Critical bool
+	Run      func(ctx context.Context) (details any, err error)
+}
+
+// Result is the outcome of a check
+type Result struct {
+	Status    Status    `json:"status"`
+	Critical  bool      `json:"critical"`
+	Error     string    `json:"error,omitempty"`
+	Details   any       `json:"details,omitempty"`
+	Duration  string    `json:"duration"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+type Report struct {
+	Status Status            `json:"status"`
+	Checks map[string]Result `json:"checks,omitempty"`
+}
+
+// Registry runs the registered checks for readiness. Each check runs with a
+// timeout and its result is cached, so frequent probes do not load the
+// dependencies.
+type Registry struct {
+	timeout  time.Duration
+	cacheTTL time.Duration
+	draining atomic.Bool
+
+	mu     sync.Mutex
+	checks []*cachedCheck
+}
+
+type cachedCheck struct {
+	Check
+	mu     sync.Mutex
+	result Result
+}
+
+func NewRegistry(timeout, cacheTTL time.Duration) *Registry {
+	return &Registry{
+		timeout:  timeout,
+		cacheTTL: cacheTTL,
+	}
+}
+
+func (r *Registry) Register(checks ...Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, check := range checks {
+		r.checks = append(r.checks, &cachedCheck{Check: check})
+	}
+}
+
+// Drain makes readiness fail from now on, so load balancers stop sending
+// traffic before the server shuts down
+func (r *Registry) Drain() {
+	r.draining.Store(true)
+}
+
+// Ready runs every check concurrently, or reuses its cached result
+func (r *Registry) Ready(ctx context.Context) Report {
+	if r.draining.Load() {
+		return Report{Status: StatusDraining}
+	}
+
+	r.mu.Lock()
+	checks := append([]*cachedCheck(nil), r.checks...)
+	r.mu.Unlock()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = r.run(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]Result, len(checks))}
+	for i, check := range checks {
+		report.Checks[check.Name] = results[i]
+		if check.Critical && results[i].Status != StatusOK {
+			report.Status = StatusFailing
+		}
+	}
+	return report
+}
+
+func (r *Registry) run(ctx context.Context, check *cachedCheck) Result {
+	// Concurrent probes wait for the one running the check
+	check.mu.Lock()
+	defer check.mu.Unlock()
+
+	if !check.result.CheckedAt.IsZero() && time.Since(check.result.CheckedAt) < r.cacheTTL {
+		return check.result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	details, err := runCheck(ctx, check.Run)
+	result := Result{
+		Status:    StatusOK,
+		Critical:  check.Critical,
+		Details:   details,
+		Duration:  time.Since(start).String(),
+		CheckedAt: start,
+	}
+	if err != nil {
+		result.Status = StatusFailing
+		result.Error = err.Error()
+	}
+
+	check.result = result
+	return result
+}
+
+// runCheck returns when the check does or its timeout expires, whichever
+// comes first, so a check ignoring ctx cannot block readiness
+func runCheck(ctx context.Context, run func(context.Context) (any, error)) (any, error) {
+	type outcome struct {
+		details any
+		err     error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		details, err := run(ctx)
+		done <- outcome{details, err}
+	}()
+
+	select {
+	case o := <-done:
+		return o.details, o.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// LiveHandler reports that the process is up. It checks no dependencies, so
+// an outage of one does not get every replica restarted.
+func LiveHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusOK).JSON(Report{Status: StatusOK})
+	}
+}
+
+// ReadyHandler reports whether the instance should receive traffic. It is
+// served without authentication, so the breakdown per check is left out.
+func ReadyHandler(r *Registry) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		report := r.Ready(c.UserContext())
+		status := fiber.StatusOK
+		if report.Status != StatusOK {
+			status = fiber.StatusServiceUnavailable
+		}
+		return c.Status(status).JSON(Report{Status: report.Status})
+	}
+}
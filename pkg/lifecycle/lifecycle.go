@@ -0,0 +1,169 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+	"go.uber.org/zap"
+)
+
+// Component is a part of the service with a lifetime, such as a server, a
+// background worker or a connection pool. Every hook is optional.
+type Component struct {
+	Name string
+	// Start must not block; long running work belongs in a goroutine that
+	// outlives ctx, which only bounds the start itself
+	Start func(ctx context.Context) error
+	// Drain stops taking new work, e.g. fails readiness or ends open streams
+	Drain func(ctx context.Context) error
+	// Stop finishes in-flight work and releases resources
+	Stop func(ctx context.Context) error
+	// StopTimeout bounds Drain and Stop each; zero uses lifecycle.stoptimeout
+	StopTimeout time.Duration
+}
+
+// Manager starts components in registration order. On shutdown it drains
+// them and then stops them, both in reverse order, so a component is stopped
+// before the ones it depends on.
+type Manager struct {
+	cfg        config.LifecycleConfig
+	components []Component
+	// started is the number of components whose Start succeeded
+	started int
+	failed  chan error
+}
+
+func NewManager(cfg config.LifecycleConfig) *Manager {
+	return &Manager{
+		cfg:    cfg,
+		failed: make(chan error, 1),
+	}
+}
+
+// Register appends components, which depend on the ones registered before them
+func (m *Manager) Register(components ...Component) {
+	m.components = append(m.components, components...)
+}
+
+// Start starts the registered components in order. It stops at the first
+// failure; Shutdown then only stops the components already started.
+func (m *Manager) Start() error {
+	for _, c := range m.components[m.started:] {
+		if c.Start != nil {
+			start := time.Now()
+			if err := runWithin(context.Background(), m.cfg.StartTimeout, c.Start); err != nil {
+				return fmt.Errorf("starting %s: %w", c.Name, err)
+			}
+			zap.L().Info("Component started", zap.String("component", c.Name), zap.Duration("took", time.Since(start)))
+		}
+		m.started++
+	}
+	return nil
+}
+
+// Fail reports that a running component broke, e.g. a server stopped
+// serving. It ends Wait; only the first failure is kept.
+func (m *Manager) Fail(component string, err error) {
+	select {
+	case m.failed <- fmt.Errorf("%s: %w", component, err):
+	default:
+	}
+}
+
+// Wait blocks until SIGINT or SIGTERM is received or a component fails, and
+// returns the failure
+func (m *Manager) Wait() error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	select {
+	case sig := <-signals:
+		zap.L().Info("Shutdown requested", zap.String("signal", sig.String()))
+		return nil
+	case err := <-m.failed:
+		zap.L().Error("Component failed, shutting down", zap.Error(err))
+		return err
+	}
+}
+
+// Shutdown drains every started component, then stops them. A component that
+// fails or times out is logged and skipped so the others still get to stop.
+func (m *Manager) Shutdown() error {
+	begin := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), m.cfg.ShutdownTimeout)
+	defer cancel()
+
+	started := m.components[:m.started]
+	zap.L().Info("Draining components", zap.Int("components", len(started)))
+	errs := m.each(ctx, started, "drain", func(c Component) func(context.Context) error { return c.Drain })
+
+	zap.L().Info("Stopping components", zap.Int("components", len(started)))
+	errs = append(errs, m.each(ctx, started, "stop", func(c Component) func(context.Context) error { return c.Stop })...)
+
+	if len(errs) > 0 {
+		zap.L().Warn("Shutdown completed with errors", zap.Int("errors", len(errs)), zap.Duration("took", time.Since(begin)))
+		return errors.Join(errs...)
+	}
+	zap.L().Info("Shutdown completed", zap.Duration("took", time.Since(begin)))
+	return nil
+}
+
+// each runs one hook of every component, in reverse order
+func (m *Manager) each(ctx context.Context, components []Component, phase string, hook func(Component) func(context.Context) error) []error {
+	var errs []error
+	for i := len(components) - 1; i >= 0; i-- {
+		c := components[i]
+		fn := hook(c)
+		if fn == nil {
+			continue
+		}
+
+		start := time.Now()
+		fields := []zap.Field{zap.String("component", c.Name), zap.String("phase", phase)}
+		if err := runWithin(ctx, m.timeout(c), fn); err != nil {
+			zap.L().Error("Component "+phase+" failed", append(fields, zap.Duration("took", time.Since(start)), zap.Error(err))...)
+			errs = append(errs, fmt.Errorf("%s %s: %w", phase, c.Name, err))
+			continue
+		}
+		zap.L().Info("Component "+phase+" finished", append(fields, zap.Duration("took", time.Since(start)))...)
+	}
+	return errs
+}
+
+// timeout of a component's drain and stop, preferring the configured override
+func (m *Manager) timeout(c Component) time.Duration {
+	if timeout, ok := m.cfg.Timeouts[strings.ToLower(c.Name)]; ok && timeout > 0 {
+		return timeout
+	}
+	if c.StopTimeout > 0 {
+		return c.StopTimeout
+	}
+	return m.cfg.StopTimeout
+}
+
+// runWithin returns when fn does or the timeout expires, whichever comes
+// first, so a hook ignoring ctx cannot hold up the remaining components
+func runWithin(ctx context.Context, timeout time.Duration, fn func(context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
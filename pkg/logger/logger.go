@@ -8,10 +8,14 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
-var log *zap.Logger
+var (
+	log   *zap.Logger
+	level = zap.NewAtomicLevelAt(zap.InfoLevel)
+)
 
 func Init() {
 	config := zap.NewProductionConfig()
+	config.Level = level
 	config.EncoderConfig.TimeKey = "timestamp"
 	config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
 
@@ -27,6 +31,17 @@ func GetLogger() *zap.Logger {
 	return log
 }
 
+// SetLevel changes the minimum level of every logger built by Init, e.g.
+// "debug" or "warn"
+func SetLevel(name string) error {
+	l, err := zapcore.ParseLevel(name)
+	if err != nil {
+		return err
+	}
+	level.SetLevel(l)
+	return nil
+}
+
 func GetTraceFields(ctx context.Context) []zap.Field {
 	spanCtx := trace.SpanContextFromContext(ctx)
 	fields := make([]zap.Field, 0)
@@ -0,0 +1,39 @@
+package openapi
+
+import (
+	"bytes"
+	_ "embed"
+	"html/template"
+	"io/fs"
+
+	swaggerfiles "github.com/swaggo/files/v2"
+)
+
+//go:embed docs.html
+var docsHTML string
+
+var docsTemplate = template.Must(template.New("docs").Parse(docsHTML))
+
+// DocsAssets are the Swagger UI files loaded by the docs page, by file name.
+// They are embedded in the binary, so the page does not depend on a CDN.
+var DocsAssets = map[string][]byte{}
+
+func init() {
+	for _, name := range []string{"swagger-ui.css", "swagger-ui-bundle.js"} {
+		data, err := fs.ReadFile(swaggerfiles.FS, name)
+		if err != nil {
+			panic(err)
+		}
+		DocsAssets[name] = data
+	}
+}
+
+// DocsPage renders an interactive documentation page for the spec served at
+// specURL, loading DocsAssets from assetsURL
+func DocsPage(title, specURL, assetsURL string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := docsTemplate.Execute(&buf, struct{ Title, SpecURL, AssetsURL string }{title, specURL, assetsURL}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,481 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/auth"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/handler"
+	"github.com/google/uuid"
+)
+
+const (
+	securitySchemeName = "bearerAuth"
+	apiKeySchemeName   = "apiKeyAuth"
+	errorSchemaName    = "Error"
+	jsonContentType    = "application/json"
+)
+
+var (
+	timeType       = reflect.TypeFor[time.Time]()
+	uuidType       = reflect.TypeFor[uuid.UUID]()
+	rawMessageType = reflect.TypeFor[json.RawMessage]()
+	marshalerType  = reflect.TypeFor[json.Marshaler]()
+)
+
+// Route documents a single endpoint. Request and Response are the types bound
+// and returned by pkg/handler.Handler; Handler sets them from type parameters.
+type Route struct {
+	Method  string
+	Path    string // Fiber syntax, e.g. /api/v1/products/:id
+	Summary string
+	Tag     string
+	// OperationID defaults to the request type name without its Command/Query suffix
+	OperationID string
+	// Errors lists statuses returned besides 400, 500 and, unless Public, 401 and 403
+	Errors []int
+	// Public routes are served without a bearer token
+	Public bool
+	// Status of a successful response. Defaults to handler.StatusCoder or 200.
+	Status int
+	// ContentType of a successful response, application/json when empty
+	ContentType string
+
+	Request  reflect.Type
+	Response reflect.Type
+}
+
+// Handler documents a route served by handler.Handler for a HandlerInterface[Req, Res]
+func Handler[Req, Res any](route Route) Route {
+	route.Request = reflect.TypeFor[Req]()
+	route.Response = reflect.TypeFor[Res]()
+	return route
+}
+
+// Generator builds a Document from routes by reflecting on their request and
+// response types, following the json, params, query and reqHeader tags used by
+// Fiber's parsers.
+type Generator struct {
+	doc    *Document
+	names  map[reflect.Type]string
+	owners map[string]reflect.Type
+	custom map[reflect.Type]*Schema
+}
+
+func NewGenerator(info Info) *Generator {
+	return &Generator{
+		doc: &Document{
+			OpenAPI: Version,
+			Info:    info,
+			Paths:   map[string]PathItem{},
+			Components: Components{
+				Schemas: map[string]*Schema{
+					errorSchemaName: {
+						Type: "object",
+						Properties: map[string]*Schema{
+							"status":  {Type: "integer"},
+							"message": {Type: "string"},
+						},
+						Required: []string{"status", "message"},
+					},
+				},
+				SecuritySchemes: map[string]SecurityScheme{
+					securitySchemeName: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+					apiKeySchemeName:   {Type: "apiKey", In: "header", Name: auth.APIKeyHeader},
+				},
+			},
+			// Either a JWT or an API key
+			Security: []SecurityRequirement{{securitySchemeName: {}}, {apiKeySchemeName: {}}},
+		},
+		names:  map[reflect.Type]string{},
+		owners: map[string]reflect.Type{},
+		custom: map[reflect.Type]*Schema{},
+	}
+}
+
+// Enum registers the allowed values of a named string or integer type as a
+// component schema. All values must share the same type.
+func (g *Generator) Enum(values ...interface{}) {
+	if len(values) == 0 {
+		return
+	}
+
+	t := reflect.TypeOf(values[0])
+	s := g.primitive(t)
+	s.Enum = values
+	name := g.componentName(t)
+	g.doc.Components.Schemas[name] = s
+	g.custom[t] = &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// Schema overrides the schema of a type, e.g. one with a custom JSON encoding
+func (g *Generator) Schema(t reflect.Type, s *Schema) {
+	g.custom[t] = s
+}
+
+func (g *Generator) Add(routes ...Route) error {
+	for _, route := range routes {
+		if err := g.add(route); err != nil {
+			return fmt.Errorf("%s %s: %w", route.Method, route.Path, err)
+		}
+	}
+	return nil
+}
+
+func (g *Generator) Document() *Document {
+	return g.doc
+}
+
+func (g *Generator) add(route Route) error {
+	method := strings.ToLower(route.Method)
+	specPath, pathParams := convertPath(route.Path)
+
+	item := g.doc.Paths[specPath]
+	if item == nil {
+		item = PathItem{}
+		g.doc.Paths[specPath] = item
+	}
+	if _, ok := item[method]; ok {
+		return fmt.Errorf("documented twice")
+	}
+
+	op := &Operation{
+		OperationID: route.OperationID,
+		Summary:     route.Summary,
+		Responses:   map[string]Response{},
+	}
+	if route.Tag != "" {
+		op.Tags = []string{route.Tag}
+		g.addTag(route.Tag)
+	}
+	if route.Public {
+		op.Security = &[]SecurityRequirement{}
+	}
+
+	// Path parameters come from the route itself; the request type only refines their schema
+	bound := map[string]map[string]reflect.Type{}
+	if route.Request != nil {
+		if route.Request.Kind() != reflect.Struct {
+			return fmt.Errorf("request type %s is not a struct", route.Request)
+		}
+		bound = boundFields(route.Request)
+		if op.OperationID == "" {
+			op.OperationID = operationID(route.Request.Name())
+		}
+	}
+	for _, name := range pathParams {
+		schema := &Schema{Type: "string"}
+		if t, ok := bound["params"][name]; ok {
+			schema = g.schemaOf(t)
+		}
+		op.Parameters = append(op.Parameters, Parameter{Name: name, In: "path", Required: true, Schema: schema})
+	}
+	for _, in := range []struct{ tag, location string }{{"query", "query"}, {"reqHeader", "header"}} {
+		for _, name := range sortedKeys(bound[in.tag]) {
+			op.Parameters = append(op.Parameters, Parameter{Name: name, In: in.location, Schema: g.schemaOf(bound[in.tag][name])})
+		}
+	}
+
+	if route.Request != nil && method != "get" && method != "head" {
+		if body := g.requestBody(route.Request); body != nil {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]MediaType{jsonContentType: {Schema: body}},
+			}
+		}
+	}
+
+	status := route.Status
+	if status == 0 && route.Response != nil {
+		if sc, ok := reflect.New(route.Response).Interface().(handler.StatusCoder); ok {
+			status = sc.StatusCode()
+		}
+	}
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := Response{Description: http.StatusText(status)}
+	if route.Response != nil || route.ContentType != "" {
+		contentType := route.ContentType
+		if contentType == "" {
+			contentType = jsonContentType
+		}
+		media := MediaType{}
+		if route.Response != nil {
+			media.Schema = g.schemaOf(route.Response)
+		}
+		success.Content = map[string]MediaType{contentType: media}
+	}
+	op.Responses[strconv.Itoa(status)] = success
+
+	errorStatuses := append([]int{http.StatusBadRequest, http.StatusInternalServerError}, route.Errors...)
+	if !route.Public {
+		// Authenticated clients are also rate limited
+		errorStatuses = append(errorStatuses, http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests)
+	}
+	for _, code := range errorStatuses {
+		op.Responses[strconv.Itoa(code)] = Response{
+			Description: http.StatusText(code),
+			Content: map[string]MediaType{
+				jsonContentType: {Schema: &Schema{Ref: "#/components/schemas/" + errorSchemaName}},
+			},
+		}
+	}
+
+	item[method] = op
+	return nil
+}
+
+func (g *Generator) addTag(name string) {
+	for _, tag := range g.doc.Tags {
+		if tag.Name == name {
+			return
+		}
+	}
+	g.doc.Tags = append(g.doc.Tags, Tag{Name: name})
+}
+
+// requestBody describes the json tagged fields of a request that are not
+// bound from the path, query string or headers. It returns nil when there are none.
+func (g *Generator) requestBody(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	g.fields(t, s, true)
+	if len(s.Properties) == 0 {
+		return nil
+	}
+
+	name := g.componentName(t)
+	g.doc.Components.Schemas[name] = s
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func (g *Generator) schemaOf(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if s, ok := g.custom[t]; ok {
+		clone := *s
+		return &clone
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == uuidType:
+		return &Schema{Type: "string", Format: "uuid"}
+	case t == rawMessageType:
+		return &Schema{}
+	case t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType):
+		// Custom encodings cannot be inferred; register them with Schema
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return g.component(t)
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem())}
+	case reflect.Interface:
+		return &Schema{}
+	default:
+		return g.primitive(t)
+	}
+}
+
+func (g *Generator) primitive(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Uint:
+		return &Schema{Type: "integer"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	default:
+		return &Schema{}
+	}
+}
+
+// component returns a reference to the schema of a struct, generating it on first use
+func (g *Generator) component(t reflect.Type) *Schema {
+	if t.Name() == "" {
+		s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		g.fields(t, s, false)
+		return s
+	}
+
+	ref := func(name string) *Schema { return &Schema{Ref: "#/components/schemas/" + name} }
+	if name, ok := g.names[t]; ok {
+		return ref(name)
+	}
+
+	// Register before walking the fields so recursive types terminate
+	name := g.componentName(t)
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	g.doc.Components.Schemas[name] = s
+	g.fields(t, s, false)
+	return ref(name)
+}
+
+// fields adds the properties encoding/json produces for t. Request bodies only
+// include explicitly json tagged fields that no other parser binds, and list no
+// required properties since missing fields decode to their zero value.
+func (g *Generator) fields(t reflect.Type, s *Schema, requestBody bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.fields(embedded, s, requestBody)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if requestBody && (tag == "" || isBound(field)) {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		omitEmpty := strings.Contains(opts, "omitempty")
+		fieldSchema := g.schemaOf(field.Type)
+		if field.Type.Kind() == reflect.Pointer && !omitEmpty && !requestBody {
+			fieldSchema = nullable(fieldSchema)
+		}
+		s.Properties[name] = fieldSchema
+		if !requestBody && !omitEmpty && field.Type.Kind() != reflect.Pointer {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// componentName picks a unique schema name, qualifying it with the package on collisions
+func (g *Generator) componentName(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+
+	name := t.Name()
+	if owner, taken := g.owners[name]; taken && owner != t {
+		pkg := path.Base(t.PkgPath())
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+	g.names[t] = name
+	g.owners[name] = t
+	return name
+}
+
+func nullable(s *Schema) *Schema {
+	if s.Ref != "" {
+		return &Schema{OneOf: []*Schema{s, {Type: "null"}}}
+	}
+	if typ, ok := s.Type.(string); ok {
+		s.Type = []string{typ, "null"}
+	}
+	return s
+}
+
+func isBound(field reflect.StructField) bool {
+	for _, tag := range []string{"params", "query", "reqHeader"} {
+		if field.Tag.Get(tag) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// boundFields maps each Fiber parser tag to the fields it binds, keyed by name
+func boundFields(t reflect.Type) map[string]map[string]reflect.Type {
+	bound := map[string]map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		for _, tag := range []string{"params", "query", "reqHeader"} {
+			name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+			if name == "" {
+				continue
+			}
+			if bound[tag] == nil {
+				bound[tag] = map[string]reflect.Type{}
+			}
+			bound[tag][name] = field.Type
+		}
+	}
+	return bound
+}
+
+// convertPath turns a Fiber route path into an OpenAPI path template
+func convertPath(fiberPath string) (string, []string) {
+	var params []string
+	segments := strings.Split(strings.TrimSuffix(fiberPath, "/"), "/")
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(segment, ":"), "?")
+		name, _, _ = strings.Cut(name, "<")
+		params = append(params, name)
+		segments[i] = "{" + name + "}"
+	}
+
+	specPath := strings.Join(segments, "/")
+	if specPath == "" {
+		specPath = "/"
+	}
+	return specPath, params
+}
+
+// NormalizePath converts a Fiber route path the same way routes are documented
+func NormalizePath(fiberPath string) string {
+	specPath, _ := convertPath(fiberPath)
+	return specPath
+}
+
+func operationID(typeName string) string {
+	name := strings.TrimSuffix(strings.TrimSuffix(typeName, "Command"), "Query")
+	if name == "" {
+		return ""
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+func sortedKeys(m map[string]reflect.Type) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,68 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit is a token bucket: Burst requests at once, refilled at Rate per second
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Result is the outcome of taking a token from a bucket
+type Result struct {
+	Allowed   bool
+	Remaining int
+	// RetryAfter is how long until the next token, zero when one is available
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+}
+
+// Store keeps token buckets. Implementations must take tokens atomically so
+// concurrent requests for one key cannot overspend it.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+}
+
+// Bucket is the persisted state of a token bucket
+type Bucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+// Take refills the bucket for the time passed since its last update and
+// spends one token if there is one
+func (b *Bucket) Take(limit Limit, now time.Time) Result {
+	if elapsed := now.Sub(b.UpdatedAt).Seconds(); elapsed > 0 {
+		b.Tokens = math.Min(float64(limit.Burst), b.Tokens+elapsed*limit.Rate)
+	}
+	b.UpdatedAt = now
+
+	allowed := b.Tokens >= 1
+	if allowed {
+		b.Tokens--
+	}
+
+	result := Result{
+		Allowed:   allowed,
+		Remaining: int(b.Tokens),
+		Reset:     seconds((float64(limit.Burst) - b.Tokens) / limit.Rate),
+	}
+	if !allowed {
+		result.RetryAfter = seconds((1 - b.Tokens) / limit.Rate)
+	}
+	return result
+}
+
+// NewBucket returns a full bucket
+func NewBucket(limit Limit, now time.Time) *Bucket {
+	return &Bucket{Tokens: float64(limit.Burst), UpdatedAt: now}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
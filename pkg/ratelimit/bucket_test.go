@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBucketBurst(t *testing.T) {
+	limit := Limit{Rate: 2, Burst: 3}
+	now := time.Now()
+	b := NewBucket(limit, now)
+
+	for i := 2; i >= 0; i-- {
+		res := b.Take(limit, now)
+		if !res.Allowed || res.Remaining != i || res.RetryAfter != 0 {
+			t.Fatalf("take %d: %+v, want allowed with %d remaining", 3-i, res, i)
+		}
+	}
+
+	res := b.Take(limit, now)
+	if res.Allowed {
+		t.Fatal("allowed beyond the burst")
+	}
+	// One token takes 1/rate seconds; the bucket is full after burst/rate
+	if res.RetryAfter != 500*time.Millisecond {
+		t.Errorf("retry after %v, want 500ms", res.RetryAfter)
+	}
+	if res.Reset != 1500*time.Millisecond {
+		t.Errorf("reset %v, want 1.5s", res.Reset)
+	}
+}
+
+func TestBucketRefill(t *testing.T) {
+	limit := Limit{Rate: 4, Burst: 10}
+	now := time.Now()
+	b := &Bucket{Tokens: 0, UpdatedAt: now}
+
+	// A quarter second refills one token
+	if res := b.Take(limit, now.Add(100*time.Millisecond)); res.Allowed {
+		t.Fatalf("allowed with %.1f tokens", b.Tokens)
+	}
+	res := b.Take(limit, now.Add(250*time.Millisecond))
+	if !res.Allowed || res.Remaining != 0 {
+		t.Fatalf("after refill: %+v, want allowed with none remaining", res)
+	}
+
+	// Partial tokens carry over to the next take
+	res = b.Take(limit, now.Add(375*time.Millisecond))
+	if res.Allowed || res.RetryAfter != 125*time.Millisecond {
+		t.Errorf("half a token: %+v, want rejected for 125ms", res)
+	}
+}
+
+func TestBucketRefillIsCappedAtBurst(t *testing.T) {
+	limit := Limit{Rate: 1, Burst: 5}
+	now := time.Now()
+	b := &Bucket{Tokens: 0, UpdatedAt: now}
+
+	res := b.Take(limit, now.Add(time.Hour))
+	if !res.Allowed || res.Remaining != 4 {
+		t.Errorf("after an hour: %+v, want allowed with 4 remaining", res)
+	}
+	if b.Tokens != 4 {
+		t.Errorf("tokens = %g, want 4", b.Tokens)
+	}
+}
+
+func TestBucketIgnoresClockGoingBack(t *testing.T) {
+	limit := Limit{Rate: 1, Burst: 2}
+	now := time.Now()
+	b := &Bucket{Tokens: 0.5, UpdatedAt: now}
+
+	if res := b.Take(limit, now.Add(-time.Minute)); res.Allowed {
+		t.Errorf("refilled for negative time: %+v", res)
+	}
+	if b.Tokens != 0.5 {
+		t.Errorf("tokens = %g, want 0.5", b.Tokens)
+	}
+}
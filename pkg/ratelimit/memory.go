@@ -0,0 +1,45 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps buckets in process memory, so each replica enforces its
+// own limits. Buckets idle for longer than idleTimeout are dropped.
+type MemoryStore struct {
+	idleTimeout time.Duration
+
+	mu        sync.Mutex
+	buckets   map[string]*Bucket
+	lastSweep time.Time
+}
+
+func NewMemoryStore(idleTimeout time.Duration) *MemoryStore {
+	return &MemoryStore{
+		idleTimeout: idleTimeout,
+		buckets:     map[string]*Bucket{},
+	}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) > s.idleTimeout {
+		for k, b := range s.buckets {
+			if now.Sub(b.UpdatedAt) > s.idleTimeout {
+				delete(s.buckets, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = NewBucket(limit, now)
+		s.buckets[key] = bucket
+	}
+	return bucket.Take(limit, now), nil
+}
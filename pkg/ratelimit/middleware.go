@@ -0,0 +1,182 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/auth"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/logger"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/tenant"
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+var (
+	rejections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_rate_limit_rejections_total",
+		Help: "Requests and gRPC calls rejected by the rate limiter, by rule and API key tier.",
+	}, []string{"rule", "tier"})
+	storeErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "http_rate_limit_store_errors_total",
+		Help: "Rate limit checks that failed and let the request through.",
+	})
+)
+
+// Middleware limits each client per rule of the request's tenant
+// configuration. It must run after authentication and tenant resolution.
+// Requests are let through when the store fails.
+func Middleware(store Store, runtime *config.Runtime, skip func(*fiber.Ctx) bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if skip != nil && skip(c) {
+			return c.Next()
+		}
+
+		client, tier := clientOf(c.UserContext(), c.IP())
+		rule, limit, key, ok := resolve(c.UserContext(), runtime, c.Method(), c.Path(), client, tier)
+		if !ok {
+			return c.Next()
+		}
+		return enforce(c, store, key, limit, rule, tier)
+	}
+}
+
+// Allow takes a token for a call to method and path from the address ip, by
+// the same rules and buckets as Middleware, and returns how long to wait when
+// the call is rejected. Calls are let through when the store fails.
+func Allow(ctx context.Context, store Store, runtime *config.Runtime, method, path, ip string) (bool, time.Duration) {
+	client, tier := clientOf(ctx, ip)
+	rule, limit, key, ok := resolve(ctx, runtime, method, path, client, tier)
+	if !ok {
+		return true, 0
+	}
+	result, ok := take(ctx, store, key, limit, rule, tier)
+	if !ok {
+		return true, 0
+	}
+	return result.Allowed, result.RetryAfter
+}
+
+// resolve returns the rule of the context's tenant configuration that limits
+// a call, its limit for the tier and the bucket key of the client. ok is false
+// when the call is not limited.
+func resolve(ctx context.Context, runtime *config.Runtime, method, path, client, tier string) (rule string, limit Limit, key string, ok bool) {
+	limits := tenant.Config(ctx, runtime.Config()).RateLimit
+	if !limits.Enabled {
+		return "", Limit{}, "", false
+	}
+
+	r := match(limits, method, path)
+	if r.Rate <= 0 || r.Burst <= 0 {
+		return "", Limit{}, "", false
+	}
+	multiplier, found := limits.Tiers[tier]
+	if !found {
+		multiplier = 1
+	}
+	// A fractional multiplier must not truncate the burst to no requests at all
+	limit = Limit{Rate: r.Rate * multiplier, Burst: max(1, int(float64(r.Burst)*multiplier))}
+
+	var tenantID string
+	if t, found := tenant.FromContext(ctx); found {
+		tenantID = t.ID
+	}
+	return r.Name, limit, strings.Join([]string{tenantID, r.Name, client}, "|"), true
+}
+
+// IPMiddleware limits each IP address by the perip rule of the global
+// configuration. It must run before authentication, so that requests with
+// invalid credentials are limited as well.
+func IPMiddleware(store Store, runtime *config.Runtime, skip func(*fiber.Ctx) bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if skip != nil && skip(c) {
+			return c.Next()
+		}
+
+		limits := runtime.Config().RateLimit
+		rule := limits.PerIP
+		if !limits.Enabled || rule.Rate <= 0 || rule.Burst <= 0 {
+			return c.Next()
+		}
+		// No tenant is known yet; "*" is not a valid tenant ID
+		key := strings.Join([]string{"*", rule.Name, "ip:" + c.IP()}, "|")
+		return enforce(c, store, key, Limit{Rate: rule.Rate, Burst: rule.Burst}, rule.Name, "")
+	}
+}
+
+// enforce takes a token for key, rejecting the request with 429 when there
+// is none. The headers describe the budget checked last.
+func enforce(c *fiber.Ctx, store Store, key string, limit Limit, rule, tier string) error {
+	result, ok := take(c.UserContext(), store, key, limit, rule, tier)
+	if !ok {
+		return c.Next()
+	}
+
+	c.Set("RateLimit-Limit", strconv.Itoa(limit.Burst))
+	c.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+	c.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Burst, ceilSeconds(time.Duration(float64(limit.Burst)/limit.Rate*float64(time.Second)))))
+
+	if !result.Allowed {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(ceilSeconds(result.RetryAfter)))
+		return fiber.NewError(fiber.StatusTooManyRequests, "Too Many Requests")
+	}
+	return c.Next()
+}
+
+// take takes a token for key and counts rejections. ok is false when the
+// store failed.
+func take(ctx context.Context, store Store, key string, limit Limit, rule, tier string) (result Result, ok bool) {
+	result, err := store.Take(ctx, key, limit, time.Now())
+	if err != nil {
+		storeErrors.Inc()
+		zap.L().Error("Rate limit check failed", append(logger.GetTraceFields(ctx), zap.Error(err))...)
+		return Result{}, false
+	}
+	if !result.Allowed {
+		if tier == "" {
+			tier = "none"
+		}
+		rejections.WithLabelValues(rule, tier).Inc()
+	}
+	return result, true
+}
+
+// match returns the first rule for the request, or the default
+func match(cfg config.RateLimitConfig, method, path string) config.RateLimitRule {
+	for _, rule := range cfg.Rules {
+		if len(rule.Methods) > 0 && !slices.ContainsFunc(rule.Methods, func(m string) bool {
+			return strings.EqualFold(m, method)
+		}) {
+			continue
+		}
+		if prefix, ok := strings.CutSuffix(rule.Path, "*"); ok {
+			if strings.HasPrefix(path, prefix) {
+				return rule
+			}
+		} else if path == rule.Path || path == rule.Path+"/" {
+			return rule
+		}
+	}
+	return cfg.Default
+}
+
+// clientOf identifies the caller by subject, which is the key ID for API
+// keys, or by IP address when unauthenticated
+func clientOf(ctx context.Context, ip string) (client, tier string) {
+	if claims, ok := auth.ClaimsFromContext(ctx); ok && claims.Subject != "" {
+		return "sub:" + claims.Subject, claims.RateLimitTier
+	}
+	return "ip:" + ip, ""
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
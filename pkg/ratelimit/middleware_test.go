@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/auth"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestIPMiddlewareLimitsRejectedCredentials(t *testing.T) {
+	runtime := config.NewRuntime(&config.Config{RateLimit: config.RateLimitConfig{
+		Enabled: true,
+		PerIP:   config.RateLimitRule{Name: "per-ip", Rate: 1, Burst: 2},
+	}})
+
+	app := fiber.New()
+	app.Use(IPMiddleware(NewMemoryStore(time.Minute), runtime, nil))
+	// Stands in for authentication refusing every request
+	app.Use(func(c *fiber.Ctx) error {
+		return fiber.ErrUnauthorized
+	})
+
+	want := []int{fiber.StatusUnauthorized, fiber.StatusUnauthorized, fiber.StatusTooManyRequests}
+	for i, status := range want {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/api/v1/products", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != status {
+			t.Fatalf("request %d: status %d, want %d", i+1, resp.StatusCode, status)
+		}
+	}
+}
+
+func TestFractionalTierKeepsABurst(t *testing.T) {
+	runtime := config.NewRuntime(&config.Config{RateLimit: config.RateLimitConfig{
+		Enabled: true,
+		Default: config.RateLimitRule{Name: "default", Rate: 1, Burst: 1},
+		Tiers:   map[string]float64{"free": 0.5},
+	}})
+	ctx := auth.WithClaims(context.Background(), &auth.Claims{Subject: "key-1", RateLimitTier: "free"})
+
+	_, limit, _, ok := resolve(ctx, runtime, fiber.MethodGet, "/api/v1/products", "sub:key-1", "free")
+	if !ok {
+		t.Fatal("call is not limited")
+	}
+	if limit.Burst != 1 {
+		t.Fatalf("burst %d, want 1", limit.Burst)
+	}
+	if allowed, _ := Allow(ctx, NewMemoryStore(time.Minute), runtime, fiber.MethodGet, "/api/v1/products", "10.0.0.1"); !allowed {
+		t.Fatal("first call was rejected")
+	}
+}
+
+func TestAllowSharesBucketsWithMiddleware(t *testing.T) {
+	runtime := config.NewRuntime(&config.Config{RateLimit: config.RateLimitConfig{
+		Enabled: true,
+		Default: config.RateLimitRule{Name: "default", Rate: 0.001, Burst: 1},
+	}})
+	store := NewMemoryStore(time.Minute)
+
+	app := fiber.New()
+	app.Use(Middleware(store, runtime, nil))
+	app.Get("/api/v1/products", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/api/v1/products", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status %d, want %d", resp.StatusCode, fiber.StatusOK)
+	}
+
+	// app.Test requests come from 0.0.0.0
+	allowed, retryAfter := Allow(context.Background(), store, runtime, fiber.MethodPost, "/product.v1.ProductService/GetProduct", "0.0.0.0")
+	if allowed {
+		t.Fatal("call was allowed after the HTTP request spent the burst")
+	}
+	if retryAfter <= 0 {
+		t.Fatalf("retry after %s, want a positive wait", retryAfter)
+	}
+}
@@ -0,0 +1,74 @@
+package tenant
+
+import (
+	"context"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/auth"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Resolver determines the tenant of a request from its token and headers.
+// Tenants get the configuration active when their request starts.
+type Resolver struct {
+	runtime *config.Runtime
+}
+
+func NewResolver(runtime *config.Runtime) *Resolver {
+	return &Resolver{runtime: runtime}
+}
+
+// Header is the request header that may name the tenant
+func (r *Resolver) Header() string {
+	return r.runtime.Config().Tenancy.Header
+}
+
+// Resolve picks the tenant from the token claim, then the configured default.
+// The header is only trusted when authentication is disabled; otherwise it
+// must name the token's tenant, and tokens without the claim act for the
+// default tenant.
+func (r *Resolver) Resolve(ctx context.Context, header string) (*Tenant, error) {
+	cfg := r.runtime.Config()
+	id := header
+	if !cfg.Auth.Disabled {
+		id = cfg.Tenancy.Default
+		if claims, ok := auth.ClaimsFromContext(ctx); ok {
+			if claimed := claims.Tenant; claimed != "" {
+				id = claimed
+			} else if claimed := claims.String(cfg.Tenancy.Claim); claimed != "" {
+				id = claimed
+			}
+		}
+		if header != "" && header != id {
+			return nil, fiber.NewError(fiber.StatusForbidden, "tenant does not match token")
+		}
+	}
+	if id == "" {
+		id = cfg.Tenancy.Default
+	}
+
+	if id == "" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "tenant is required")
+	}
+	if !validID.MatchString(id) || !cfg.KnownTenant(id) {
+		return nil, fiber.NewError(fiber.StatusForbidden, "unknown tenant")
+	}
+	return &Tenant{ID: id, Config: cfg.ForTenant(id)}, nil
+}
+
+// Middleware resolves the tenant of each request into its user context. It
+// must run after authentication so the token's claim is available.
+func Middleware(resolver *Resolver, skip func(*fiber.Ctx) bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if skip != nil && skip(c) {
+			return c.Next()
+		}
+
+		t, err := resolver.Resolve(c.UserContext(), c.Get(resolver.Header()))
+		if err != nil {
+			return err
+		}
+		c.SetUserContext(WithTenant(c.UserContext(), t))
+		return c.Next()
+	}
+}
@@ -0,0 +1,63 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/auth"
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestResolve(t *testing.T) {
+	tenancy := config.TenancyConfig{Claim: "tenant_id", Header: "X-Tenant-ID", Default: "default"}
+	listed := tenancy
+	listed.Tenants = map[string]map[string]interface{}{"acme": nil}
+
+	token := func(tenant string) context.Context {
+		return auth.WithClaims(context.Background(), &auth.Claims{Raw: map[string]interface{}{"tenant_id": tenant}})
+	}
+	claimless := auth.WithClaims(context.Background(), &auth.Claims{Raw: map[string]interface{}{}})
+
+	tests := []struct {
+		name         string
+		authDisabled bool
+		tenancy      config.TenancyConfig
+		ctx          context.Context
+		header       string
+		want         string
+		wantStatus   int
+	}{
+		{"token claim", false, listed, token("acme"), "", "acme", 0},
+		{"header matching token", false, listed, token("acme"), "acme", "acme", 0},
+		{"header naming another tenant", false, listed, token("acme"), "other", "", fiber.StatusForbidden},
+		{"claimless token acts for default", false, listed, claimless, "", "default", 0},
+		{"claimless token cannot pick tenant", false, listed, claimless, "acme", "", fiber.StatusForbidden},
+		{"no caller cannot pick tenant", false, listed, context.Background(), "acme", "", fiber.StatusForbidden},
+		{"unlisted token tenant", false, listed, token("other"), "", "", fiber.StatusForbidden},
+		{"no list with auth accepts only default", false, tenancy, token("other"), "", "", fiber.StatusForbidden},
+		{"header without auth", true, tenancy, context.Background(), "other", "other", 0},
+		{"default without auth", true, tenancy, context.Background(), "", "default", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Auth: config.AuthConfig{Disabled: tt.authDisabled}, Tenancy: tt.tenancy}
+			got, err := NewResolver(config.NewRuntime(cfg)).Resolve(tt.ctx, tt.header)
+
+			if tt.wantStatus != 0 {
+				var fiberErr *fiber.Error
+				if !errors.As(err, &fiberErr) || fiberErr.Code != tt.wantStatus {
+					t.Fatalf("err = %v, want status %d", err, tt.wantStatus)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.ID != tt.want {
+				t.Errorf("tenant = %q, want %q", got.ID, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"regexp"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+)
+
+// ErrNoTenant is returned by tenant scoped operations run without a tenant
+var ErrNoTenant = errors.New("no tenant in context")
+
+var validID = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// Tenant is the tenant a request acts for
+type Tenant struct {
+	ID string
+	// Config is the global configuration with the tenant's overrides applied
+	Config *config.Config
+}
+
+type tenantKey struct{}
+
+func WithTenant(ctx context.Context, t *Tenant) context.Context {
+	return context.WithValue(ctx, tenantKey{}, t)
+}
+
+// FromContext returns the tenant of the request, if any
+func FromContext(ctx context.Context) (*Tenant, bool) {
+	t, ok := ctx.Value(tenantKey{}).(*Tenant)
+	return t, ok
+}
+
+// ID returns the tenant ID of the request, or ErrNoTenant
+func ID(ctx context.Context) (string, error) {
+	t, ok := FromContext(ctx)
+	if !ok || t.ID == "" {
+		return "", ErrNoTenant
+	}
+	return t.ID, nil
+}
+
+// Config returns the configuration of the request's tenant, or fallback when
+// the request has none
+func Config(ctx context.Context, fallback *config.Config) *config.Config {
+	if t, ok := FromContext(ctx); ok && t.Config != nil {
+		return t.Config
+	}
+	return fallback
+}
@@ -0,0 +1,51 @@
+package tracer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/health"
+
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+)
+
+// exportErrorWindow is how long an export error keeps the tracing check failing
+const exportErrorWindow = time.Minute
+
+var (
+	lastErrorMu sync.Mutex
+	lastError   error
+	lastErrorAt time.Time
+)
+
+// recordErrors logs OpenTelemetry errors, such as failed exports, and keeps
+// the last one for the health check
+func recordErrors() {
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		zap.L().Warn("OpenTelemetry error", zap.Error(err))
+
+		lastErrorMu.Lock()
+		lastError, lastErrorAt = err, time.Now()
+		lastErrorMu.Unlock()
+	}))
+}
+
+// HealthCheck fails while spans recently failed to export. It is not
+// critical, since serving requests does not depend on tracing.
+func HealthCheck() health.Check {
+	return health.Check{
+		Name: "tracing",
+		Run: func(ctx context.Context) (any, error) {
+			lastErrorMu.Lock()
+			defer lastErrorMu.Unlock()
+
+			if lastError != nil && time.Since(lastErrorAt) < exportErrorWindow {
+				return nil, fmt.Errorf("%w (at %s)", lastError, lastErrorAt.UTC().Format(time.RFC3339))
+			}
+			return nil, nil
+		},
+	}
+}
@@ -0,0 +1,81 @@
+package tracer
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
+
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// sampler is the sampler of the tracer provider. The provider cannot replace
+// it, so it delegates to the sampler last set by UpdateSampler.
+var sampler = &reloadableSampler{}
+
+// UpdateSampler applies new sampling rules to spans started from now on
+func UpdateSampler(cfg config.SamplerConfig) {
+	s := NewSampler(cfg)
+	sampler.current.Store(&s)
+}
+
+type reloadableSampler struct {
+	current atomic.Pointer[trace.Sampler]
+}
+
+func (s *reloadableSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	return (*s.current.Load()).ShouldSample(p)
+}
+
+func (s *reloadableSampler) Description() string {
+	return (*s.current.Load()).Description()
+}
+
+// NewSampler samples spans by the first rule matching their name, falling
+// back to the configured ratio. With ParentBased, spans of sampled callers
+// are always sampled and rules only decide for new traces.
+func NewSampler(cfg config.SamplerConfig) trace.Sampler {
+	sampler := &ruleSampler{fallback: trace.TraceIDRatioBased(cfg.Ratio)}
+	for _, rule := range cfg.Rules {
+		sampler.rules = append(sampler.rules, samplingRule{
+			route:   rule.Route,
+			sampler: trace.TraceIDRatioBased(rule.Ratio),
+		})
+	}
+
+	if cfg.ParentBased {
+		return trace.ParentBased(sampler)
+	}
+	return sampler
+}
+
+type samplingRule struct {
+	route   string
+	sampler trace.Sampler
+}
+
+func (r samplingRule) matches(name string) bool {
+	if prefix, ok := strings.CutSuffix(r.route, "*"); ok {
+		return strings.HasPrefix(name, prefix)
+	}
+	return name == r.route
+}
+
+type ruleSampler struct {
+	rules    []samplingRule
+	fallback trace.Sampler
+}
+
+func (s *ruleSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	for _, rule := range s.rules {
+		if rule.matches(p.Name) {
+			return rule.sampler.ShouldSample(p)
+		}
+	}
+	return s.fallback.ShouldSample(p)
+}
+
+func (s *ruleSampler) Description() string {
+	return fmt.Sprintf("RouteRules{rules=%d,fallback=%s}", len(s.rules), s.fallback.Description())
+}
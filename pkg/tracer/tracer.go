@@ -2,48 +2,111 @@ package tracer
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
 
 	"github.com/ceylanomer/golang-cqrs-ddd-poc/pkg/config"
 
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
-	"go.uber.org/zap"
 )
 
-func InitTracer(jaegerConfig config.JaegerConfig) *trace.TracerProvider {
-
-	headers := map[string]string{
-		"content-type": "application/json",
+// InitTracer installs the global tracer provider. The caller must Shutdown
+// the returned provider on exit, or buffered spans are lost.
+func InitTracer(cfg config.TracingConfig) (*trace.TracerProvider, error) {
+	UpdateSampler(cfg.Sampler)
+	opts := []trace.TracerProviderOption{
+		trace.WithSampler(sampler),
+		trace.WithResource(newResource(cfg)),
 	}
 
-	exporter, err := otlptrace.New(
-		context.Background(),
-		otlptracehttp.NewClient(
-			otlptracehttp.WithEndpoint(jaegerConfig.URL),
-			otlptracehttp.WithHeaders(headers),
-			otlptracehttp.WithInsecure(),
-		),
-	)
+	exporter, err := newExporter(cfg)
 	if err != nil {
-		zap.L().Fatal("Failed to create stdout exporter", zap.Error(err))
+		return nil, err
+	}
+	if exporter != nil {
+		opts = append(opts, trace.WithBatcher(exporter))
 	}
 
-	tp := trace.NewTracerProvider(
-		trace.WithSampler(trace.AlwaysSample()),
-		trace.WithBatcher(exporter),
-		trace.WithResource(
-			resource.NewWithAttributes(
-				semconv.SchemaURL,
-				semconv.ServiceNameKey.String("golang-cqrs-ddd-poc"),
-			)),
-	)
+	tp := trace.NewTracerProvider(opts...)
 
 	otel.SetTracerProvider(tp)
+	recordErrors()
 	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
-	return tp
+	return tp, nil
+}
+
+func newExporter(cfg config.TracingConfig) (trace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case "otlphttp", "":
+		opts := []otlptracehttp.Option{otlptracehttp.WithHeaders(cfg.Headers)}
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(context.Background(), opts...)
+	case "otlpgrpc":
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithHeaders(cfg.Headers)}
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(context.Background(), opts...)
+	case "stdout":
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+	case "file":
+		file, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("open trace file: %w", err)
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			return nil, err
+		}
+		return &closingExporter{SpanExporter: exporter, closer: file}, nil
+	case "none":
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unknown trace exporter %q", cfg.Exporter)
+}
+
+func newResource(cfg config.TracingConfig) *resource.Resource {
+	version := cfg.ServiceVersion
+	if version == "" {
+		if info, ok := debug.ReadBuildInfo(); ok {
+			version = info.Main.Version
+		}
+	}
+
+	return resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceNameKey.String(cfg.ServiceName),
+		semconv.ServiceVersionKey.String(version),
+		semconv.DeploymentEnvironmentKey.String(cfg.Environment),
+	)
+}
+
+// closingExporter closes the file spans are written to once they are flushed
+type closingExporter struct {
+	trace.SpanExporter
+	closer io.Closer
+}
+
+func (e *closingExporter) Shutdown(ctx context.Context) error {
+	if err := e.SpanExporter.Shutdown(ctx); err != nil {
+		return err
+	}
+	return e.closer.Close()
 }